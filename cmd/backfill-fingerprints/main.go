@@ -0,0 +1,45 @@
+// backfill-fingerprints 为卡片补算重复检测用的内容哈希和 MinHash 签名，并重建 LSH 分段哈希。
+// 升级后首次部署时运行一次，之后创建和修改卡片时会随之更新；
+// 此前创建的卡片在回填之前不会出现在创建卡片时的近似重复提示中。
+//
+//	go run cmd/backfill-fingerprints/main.go
+package main
+
+import (
+	"flag"
+	"log"
+
+	"ReMindful/internal/config"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/database"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	batchSize := flag.Int("batch", 500, "每批处理的卡片数")
+	flag.Parse()
+
+	// 加载配置文件
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化MySQL连接
+	db, err := database.InitMySQL(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MySQL: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	repo := repository.NewLearningCardsRepository(db)
+	err = repo.BackfillFingerprints(*batchSize, func(done int) {
+		log.Printf("Backfilled fingerprints for %d cards", done)
+	})
+	if err != nil {
+		log.Fatalf("Failed to backfill fingerprints: %v", err)
+	}
+	log.Println("Done")
+}
@@ -0,0 +1,54 @@
+// rebuild-stats 根据复习日志重新生成每日统计。
+// 升级后首次部署时运行一次以回填历史数据，之后统计随每次复习增量更新；
+// 每日统计按用户时区划分日期，从按服务器时区划分的旧版本升级时也需要运行一次；
+// 统计与复习日志不一致时（例如直接修改过数据库）也可以重新运行。
+//
+//	go run cmd/rebuild-stats/main.go            # 重建所有用户
+//	go run cmd/rebuild-stats/main.go -user 42   # 只重建指定用户
+package main
+
+import (
+	"flag"
+	"log"
+
+	"ReMindful/internal/config"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/database"
+)
+
+func main() {
+	configPath := flag.String("config", "config.yaml", "配置文件路径")
+	userID := flag.Uint("user", 0, "只重建该用户的统计，为 0 时重建所有用户")
+	flag.Parse()
+
+	// 加载配置文件
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 初始化MySQL连接
+	db, err := database.InitMySQL(&cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize MySQL: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	repo := repository.NewDailyStatsRepository(db)
+	userIDs := []uint{uint(*userID)}
+	if *userID == 0 {
+		if userIDs, err = repo.UserIDs(); err != nil {
+			log.Fatalf("Failed to list users: %v", err)
+		}
+	}
+
+	for i, id := range userIDs {
+		if err := repo.Rebuild(id); err != nil {
+			log.Fatalf("Failed to rebuild daily stats for user %d: %v", id, err)
+		}
+		log.Printf("Rebuilt daily stats for user %d (%d/%d)", id, i+1, len(userIDs))
+	}
+	log.Printf("Done, %d users rebuilt", len(userIDs))
+}
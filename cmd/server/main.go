@@ -74,7 +74,7 @@ func main() {
 	r := gin.New()
 
 	// 初始化路由
-	router.InitRouter(r, db, redis, emailSender)
+	router.InitRouter(r, db, redis, emailSender, cfg)
 
 	// 启动服务器
 	addr := fmt.Sprintf(":%d", cfg.Server.Port)
@@ -15,6 +15,8 @@ type Config struct {
 	Redis    RedisConfig    `mapstructure:"redis"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Email    EmailConfig    `mapstructure:"email"`
+	Revision RevisionConfig `mapstructure:"revision"`
+	Trash    TrashConfig    `mapstructure:"trash"`
 }
 
 // 服务器配置
@@ -43,8 +45,9 @@ type RedisConfig struct {
 
 // jwt配置
 type JWTConfig struct {
-	Secret     string        `mapstructure:"secret"`
-	Expiration time.Duration `mapstructure:"expiration"`
+	Secret            string        `mapstructure:"secret"`
+	Expiration        time.Duration `mapstructure:"expiration"`         // 访问令牌的有效期
+	RefreshExpiration time.Duration `mapstructure:"refresh_expiration"` // 刷新令牌的有效期，期间未刷新则需要重新登录
 }
 
 // email配置
@@ -58,11 +61,43 @@ type EmailConfig struct {
 	TLS      bool   `mapstructure:"tls"`
 }
 
+// 卡片修订历史保留配置，按用户等级区分
+type RevisionConfig struct {
+	Free    RetentionPolicy `mapstructure:"free"`
+	Premium RetentionPolicy `mapstructure:"premium"`
+}
+
+// 修订保留策略，0 表示不限制
+type RetentionPolicy struct {
+	MaxCount int `mapstructure:"max_count"` // 每张卡片最多保留的修订数
+	MaxDays  int `mapstructure:"max_days"`  // 修订最多保留的天数
+}
+
+// 回收站配置
+type TrashConfig struct {
+	RetentionDays int           `mapstructure:"retention_days"` // 回收站中的条目保留天数，超过后彻底删除，0 表示不自动清理
+	PurgeInterval time.Duration `mapstructure:"purge_interval"` // 清理任务的执行间隔
+}
+
 // 加载配置
 func LoadConfig(path string) (*Config, error) {
 	viper.SetConfigFile(path) //设置配置文件路径
 	viper.AutomaticEnv()      //自动读取环境变量
 
+	// 修订历史保留的默认值
+	viper.SetDefault("revision.free.max_count", 20)
+	viper.SetDefault("revision.free.max_days", 30)
+	viper.SetDefault("revision.premium.max_count", 200)
+	viper.SetDefault("revision.premium.max_days", 0)
+
+	// 令牌有效期的默认值
+	viper.SetDefault("jwt.expiration", 15*time.Minute)
+	viper.SetDefault("jwt.refresh_expiration", 30*24*time.Hour)
+
+	// 回收站的默认值
+	viper.SetDefault("trash.retention_days", 30)
+	viper.SetDefault("trash.purge_interval", time.Hour)
+
 	if err := viper.ReadInConfig(); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+// 请求头中的 User-Agent 最多保存的长度
+const maxUserAgentLength = 255
+
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+// 从请求中读取客户端信息
+func clientInfo(c *gin.Context, device string) model.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return model.ClientInfo{
+		Device:    device,
+		UserAgent: userAgent,
+		IP:        c.ClientIP(),
+	}
+}
+
+// 当前请求所属的登录会话ID
+func currentSessionID(c *gin.Context) uint {
+	sessionID, _ := c.Get("sessionID")
+	id, _ := sessionID.(uint)
+	return id
+}
+
+// @Summary 刷新令牌
+// @Description 用刷新令牌换取新的访问令牌和刷新令牌。每个刷新令牌只能使用一次，已经用过的刷新令牌再次出现时会注销整个会话
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param request body model.RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} response.Response{data=model.LoginResponse}
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "刷新令牌无效、过期或已被使用"
+// @Router /refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req model.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	resp, err := h.authService.Refresh(req.RefreshToken, clientInfo(c, ""))
+	if err != nil {
+		switch err.Error() {
+		case "无效的刷新令牌", "刷新令牌已过期", "刷新令牌已被使用，会话已注销", "会话已注销":
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "服务器内部错误")
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// @Summary 注销
+// @Description 注销当前登录会话，会话的访问令牌立即失效，刷新令牌不能再使用
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	session, err := h.authService.GetSession(currentSessionID(c))
+	if err != nil || session.UserID != userID.(uint) {
+		response.Error(c, http.StatusNotFound, "登录会话不存在")
+		return
+	}
+
+	if err := h.authService.RevokeSession(session); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已注销"})
+}
+
+// @Summary 注销全部设备
+// @Description 注销当前用户在所有设备上的登录会话，包括当前会话
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=object{message=string,revoked=int}}
+// @Failure 401 {object} response.Response "未授权"
+// @Router /logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	revoked, err := h.authService.LogoutAll(userID.(uint), 0)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已注销全部设备", "revoked": revoked})
+}
+
+// @Summary 获取登录会话
+// @Description 获取当前用户在各设备上有效的登录会话，current 标记发起请求的会话
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.AuthSession}
+// @Failure 401 {object} response.Response "未授权"
+// @Router /user/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID.(uint), currentSessionID(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// @Summary 注销登录会话
+// @Description 注销指定设备上的登录会话，该会话的访问令牌立即失效
+// @Tags 用户
+// @Produce json
+// @Security Bearer
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response "无效的会话ID"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限操作此会话"
+// @Failure 404 {object} response.Response "会话不存在"
+// @Router /user/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的会话ID")
+		return
+	}
+
+	session, err := h.authService.GetSession(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "登录会话不存在")
+		return
+	}
+	if session.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此会话")
+		return
+	}
+
+	if err := h.authService.RevokeSession(session); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "会话已注销"})
+}
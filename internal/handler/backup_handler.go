@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type BackupHandler struct {
+	backupService *service.BackupService
+}
+
+func NewBackupHandler(backupService *service.BackupService) *BackupHandler {
+	return &BackupHandler{backupService: backupService}
+}
+
+// @Summary 导出账户备份
+// @Description 将用户资料、卡片、标签、卡片标签关联和复习日志导出为版本化的zip归档（JSON Lines），可用于数据迁移和备份
+// @Tags 导入导出
+// @Produce application/zip
+// @Security Bearer
+// @Success 200 {file} file "备份归档"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /backup/export [get]
+func (h *BackupHandler) ExportBackup(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	filename := fmt.Sprintf("remindful-backup-%d-%s.zip", userID.(uint), time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// 归档以流的方式写出，写出开始后无法再返回错误响应
+	if err := h.backupService.Export(userID.(uint), c.Writer); err != nil {
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			response.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		log.Printf("Failed to export backup for user %d: %v", userID.(uint), err)
+		c.Abort()
+	}
+}
+
+// @Summary 从备份恢复账户
+// @Description 将导出的备份归档恢复到当前账户（账户中不能已有卡片和标签），所有ID会重新分配
+// @Tags 导入导出
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "备份归档"
+// @Success 200 {object} response.Response{data=model.RestoreResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /backup/restore [post]
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请上传文件")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.backupService.Restore(userID.(uint), file, fileHeader.Size)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type CardRevisionsHandler struct {
+	revisionsService     *service.CardRevisionsService
+	learningCardsService *service.LearningCardsService
+}
+
+func NewCardRevisionsHandler(revisionsService *service.CardRevisionsService, learningCardsService *service.LearningCardsService) *CardRevisionsHandler {
+	return &CardRevisionsHandler{
+		revisionsService:     revisionsService,
+		learningCardsService: learningCardsService,
+	}
+}
+
+// 解析路径中的卡片ID并检查权限，失败时已写入响应
+func (h *CardRevisionsHandler) ownedCard(c *gin.Context, userID uint) (*model.LearningCard, bool) {
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return nil, false
+	}
+
+	card, err := h.learningCardsService.GetLearningCardByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "卡片不存在")
+		return nil, false
+	}
+
+	if card.UserID != userID {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return nil, false
+	}
+	return card, true
+}
+
+// 按ID获取卡片的修订，失败时已写入响应
+func (h *CardRevisionsHandler) revision(c *gin.Context, cardID uint, idStr string) (*model.CardRevision, bool) {
+	idUint, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的修订ID")
+		return nil, false
+	}
+	revision, err := h.revisionsService.GetRevision(cardID, uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	return revision, true
+}
+
+// @Summary 获取卡片修订历史
+// @Description 获取卡片的修订记录，最新的在前，保留数量和天数按用户等级配置
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Success 200 {object} response.Response{data=[]model.CardRevision}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡片不存在"
+// @Router /learning-cards/{id}/revisions [get]
+func (h *CardRevisionsHandler) GetRevisions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	card, ok := h.ownedCard(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	revisions, err := h.revisionsService.GetRevisions(card.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, revisions)
+}
+
+// @Summary 比较两个修订
+// @Description 比较卡片的两个修订：标题按字比较，内容按行比较，并列出卡片类型和标签的变化。to 为空时与最新修订比较
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Param from query int true "旧修订ID"
+// @Param to query int false "新修订ID"
+// @Success 200 {object} response.Response{data=model.RevisionDiff}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "修订不存在"
+// @Router /learning-cards/{id}/revisions/diff [get]
+func (h *CardRevisionsHandler) DiffRevisions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	card, ok := h.ownedCard(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	from, ok := h.revision(c, card.ID, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	var to *model.CardRevision
+	if toStr := c.Query("to"); toStr != "" {
+		if to, ok = h.revision(c, card.ID, toStr); !ok {
+			return
+		}
+	} else {
+		latest, err := h.revisionsService.GetLatestRevision(card.ID)
+		if err != nil || latest == nil {
+			response.Error(c, http.StatusNotFound, "修订不存在")
+			return
+		}
+		to = latest
+	}
+
+	response.Success(c, h.revisionsService.Diff(from, to))
+}
+
+// @Summary 恢复修订
+// @Description 将卡片的标题、内容、类型和标签恢复到指定修订，已删除的标签会被跳过，恢复操作本身也会记录为新的修订
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Param revision_id path int true "修订ID"
+// @Success 200 {object} response.Response{data=model.LearningCard}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "修订不存在"
+// @Router /learning-cards/{id}/revisions/{revision_id}/restore [post]
+func (h *CardRevisionsHandler) RestoreRevision(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	card, ok := h.ownedCard(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	revision, ok := h.revision(c, card.ID, c.Param("revision_id"))
+	if !ok {
+		return
+	}
+
+	restored, err := h.revisionsService.Restore(card, revision, userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, restored)
+}
@@ -0,0 +1,628 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type DecksHandler struct {
+	decksService *service.DecksService
+}
+
+func NewDecksHandler(decksService *service.DecksService) *DecksHandler {
+	return &DecksHandler{decksService: decksService}
+}
+
+// 解析路径中的卡组ID并检查权限，失败时已写入响应
+func (h *DecksHandler) ownedDeck(c *gin.Context, userID uint) (*model.Deck, bool) {
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡组ID")
+		return nil, false
+	}
+
+	deck, err := h.decksService.GetDeckByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "卡组不存在")
+		return nil, false
+	}
+
+	if deck.UserID != userID {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡组")
+		return nil, false
+	}
+	return deck, true
+}
+
+// @Summary 创建卡组
+// @Description 创建新的卡组，可指定父卡组和调度选项预设
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.CreateDeckRequest true "卡组信息"
+// @Success 200 {object} response.Response{data=model.Deck}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /decks [post]
+func (h *DecksHandler) CreateDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.CreateDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	deck := &model.Deck{
+		UserID:   userID.(uint),
+		Name:     req.Name,
+		ParentID: req.ParentID,
+		PresetID: req.PresetID,
+	}
+
+	if err := h.decksService.CreateDeck(deck); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, deck)
+}
+
+// @Summary 获取卡组树
+// @Description 获取当前用户的卡组树，每个卡组的卡片总数、新卡片数和到期数包含其全部子卡组
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.Deck}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /decks [get]
+func (h *DecksHandler) GetDecks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	decks, err := h.decksService.GetDeckTree(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, decks)
+}
+
+// @Summary 根据ID获取卡组
+// @Description 获取卡组详情及其调度选项
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Success 200 {object} response.Response{data=object{deck=model.Deck,preset=model.DeckPreset}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id} [get]
+func (h *DecksHandler) GetDeckByID(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	preset, err := h.decksService.GetDeckPreset(deck.ID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"deck":   deck,
+		"preset": preset,
+	})
+}
+
+// @Summary 更新卡组
+// @Description 修改卡组名称或调度选项预设（preset_id 为0表示使用默认预设）
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Param request body model.UpdateDeckRequest true "更新的卡组信息"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id} [put]
+func (h *DecksHandler) UpdateDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.UpdateDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	if req.Name != "" {
+		deck.Name = req.Name
+	}
+	if req.PresetID != nil {
+		if *req.PresetID == 0 {
+			deck.PresetID = nil
+		} else {
+			deck.PresetID = req.PresetID
+		}
+	}
+
+	if err := h.decksService.UpdateDeck(deck); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "卡组更新成功"})
+}
+
+// @Summary 移动卡组
+// @Description 将卡组连同其子卡组移动到新的父卡组下，parent_id 为空表示移动到顶级
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Param request body model.MoveDeckRequest true "目标父卡组"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id}/move [post]
+func (h *DecksHandler) MoveDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.MoveDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	if err := h.decksService.MoveDeck(deck, req.ParentID); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "卡组移动成功"})
+}
+
+// @Summary 删除卡组
+// @Description 删除卡组，其中的卡片移入父卡组（顶级卡组则移入默认卡组），子卡组上移一级
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id} [delete]
+func (h *DecksHandler) DeleteDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	if err := h.decksService.DeleteDeck(deck); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "卡组删除成功"})
+}
+
+// @Summary 获取卡组学习队列
+// @Description 获取卡组（含子卡组）今天要学习的卡片，按调度选项的每日新卡片上限和复习上限截取
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Success 200 {object} response.Response{data=[]model.LearningCard}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id}/study [get]
+func (h *DecksHandler) GetStudyQueue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	cards, err := h.decksService.GetStudyQueue(deck)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, cards)
+}
+
+// @Summary 创建筛选卡组
+// @Description 按筛选条件从各卡组中临时抽取卡片组成筛选卡组，reschedule 为 false 时为冲刺模式，复习不影响长期间隔
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.FilteredDeckRequest true "筛选卡组信息"
+// @Success 200 {object} response.Response{data=object{deck=model.Deck,pulled=int}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /decks/filtered [post]
+func (h *DecksHandler) CreateFilteredDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.FilteredDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	deck := &model.Deck{
+		UserID:      userID.(uint),
+		Name:        req.Name,
+		FilterQuery: &req.Filter,
+		FilterLimit: req.Limit,
+		Reschedule:  req.Reschedule,
+	}
+
+	pulled, err := h.decksService.CreateFilteredDeck(deck)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"deck": deck, "pulled": pulled})
+}
+
+// @Summary 更新筛选卡组
+// @Description 更新筛选卡组的名称和筛选条件，已抽取的卡片先送回原卡组再按新条件重新抽取
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Param request body model.FilteredDeckRequest true "筛选卡组信息"
+// @Success 200 {object} response.Response{data=object{deck=model.Deck,pulled=int}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id}/filter [put]
+func (h *DecksHandler) UpdateFilteredDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.FilteredDeckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+	if !deck.IsFiltered {
+		response.Error(c, http.StatusBadRequest, "该卡组不是筛选卡组")
+		return
+	}
+
+	deck.Name = req.Name
+	deck.FilterQuery = &req.Filter
+	deck.FilterLimit = req.Limit
+	deck.Reschedule = req.Reschedule
+
+	pulled, err := h.decksService.UpdateFilteredDeck(deck)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"deck": deck, "pulled": pulled})
+}
+
+// @Summary 重建筛选卡组
+// @Description 将筛选卡组中的卡片送回原卡组，再按筛选条件重新抽取
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Success 200 {object} response.Response{data=object{pulled=int}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id}/rebuild [post]
+func (h *DecksHandler) RebuildFilteredDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+	if !deck.IsFiltered {
+		response.Error(c, http.StatusBadRequest, "该卡组不是筛选卡组")
+		return
+	}
+
+	pulled, err := h.decksService.RebuildFilteredDeck(deck)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"pulled": pulled})
+}
+
+// @Summary 清空筛选卡组
+// @Description 将筛选卡组中的全部卡片送回原卡组，恢复其原有排程，卡组本身保留
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡组ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡组不存在"
+// @Router /decks/{id}/empty [post]
+func (h *DecksHandler) EmptyFilteredDeck(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	deck, ok := h.ownedDeck(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	if err := h.decksService.EmptyFilteredDeck(deck); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "筛选卡组已清空"})
+}
+
+// @Summary 创建调度选项预设
+// @Description 创建可被多个卡组共用的调度选项预设
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.DeckPresetRequest true "预设信息"
+// @Success 200 {object} response.Response{data=model.DeckPreset}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /decks/presets [post]
+func (h *DecksHandler) CreatePreset(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.DeckPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	preset := &model.DeckPreset{
+		UserID:           userID.(uint),
+		Name:             req.Name,
+		NewCardsPerDay:   req.NewCardsPerDay,
+		MaxReviewsPerDay: req.MaxReviewsPerDay,
+		LearningSteps:    req.LearningSteps,
+		MaxInterval:      req.MaxInterval,
+	}
+
+	if err := h.decksService.CreatePreset(preset); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, preset)
+}
+
+// @Summary 获取调度选项预设列表
+// @Description 获取当前用户的调度选项预设，未设置预设的卡组使用默认预设
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=object{presets=[]model.DeckPreset,default=model.DeckPreset}}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /decks/presets [get]
+func (h *DecksHandler) GetPresets(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	presets, err := h.decksService.GetPresetsByUserID(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"presets": presets,
+		"default": model.DefaultDeckPreset,
+	})
+}
+
+// @Summary 更新调度选项预设
+// @Description 更新调度选项预设，使用该预设的卡组立即生效
+// @Tags 卡组
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预设ID"
+// @Param request body model.DeckPresetRequest true "预设信息"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "预设不存在"
+// @Router /decks/presets/{id} [put]
+func (h *DecksHandler) UpdatePreset(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的预设ID")
+		return
+	}
+
+	var req model.DeckPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	preset, err := h.decksService.GetPresetByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "预设不存在")
+		return
+	}
+
+	if preset.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此预设")
+		return
+	}
+
+	preset.Name = req.Name
+	preset.NewCardsPerDay = req.NewCardsPerDay
+	preset.MaxReviewsPerDay = req.MaxReviewsPerDay
+	preset.LearningSteps = req.LearningSteps
+	preset.MaxInterval = req.MaxInterval
+
+	if err := h.decksService.UpdatePreset(preset); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "预设更新成功"})
+}
+
+// @Summary 删除调度选项预设
+// @Description 删除调度选项预设，使用该预设的卡组改用默认预设
+// @Tags 卡组
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预设ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "预设不存在"
+// @Router /decks/presets/{id} [delete]
+func (h *DecksHandler) DeletePreset(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的预设ID")
+		return
+	}
+
+	preset, err := h.decksService.GetPresetByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "预设不存在")
+		return
+	}
+
+	if preset.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此预设")
+		return
+	}
+
+	if err := h.decksService.DeletePreset(preset.ID); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "预设删除成功"})
+}
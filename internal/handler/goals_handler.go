@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type GoalsHandler struct {
+	goalsService *service.GoalsService
+}
+
+func NewGoalsHandler(goalsService *service.GoalsService) *GoalsHandler {
+	return &GoalsHandler{goalsService: goalsService}
+}
+
+// @Summary 获取每日目标
+// @Description 获取每日目标、今天的完成进度（按用户时区划分日期）以及连续完成目标的天数和可用冻结天数。连续完成目标每满7天获得一天冻结（最多2天），漏掉的天数不超过冻结天数时连续不中断
+// @Tags 每日目标
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=model.GoalStatus}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /goals [get]
+func (h *GoalsHandler) GetGoal(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	status, err := h.goalsService.GetGoalStatus(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// @Summary 设置每日目标
+// @Description 设置每天需要复习的卡片数或学习的分钟数，已有的连续天数不受影响
+// @Tags 每日目标
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.UpdateGoalRequest true "目标设置"
+// @Success 200 {object} response.Response{data=model.GoalStatus}
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /goals [put]
+func (h *GoalsHandler) UpdateGoal(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	status, err := h.goalsService.UpdateGoal(userID.(uint), &req)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// @Summary 获取成就列表
+// @Description 获取所有成就及当前进度，已解锁的成就包含解锁时间。每次复习后自动检查并解锁成就
+// @Tags 每日目标
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.AchievementStatus}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /achievements [get]
+func (h *GoalsHandler) GetAchievements(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	achievements, err := h.goalsService.GetAchievements(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, achievements)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type ImportHandler struct {
+	importService *service.ImportService
+}
+
+func NewImportHandler(importService *service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// @Summary 从CSV/TSV批量导入卡片
+// @Description 流式导入CSV/TSV文件，支持自定义分隔符、表头检测、列映射、试运行和重复处理策略
+// @Tags 导入导出
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "CSV/TSV文件"
+// @Param delimiter formData string false "分隔符，支持 , ; | tab，默认根据扩展名判断"
+// @Param has_header formData string false "是否包含表头" Enums(auto,true,false) default(auto)
+// @Param mapping formData string false "字段映射JSON，如 {\"title\":\"Front\",\"content\":\"Back\",\"tags\":\"3\"}"
+// @Param tag_separator formData string false "标签分隔符" default(;)
+// @Param card_type formData string false "默认卡片类型" Enums(basic,cloze,question) default(basic)
+// @Param duplicate formData string false "重复处理策略" Enums(skip,update,create) default(skip)
+// @Param dry_run formData bool false "仅校验不写入" default(false)
+// @Success 200 {object} response.Response{data=model.ImportResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /learning-cards/import/csv [post]
+func (h *ImportHandler) ImportCSV(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请上传文件")
+		return
+	}
+
+	opts := &model.CSVImportOptions{
+		TagSeparator:    c.PostForm("tag_separator"),
+		DefaultCardType: model.CardType(c.PostForm("card_type")),
+		DuplicatePolicy: model.DuplicatePolicy(c.PostForm("duplicate")),
+	}
+
+	// 解析分隔符，未指定时 .tsv 文件默认使用制表符
+	switch delimiter := c.PostForm("delimiter"); strings.ToLower(delimiter) {
+	case "":
+		if strings.EqualFold(filepath.Ext(fileHeader.Filename), ".tsv") {
+			opts.Delimiter = '\t'
+		}
+	case "tab", "\\t", "\t":
+		opts.Delimiter = '\t'
+	default:
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) {
+			response.Error(c, http.StatusBadRequest, "分隔符必须是单个字符")
+			return
+		}
+		opts.Delimiter = r
+	}
+
+	switch c.DefaultPostForm("has_header", "auto") {
+	case "auto":
+	case "true":
+		hasHeader := true
+		opts.HasHeader = &hasHeader
+	case "false":
+		hasHeader := false
+		opts.HasHeader = &hasHeader
+	default:
+		response.Error(c, http.StatusBadRequest, "无效的has_header参数")
+		return
+	}
+
+	if mapping := c.PostForm("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &opts.Mapping); err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的字段映射")
+			return
+		}
+	}
+
+	if dryRun := c.PostForm("dry_run"); dryRun != "" {
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的dry_run参数")
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportCSV(userID.(uint), file, opts)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// @Summary 导入Markdown笔记库
+// @Description 上传zip打包的Markdown/Obsidian笔记库，从 Question :: Answer（两侧有空格）、? 分隔的问答块和 ==高亮== 中提取卡片；文件夹路径和 #标签 转为标签（a/b 形式的路径转为 a::b 层级标签）；重复导入时按来源锚点更新已有卡片，问题修改过的卡片按内容相似度匹配，回收站中的卡片跳过
+// @Tags 导入导出
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "zip格式的笔记库"
+// @Param folder_tags formData bool false "是否将文件夹路径作为标签" default(true)
+// @Param dry_run formData bool false "仅解析不写入" default(false)
+// @Success 200 {object} response.Response{data=model.ImportResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /learning-cards/import/markdown [post]
+func (h *ImportHandler) ImportMarkdown(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请上传文件")
+		return
+	}
+
+	opts := &model.MarkdownImportOptions{}
+	opts.FolderTags, err = strconv.ParseBool(c.DefaultPostForm("folder_tags", "true"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的folder_tags参数")
+		return
+	}
+	opts.DryRun, err = strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的dry_run参数")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	defer file.Close()
+
+	result, err := h.importService.ImportMarkdown(userID.(uint), file, fileHeader.Size, opts)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
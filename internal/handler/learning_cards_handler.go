@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -9,9 +11,13 @@ import (
 
 	"ReMindful/internal/model"
 	"ReMindful/internal/service"
+	"ReMindful/pkg/cardquery"
 	"ReMindful/pkg/utils/response"
 )
 
+// 卡片列表每页最多条数
+const maxPageSize = 100
+
 type LearningCardsHandler struct {
 	learningCardsService *service.LearningCardsService
 }
@@ -21,7 +27,7 @@ func NewLearningCardsHandler(learningCardsService *service.LearningCardsService)
 }
 
 // @Summary 创建学习卡片
-// @Description 创建新的学习卡片
+// @Description 创建新的学习卡片，标签通过 tag_ids（已有标签）或 tag_names（不存在时自动创建）指定，已存在内容相同的卡片时返回409（可用 allow_duplicate 跳过），近似重复的卡片在 similar_cards 中返回
 // @Tags 学习卡片
 // @Accept json
 // @Produce json
@@ -29,6 +35,7 @@ func NewLearningCardsHandler(learningCardsService *service.LearningCardsService)
 // @Param request body model.CreateCardRequest true "学习卡片信息"
 // @Success 200 {object} model.LearningCard
 // @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response{data=object{duplicates=[]model.DuplicateCandidate}} "已存在内容相同的卡片"
 // @Failure 401 {object} response.Response "未授权"
 // @Failure 500 {object} response.Response
 // @Router /learning-cards [post]
@@ -45,12 +52,37 @@ func (h *LearningCardsHandler) CreateLearningCard(c *gin.Context) {
 		return
 	}
 
+	tags, err := h.learningCardsService.ResolveTags(userID.(uint), req.TagIDs, req.TagNames)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	card := &model.LearningCard{
 		UserID:   userID.(uint),
 		Title:    req.Title,
 		Content:  req.Content,
 		CardType: req.CardType,
-		Tags:     req.Tags,
+		DeckID:   req.DeckID,
+		Tags:     tags,
+	}
+
+	// 检查重复：完全重复时拒绝创建，近似重复随卡片一起返回
+	if !req.AllowDuplicate {
+		exact, similar, err := h.learningCardsService.CheckDuplicates(card)
+		if err != nil {
+			response.Error(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if len(exact) > 0 {
+			c.JSON(http.StatusConflict, response.Response{
+				Code:    http.StatusConflict,
+				Message: "已存在内容相同的卡片",
+				Data:    gin.H{"duplicates": exact},
+			})
+			return
+		}
+		card.SimilarCards = similar
 	}
 
 	if err := h.learningCardsService.CreateLearningCard(card); err != nil {
@@ -61,6 +93,110 @@ func (h *LearningCardsHandler) CreateLearningCard(c *gin.Context) {
 	response.Success(c, card)
 }
 
+// @Summary 查找重复卡片
+// @Description 检查当前用户的全部卡片，返回完全重复（规范化后内容相同）和近似重复（MinHash 估算相似度不低于阈值）的卡片聚类
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param threshold query number false "近似重复的相似度阈值（0.5-1）" default(0.8)
+// @Success 200 {object} response.Response{data=[]model.DuplicateCluster}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /learning-cards/duplicates [get]
+func (h *LearningCardsHandler) GetDuplicates(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	threshold := service.DefaultDuplicateThreshold
+	if thresholdStr := c.Query("threshold"); thresholdStr != "" {
+		value, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil || value < 0.5 || value > 1 {
+			response.Error(c, http.StatusBadRequest, "相似度阈值必须在0.5到1之间")
+			return
+		}
+		threshold = value
+	}
+
+	clusters, err := h.learningCardsService.GetDuplicateClusters(userID.(uint), threshold)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, clusters)
+}
+
+// @Summary 合并重复卡片
+// @Description 合并多张卡片：保留复习记录最丰富的卡片（或指定的卡片），合并全部标签，其余卡片的复习日志转移到保留的卡片后删除，保留卡片的复习次数、上次复习时间和下次复习时间按合并后的日志重新计算
+// @Tags 学习卡片
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.MergeCardsRequest true "要合并的卡片"
+// @Success 200 {object} response.Response{data=model.LearningCard}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /learning-cards/merge [post]
+func (h *LearningCardsHandler) MergeCards(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.MergeCardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	card, err := h.learningCardsService.MergeCards(userID.(uint), req.CardIDs, req.KeepID)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, card)
+}
+
+// @Summary 批量操作卡片
+// @Description 对一批卡片执行同一操作：添加/移除标签、修改类型、移动卡组、重置为新卡片、在日期范围内随机安排复习时间、暂停/取消暂停、删除。
+// @Description 卡片由 card_ids 或搜索语句 query 指定，一次最多5000张，每200张在一个事务中执行，返回每张卡片的结果
+// @Tags 学习卡片
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.BulkCardsRequest true "批量操作"
+// @Success 200 {object} response.Response{data=model.BulkCardsResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /learning-cards/bulk [post]
+func (h *LearningCardsHandler) BulkCards(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.BulkCardsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	result, err := h.learningCardsService.BulkUpdate(userID.(uint), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // @Summary 根据ID获取学习卡片
 // @Description 根据ID获取学习卡片
 // @Tags 学习卡片
@@ -90,15 +226,19 @@ func (h *LearningCardsHandler) GetLearningCardByID(c *gin.Context) {
 }
 
 // @Summary 获取用户的学习卡片列表
-// @Description 获取当前用户的所有学习卡片，支持分页
+// @Description 获取当前用户的学习卡片，支持搜索语句、过滤条件组合、排序和分页
 // @Tags 学习卡片
 // @Produce json
 // @Security Bearer
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param q query string false "搜索语句，如 tag:git -tag:done type:cloze due<3d ease<2.0 lapses>3 \"exact phrase\" created:7d"
+// @Param sort query string false "排序字段，- 前缀表示降序" Enums(due,-due,created,-created,updated,-updated,ease,-ease,difficulty,-difficulty,reviews,-reviews,title,-title)
 // @Param tag_id query int false "标签ID过滤"
+// @Param deck_id query int false "卡组ID过滤（包含子卡组）"
 // @Param card_type query string false "卡片类型过滤" Enums(basic,cloze,question)
 // @Success 200 {object} response.Response{data=object{cards=[]model.LearningCard,total=int64,page=int,page_size=int}}
+// @Failure 400 {object} response.Response "搜索语法错误"
 // @Failure 401 {object} response.Response "未授权"
 // @Failure 500 {object} response.Response
 // @Router /learning-cards [get]
@@ -112,27 +252,42 @@ func (h *LearningCardsHandler) GetLearningCards(c *gin.Context) {
 	// 解析查询参数
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	tagIDStr := c.Query("tag_id")
-	cardTypeStr := c.Query("card_type")
-
-	var cards []*model.LearningCard
-	var total int64
-	var err error
-
-	// 根据过滤条件查询
-	if tagIDStr != "" {
-		tagID, _ := strconv.ParseUint(tagIDStr, 10, 64)
-		cards, err = h.learningCardsService.GetLearningCardsByTag(userID.(uint), uint(tagID))
-		total = int64(len(cards))
-	} else if cardTypeStr != "" {
-		cardType := model.CardType(cardTypeStr)
-		cards, err = h.learningCardsService.GetLearningCardsByCardType(userID.(uint), cardType)
-		total = int64(len(cards))
-	} else {
-		cards, total, err = h.learningCardsService.GetLearningCardsByUserIDWithPagination(userID.(uint), page, pageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		pageSize = 10
+	}
+
+	// 各过滤条件可以同时使用，之间为"且"的关系
+	filter := &model.CardFilter{Query: c.Query("q")}
+	if tagIDStr := c.Query("tag_id"); tagIDStr != "" {
+		tagID, err := strconv.ParseUint(tagIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的标签ID")
+			return
+		}
+		filter.TagIDs = []uint{uint(tagID)}
+	}
+	if deckIDStr := c.Query("deck_id"); deckIDStr != "" {
+		deckID, err := strconv.ParseUint(deckIDStr, 10, 64)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "无效的卡组ID")
+			return
+		}
+		filter.DeckIDs = []uint{uint(deckID)}
+	}
+	if cardTypeStr := c.Query("card_type"); cardTypeStr != "" {
+		filter.CardTypes = []model.CardType{model.CardType(cardTypeStr)}
 	}
 
+	cards, total, err := h.learningCardsService.SearchLearningCards(userID.(uint), filter, c.Query("sort"), page, pageSize)
 	if err != nil {
+		var syntaxErr *cardquery.Error
+		if errors.As(err, &syntaxErr) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -146,7 +301,7 @@ func (h *LearningCardsHandler) GetLearningCards(c *gin.Context) {
 }
 
 // @Summary 更新学习卡片
-// @Description 更新学习卡片信息
+// @Description 更新学习卡片信息，提供 tag_ids 或 tag_names 时替换卡片的标签，都未提供时保留原有标签
 // @Tags 学习卡片
 // @Accept json
 // @Produce json
@@ -191,11 +346,26 @@ func (h *LearningCardsHandler) UpdateLearningCard(c *gin.Context) {
 		return
 	}
 
+	// 未提供标签时保留原有标签
+	tags, err := h.learningCardsService.ResolveTags(card.UserID, req.TagIDs, req.TagNames)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// 更新卡片信息
 	card.Title = req.Title
 	card.Content = req.Content
 	card.CardType = req.CardType
-	card.Tags = req.Tags
+	card.Tags = tags
+	if req.DeckID != 0 && req.DeckID != card.DeckID {
+		if card.OriginalDeckID != 0 {
+			// 位于筛选卡组中的卡片只修改其原卡组
+			card.OriginalDeckID = req.DeckID
+		} else {
+			card.DeckID = req.DeckID
+		}
+	}
 
 	if err := h.learningCardsService.UpdateLearningCard(card); err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
@@ -206,7 +376,7 @@ func (h *LearningCardsHandler) UpdateLearningCard(c *gin.Context) {
 }
 
 // @Summary 删除学习卡片
-// @Description 软删除学习卡片
+// @Description 删除学习卡片，卡片移入回收站，可在保留期内恢复
 // @Tags 学习卡片
 // @Produce json
 // @Security Bearer
@@ -248,18 +418,19 @@ func (h *LearningCardsHandler) DeleteLearningCard(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, gin.H{"message": "卡片删除成功"})
+	response.Success(c, gin.H{"message": "卡片已移至回收站"})
 }
 
 // ReviewCardRequest 复习卡片请求
 type ReviewCardRequest struct {
-	Quality  int  `json:"quality" binding:"required,min=0,max=5"` // 复习质量评分 0-5
-	Duration int  `json:"duration" binding:"required,min=1"`      // 复习耗时（秒）
-	IsHard   bool `json:"is_hard"`                                // 是否觉得困难
+	Quality   int  `json:"quality" binding:"required,min=0,max=5"` // 复习质量评分 0-5
+	Duration  int  `json:"duration" binding:"required,min=1"`      // 复习耗时（秒）
+	IsHard    bool `json:"is_hard"`                                // 是否觉得困难
+	SessionID uint `json:"session_id"`                             // 所属的学习会话，为空时不记入会话
 }
 
 // @Summary 复习学习卡片
-// @Description 提交卡片复习结果，更新复习参数
+// @Description 提交卡片复习结果，更新复习参数；指定 session_id 时复习记入该学习会话
 // @Tags 学习卡片
 // @Accept json
 // @Produce json
@@ -305,9 +476,17 @@ func (h *LearningCardsHandler) ReviewCard(c *gin.Context) {
 		return
 	}
 
+	// 检查学习会话
+	if req.SessionID != 0 {
+		if err := h.learningCardsService.CheckStudySession(userID.(uint), req.SessionID); err != nil {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	// 更新复习状态
 	duration := time.Duration(req.Duration) * time.Second
-	if err := h.learningCardsService.UpdateCardReviewStatus(card, req.Quality, duration, req.IsHard); err != nil {
+	if err := h.learningCardsService.UpdateCardReviewStatus(card, req.Quality, duration, req.IsHard, req.SessionID); err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -315,6 +494,144 @@ func (h *LearningCardsHandler) ReviewCard(c *gin.Context) {
 	response.Success(c, card)
 }
 
+// @Summary 重置卡片为新卡片
+// @Description 清空复习次数、间隔、简易因子和难度系数，卡片重新作为新卡片学习；archive_logs 为 true 时归档复习日志（保留但不再计入统计）
+// @Tags 学习卡片
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Param request body model.ForgetCardRequest false "重置选项"
+// @Success 200 {object} response.Response{data=model.LearningCard}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡片不存在"
+// @Router /learning-cards/{id}/forget [post]
+func (h *LearningCardsHandler) ForgetCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return
+	}
+
+	// 请求体可以为空
+	var req model.ForgetCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	card, err := h.learningCardsService.GetLearningCardByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "卡片不存在")
+		return
+	}
+	if card.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return
+	}
+
+	updated, err := h.learningCardsService.ForgetCard(card, req.ArchiveLogs)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, updated)
+}
+
+// @Summary 安排卡片复习时间
+// @Description 设置卡片的下次复习时间：只指定 due_from 时设为该时间，同时指定 due_to 时在范围内随机选择；新卡片不能安排
+// @Tags 学习卡片
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Param request body model.RescheduleCardRequest true "复习时间"
+// @Success 200 {object} response.Response{data=model.LearningCard}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡片不存在"
+// @Router /learning-cards/{id}/reschedule [post]
+func (h *LearningCardsHandler) RescheduleCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return
+	}
+
+	var req model.RescheduleCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	card, err := h.learningCardsService.GetLearningCardByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "卡片不存在")
+		return
+	}
+	if card.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return
+	}
+
+	updated, err := h.learningCardsService.RescheduleCard(card, req.DueFrom, req.DueTo)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, updated)
+}
+
+// @Summary 分散积压的到期卡片
+// @Description 将已到期的卡片分散到接下来的若干天（含今天）：按预计记忆保持率从低到高排序，最容易遗忘的留在今天，其余均匀推迟
+// @Tags 学习卡片
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.PostponeBacklogRequest true "分散选项"
+// @Success 200 {object} response.Response{data=model.PostponeBacklogResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Router /learning-cards/postpone-backlog [post]
+func (h *LearningCardsHandler) PostponeBacklog(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.PostponeBacklogRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	result, err := h.learningCardsService.PostponeBacklog(userID.(uint), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // @Summary 获取需要复习的卡片
 // @Description 获取当前用户需要复习的卡片列表
 // @Tags 学习卡片
@@ -339,3 +656,48 @@ func (h *LearningCardsHandler) GetCardsToReview(c *gin.Context) {
 
 	response.Success(c, cards)
 }
+
+// @Summary 获取卡片统计
+// @Description 获取单张卡片的统计：第一次复习时间、复习次数、遗忘次数、平均耗时、当前和上一次的计划间隔、按遗忘曲线估算的当前和到期时的回忆概率，以及按时间顺序的完整复习时间线（含每次复习前后的间隔变化）
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Success 200 {object} response.Response{data=model.CardStats}
+// @Failure 400 {object} response.Response "无效的卡片ID"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "卡片不存在"
+// @Failure 500 {object} response.Response
+// @Router /learning-cards/{id}/stats [get]
+func (h *LearningCardsHandler) GetCardStats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return
+	}
+
+	card, err := h.learningCardsService.GetLearningCardByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "卡片不存在")
+		return
+	}
+	if card.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return
+	}
+
+	stats, err := h.learningCardsService.GetCardStats(card)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, stats)
+}
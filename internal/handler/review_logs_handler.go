@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"ReMindful/internal/model"
 	"ReMindful/internal/service"
 	"ReMindful/pkg/utils/response"
 )
@@ -139,6 +142,137 @@ func (h *ReviewLogsHandler) GetLearningProgress(c *gin.Context) {
 	response.Success(c, progress)
 }
 
+// @Summary 获取记忆保持率分析
+// @Description 根据复习日志计算真实的记忆保持率：按新近/成熟卡片、卡片类型和标签统计通过率（评分不低于3），给出经验遗忘曲线（回忆率与距上次复习天数的关系），以及调度器预测回忆概率与实际结果的校准
+// @Tags 复习日志
+// @Produce json
+// @Security Bearer
+// @Param days query int false "统计窗口的天数（1-3650）" default(90)
+// @Success 200 {object} response.Response{data=model.RetentionStats}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /review-logs/retention [get]
+func (h *ReviewLogsHandler) GetRetentionStats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	days := service.DefaultRetentionDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		value, err := strconv.Atoi(daysStr)
+		if err != nil || value < 1 || value > service.MaxRetentionDays {
+			response.Error(c, http.StatusBadRequest, "统计天数必须在1到3650之间")
+			return
+		}
+		days = value
+	}
+
+	stats, err := h.reviewLogsService.GetRetentionStats(userID.(uint), days)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// @Summary 获取到期预测
+// @Description 预测未来每天到期的卡片数（按用户时区划分日期），包含累计待复习数和根据历史平均复习耗时估算的用时，可按标签或卡组分组
+// @Tags 复习日志
+// @Produce json
+// @Security Bearer
+// @Param days query int false "预测天数（1-365）" default(30)
+// @Param group_by query string false "分组方式" Enums(tag,deck)
+// @Success 200 {object} response.Response{data=model.DueForecast}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /review-logs/forecast [get]
+func (h *ReviewLogsHandler) GetDueForecast(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	days := service.DefaultForecastDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		value, err := strconv.Atoi(daysStr)
+		if err != nil || value < 1 || value > service.MaxForecastDays {
+			response.Error(c, http.StatusBadRequest, "预测天数必须在1到365之间")
+			return
+		}
+		days = value
+	}
+
+	groupBy := c.Query("group_by")
+	switch groupBy {
+	case "", model.ForecastGroupTag, model.ForecastGroupDeck:
+	default:
+		response.Error(c, http.StatusBadRequest, "不支持的分组方式")
+		return
+	}
+
+	forecast, err := h.reviewLogsService.GetDueForecast(userID.(uint), days, groupBy)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, forecast)
+}
+
+// @Summary 获取学习时间分析
+// @Description 根据复习日志分析什么时候学习效果最好：各小时和星期几（按用户时区）的复习量与通过率、各卡片类型每张卡片的平均耗时，以及按复习间隔识别出的学习时段的时长和准确率
+// @Tags 复习日志
+// @Produce json
+// @Security Bearer
+// @Param days query int false "统计窗口的天数（1-365）" default(90)
+// @Param session_gap query int false "相邻复习间隔超过该分钟数时视为新的一次学习（1-240）" default(30)
+// @Success 200 {object} response.Response{data=model.StudyInsights}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /review-logs/insights [get]
+func (h *ReviewLogsHandler) GetStudyInsights(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	days := service.DefaultInsightsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		value, err := strconv.Atoi(daysStr)
+		if err != nil || value < 1 || value > service.MaxInsightsDays {
+			response.Error(c, http.StatusBadRequest, "统计天数必须在1到365之间")
+			return
+		}
+		days = value
+	}
+
+	gap := service.DefaultSessionGapMinutes
+	if gapStr := c.Query("session_gap"); gapStr != "" {
+		value, err := strconv.Atoi(gapStr)
+		if err != nil || value < 1 || value > service.MaxSessionGapMinutes {
+			response.Error(c, http.StatusBadRequest, "学习间隔必须在1到240分钟之间")
+			return
+		}
+		gap = value
+	}
+
+	insights, err := h.reviewLogsService.GetStudyInsights(userID.(uint), days, gap)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, insights)
+}
+
 // @Summary 获取复习热力图数据
 // @Description 获取用户复习活动的热力图数据
 // @Tags 复习日志
@@ -166,3 +300,86 @@ func (h *ReviewLogsHandler) GetReviewHeatmap(c *gin.Context) {
 
 	response.Success(c, heatmap)
 }
+
+// @Summary 获取复习连续天数
+// @Description 获取截至今天（或昨天）连续有复习记录的天数，不考虑每日目标
+// @Tags 复习日志
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=object{streak=int}}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /review-logs/streak [get]
+func (h *ReviewLogsHandler) GetReviewStreak(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	streak, err := h.reviewLogsService.GetReviewStreak(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"streak": streak})
+}
+
+// @Summary 导出学习报告
+// @Description 生成一段日期内的学习报告：汇总、每日明细、各标签的保持率和答错最多的卡片。format 为 json 时返回 JSON，为 csv 时下载 CSV 文件，为 html 时返回包含内联 SVG 图表的独立页面。日期按用户时区划分，默认为截至今天的最近 7 天，最多 366 天
+// @Tags 复习日志
+// @Produce json,text/csv,text/html
+// @Security Bearer
+// @Param start_date query string false "开始日期（YYYY-MM-DD，包含）"
+// @Param end_date query string false "结束日期（YYYY-MM-DD，包含），默认为今天"
+// @Param format query string false "输出格式：json、csv、html" default(json)
+// @Success 200 {object} response.Response{data=model.StudyReport}
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /review-logs/report [get]
+func (h *ReviewLogsHandler) GetStudyReport(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	format := c.DefaultQuery("format", model.ReportFormatJSON)
+	if format != model.ReportFormatJSON && format != model.ReportFormatCSV && format != model.ReportFormatHTML {
+		response.Error(c, http.StatusBadRequest, "不支持的报告格式")
+		return
+	}
+
+	report, err := h.reviewLogsService.GetStudyReport(userID.(uint), c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if format == model.ReportFormatJSON {
+		response.Success(c, report)
+		return
+	}
+
+	// 先渲染到内存，渲染失败时仍可返回错误响应
+	var buf bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	if format == model.ReportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+		err = service.WriteReportCSV(&buf, report)
+	} else {
+		err = service.WriteReportHTML(&buf, report)
+	}
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == model.ReportFormatCSV {
+		filename := fmt.Sprintf("remindful-report-%s-%s.csv", report.StartDate, report.EndDate)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	}
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
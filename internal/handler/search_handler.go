@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// @Summary 全文搜索卡片
+// @Description 在卡片标题和内容中搜索（支持中英文混合），结果按相关度排序，片段中的命中词用 <mark> 包裹
+// @Tags 学习卡片
+// @Produce json
+// @Security Bearer
+// @Param q query string true "搜索内容"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=object{hits=[]model.CardSearchHit,total=int64,page=int,page_size=int}}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /learning-cards/search [get]
+func (h *SearchHandler) SearchCards(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	q := c.Query("q")
+	if q == "" {
+		response.Error(c, http.StatusBadRequest, "搜索内容不能为空")
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		pageSize = 10
+	}
+
+	hits, total, err := h.searchService.SearchCards(userID.(uint), q, page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"hits":      hits,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
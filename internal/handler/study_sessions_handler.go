@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/model"
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type StudySessionsHandler struct {
+	studySessionsService *service.StudySessionsService
+}
+
+func NewStudySessionsHandler(studySessionsService *service.StudySessionsService) *StudySessionsHandler {
+	return &StudySessionsHandler{studySessionsService: studySessionsService}
+}
+
+// 解析路径中的会话ID并校验会话属于当前用户
+func (h *StudySessionsHandler) ownedSession(c *gin.Context, userID uint) (*model.StudySession, bool) {
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的会话ID")
+		return nil, false
+	}
+
+	session, err := h.studySessionsService.GetSession(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "学习会话不存在")
+		return nil, false
+	}
+
+	if session.UserID != userID {
+		response.Error(c, http.StatusForbidden, "无权限操作此学习会话")
+		return nil, false
+	}
+	return session, true
+}
+
+// @Summary 开始学习会话
+// @Description 开始一次学习会话，可指定卡组、搜索语句、设备和计划卡片数；复习卡片时传入返回的会话ID即可关联到该会话。超过30分钟没有活动的会话会自动标记为已放弃
+// @Tags 学习会话
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body model.StartSessionRequest true "会话信息"
+// @Success 200 {object} response.Response{data=model.StudySession}
+// @Failure 400 {object} response.Response "请求参数错误或卡组不可用"
+// @Failure 401 {object} response.Response "未授权"
+// @Router /study-sessions [post]
+func (h *StudySessionsHandler) StartSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.StartSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	session, err := h.studySessionsService.StartSession(userID.(uint), &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// @Summary 获取学习会话列表
+// @Description 分页获取当前用户的学习会话，最近开始的在前
+// @Tags 学习会话
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=object{sessions=[]model.StudySession,total=int64,page=int,page_size=int}}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /study-sessions [get]
+func (h *StudySessionsHandler) GetSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	page, pageSize := trashPage(c)
+	sessions, total, err := h.studySessionsService.GetSessions(userID.(uint), page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"sessions":  sessions,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// @Summary 获取学习会话汇总
+// @Description 获取学习会话及其汇总：剩余卡片数、准确率、平均耗时、时长以及新卡片/学习中/复习的数量
+// @Tags 学习会话
+// @Produce json
+// @Security Bearer
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response{data=model.StudySessionSummary}
+// @Failure 400 {object} response.Response "无效的会话ID"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "学习会话不存在"
+// @Router /study-sessions/{id} [get]
+func (h *StudySessionsHandler) GetSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	session, ok := h.ownedSession(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	response.Success(c, h.studySessionsService.Summarize(session))
+}
+
+// @Summary 学习会话心跳
+// @Description 客户端在学习期间定期调用以保持会话为进行中，复习卡片也会刷新会话的活动时间
+// @Tags 学习会话
+// @Produce json
+// @Security Bearer
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response{data=model.StudySession}
+// @Failure 400 {object} response.Response "学习会话已结束"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "学习会话不存在"
+// @Router /study-sessions/{id}/heartbeat [post]
+func (h *StudySessionsHandler) Heartbeat(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	session, ok := h.ownedSession(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	if err := h.studySessionsService.Heartbeat(session); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// @Summary 结束学习会话
+// @Description 结束学习会话并返回汇总，供客户端展示学习结束页面
+// @Tags 学习会话
+// @Produce json
+// @Security Bearer
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response{data=model.StudySessionSummary}
+// @Failure 400 {object} response.Response "学习会话已结束"
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "学习会话不存在"
+// @Router /study-sessions/{id}/finish [post]
+func (h *StudySessionsHandler) FinishSession(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	session, ok := h.ownedSession(c, userID.(uint))
+	if !ok {
+		return
+	}
+
+	summary, err := h.studySessionsService.FinishSession(session)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, summary)
+}
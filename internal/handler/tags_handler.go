@@ -21,12 +21,12 @@ func NewTagsHandler(tagsService *service.TagsService) *TagsHandler {
 
 // CreateTagRequest 创建标签请求
 type CreateTagRequest struct {
-	Name      string `json:"name" binding:"required,min=1,max=50"`
+	Name      string `json:"name" binding:"required,min=1,max=255"` // 以 :: 分隔的层级路径，如 lang::go::concurrency
 	ColorCode string `json:"color_code" binding:"omitempty,len=7"`
 }
 
 // @Summary 创建标签
-// @Description 创建新的学习标签
+// @Description 创建新的学习标签，名称可以是以 :: 分隔的层级路径，不存在的上级标签会自动创建
 // @Tags 标签
 // @Accept json
 // @Produce json
@@ -137,7 +137,7 @@ func (h *TagsHandler) GetTagByID(c *gin.Context) {
 }
 
 // @Summary 更新标签
-// @Description 更新标签信息
+// @Description 更新标签信息，修改名称时下级标签的路径随之修改
 // @Tags 标签
 // @Accept json
 // @Produce json
@@ -199,7 +199,7 @@ func (h *TagsHandler) UpdateTag(c *gin.Context) {
 }
 
 // @Summary 删除标签
-// @Description 删除标签
+// @Description 删除标签，标签及其下级标签移入回收站，恢复后原有的卡片关联随之恢复
 // @Tags 标签
 // @Produce json
 // @Security Bearer
@@ -237,10 +237,188 @@ func (h *TagsHandler) DeleteTag(c *gin.Context) {
 		return
 	}
 
-	if err := h.tagsService.DeleteTag(uint(idUint)); err != nil {
+	if err := h.tagsService.DeleteTag(tag); err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	response.Success(c, gin.H{"message": "标签删除成功"})
+	response.Success(c, gin.H{"message": "标签已移至回收站"})
+}
+
+// @Summary 获取标签树
+// @Description 按 :: 分隔的层级路径将当前用户的标签组织为树
+// @Tags 标签
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.TagNode}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /tags/tree [get]
+func (h *TagsHandler) GetTagTree(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	tree, err := h.tagsService.GetTagTree(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// @Summary 获取标签统计
+// @Description 统计每个标签（包含下级标签）的卡片数、今日到期数、新卡片/新近/成熟卡片数、平均简易因子和难度，以及统计窗口内的复习次数、记忆保持率和学习时长，用于找出掌握最薄弱的主题
+// @Tags 标签
+// @Produce json
+// @Security Bearer
+// @Param days query int false "复习统计窗口的天数（1-365）" default(30)
+// @Param sort query string false "排序方式" Enums(retention,cards,due,name) default(retention)
+// @Success 200 {object} response.Response{data=model.TagStatsResult}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /tags/stats [get]
+func (h *TagsHandler) GetTagStats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	days := service.DefaultTagStatsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		value, err := strconv.Atoi(daysStr)
+		if err != nil || value < 1 || value > service.MaxTagStatsDays {
+			response.Error(c, http.StatusBadRequest, "统计天数必须在1到365之间")
+			return
+		}
+		days = value
+	}
+
+	sortBy := c.DefaultQuery("sort", model.TagStatsSortRetention)
+	switch sortBy {
+	case model.TagStatsSortRetention, model.TagStatsSortCards, model.TagStatsSortDue, model.TagStatsSortName:
+	default:
+		response.Error(c, http.StatusBadRequest, "不支持的排序方式")
+		return
+	}
+
+	stats, err := h.tagsService.GetTagStats(userID.(uint), days, sortBy)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// @Summary 移动标签
+// @Description 将标签及其下级标签移动到新的上级标签下
+// @Tags 标签
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "标签ID"
+// @Param request body model.MoveTagRequest true "新的上级标签"
+// @Success 200 {object} response.Response{data=model.Tag}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "标签不存在"
+// @Router /tags/{id}/move [post]
+func (h *TagsHandler) MoveTag(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的标签ID")
+		return
+	}
+
+	var req model.MoveTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	tag, err := h.tagsService.GetTagByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "标签不存在")
+		return
+	}
+	if tag.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此标签")
+		return
+	}
+
+	if err := h.tagsService.MoveTag(tag, req.ParentID); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+// @Summary 合并标签
+// @Description 将标签及其下级标签合并到目标标签：卡片关联转移到目标标签下对应路径的标签，原标签被删除
+// @Tags 标签
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "被合并的标签ID"
+// @Param request body model.MergeTagRequest true "目标标签"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "标签不存在"
+// @Router /tags/{id}/merge [post]
+func (h *TagsHandler) MergeTag(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的标签ID")
+		return
+	}
+
+	var req model.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	source, err := h.tagsService.GetTagByID(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "标签不存在")
+		return
+	}
+	target, err := h.tagsService.GetTagByID(req.TargetID)
+	if err != nil {
+		response.Error(c, http.StatusNotFound, "目标标签不存在")
+		return
+	}
+	if source.UserID != userID.(uint) || target.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此标签")
+		return
+	}
+
+	if err := h.tagsService.MergeTag(source, target); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "标签合并成功"})
 }
@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"ReMindful/internal/service"
+	"ReMindful/pkg/utils/response"
+)
+
+type TrashHandler struct {
+	trashService *service.TrashService
+}
+
+func NewTrashHandler(trashService *service.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// 解析分页参数
+func trashPage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+// @Summary 获取回收站中的卡片
+// @Description 获取当前用户已删除的卡片，最近删除的在前，purge_at 为自动彻底删除的时间
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=object{cards=[]model.TrashedCard,total=int64,page=int,page_size=int}}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /trash/cards [get]
+func (h *TrashHandler) GetTrashedCards(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	page, pageSize := trashPage(c)
+	cards, total, err := h.trashService.GetTrashedCards(userID.(uint), page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"cards":     cards,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// @Summary 获取回收站中的标签
+// @Description 获取当前用户已删除的标签，card_count 为恢复后重新关联的卡片数
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=object{tags=[]model.TrashedTag,total=int64,page=int,page_size=int}}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /trash/tags [get]
+func (h *TrashHandler) GetTrashedTags(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	page, pageSize := trashPage(c)
+	tags, total, err := h.trashService.GetTrashedTags(userID.(uint), page, pageSize)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"tags":      tags,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+// @Summary 恢复卡片
+// @Description 从回收站恢复卡片，放回原卡组；原卡组已删除时放入默认卡组
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "回收站中没有此卡片"
+// @Router /trash/cards/{id}/restore [post]
+func (h *TrashHandler) RestoreCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return
+	}
+
+	card, err := h.trashService.GetTrashedCard(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if card.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return
+	}
+
+	if err := h.trashService.RestoreCard(card); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "卡片恢复成功"})
+}
+
+// @Summary 恢复标签
+// @Description 从回收站恢复标签以及和它一起删除的下级标签，原有的卡片关联随之恢复；已有同名标签时不能恢复
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param id path int true "标签ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "回收站中没有此标签"
+// @Router /trash/tags/{id}/restore [post]
+func (h *TrashHandler) RestoreTag(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的标签ID")
+		return
+	}
+
+	tag, err := h.trashService.GetTrashedTag(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if tag.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此标签")
+		return
+	}
+
+	if err := h.trashService.RestoreTag(tag); err != nil {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "标签恢复成功"})
+}
+
+// @Summary 彻底删除卡片
+// @Description 彻底删除回收站中的卡片及其标签关联、复习日志和修订历史，不可恢复
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param id path int true "卡片ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "回收站中没有此卡片"
+// @Router /trash/cards/{id} [delete]
+func (h *TrashHandler) DeleteCard(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的卡片ID")
+		return
+	}
+
+	card, err := h.trashService.GetTrashedCard(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if card.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此卡片")
+		return
+	}
+
+	if err := h.trashService.DeleteCard(card.ID); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "卡片已彻底删除"})
+}
+
+// @Summary 彻底删除标签
+// @Description 彻底删除回收站中的标签及其卡片关联，不可恢复
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Param id path int true "标签ID"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 403 {object} response.Response "无权限"
+// @Failure 404 {object} response.Response "回收站中没有此标签"
+// @Router /trash/tags/{id} [delete]
+func (h *TrashHandler) DeleteTag(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	idUint, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的标签ID")
+		return
+	}
+
+	tag, err := h.trashService.GetTrashedTag(uint(idUint))
+	if err != nil {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if tag.UserID != userID.(uint) {
+		response.Error(c, http.StatusForbidden, "无权限操作此标签")
+		return
+	}
+
+	if err := h.trashService.DeleteTag(tag.ID); err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"message": "标签已彻底删除"})
+}
+
+// @Summary 清空回收站
+// @Description 彻底删除回收站中的全部卡片和标签，不可恢复
+// @Tags 回收站
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=model.TrashPurgeResult}
+// @Failure 401 {object} response.Response "未授权"
+// @Failure 500 {object} response.Response
+// @Router /trash [delete]
+func (h *TrashHandler) EmptyTrash(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	result, err := h.trashService.EmptyTrash(userID.(uint))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -3,7 +3,6 @@ package handler
 
 import (
 	"net/http"
-	"os"
 
 	"ReMindful/internal/model"
 	"ReMindful/internal/service"
@@ -45,7 +44,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 }
 
 // @Summary     用户登录
-// @Description 用户登录获取token
+// @Description 用户登录，为当前设备创建登录会话，返回短期的访问令牌和一次性的刷新令牌
 // @Tags        用户
 // @Accept      json
 // @Produce     json
@@ -61,8 +60,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	resp, err := h.userService.Login(&req, jwtSecret)
+	resp, err := h.userService.Login(&req, clientInfo(c, req.Device))
 	if err != nil {
 		if err.Error() == "用户不存在" || err.Error() == "密码错误" {
 			response.Error(c, http.StatusUnauthorized, err.Error())
@@ -156,3 +154,101 @@ func (h *UserHandler) SendCode(c *gin.Context) {
 
 	response.Success(c, model.SendCodeResponse{Message: "验证码已发送"})
 }
+
+// ForgotPassword 忘记密码
+// @Summary     忘记密码
+// @Description 向邮箱发送一次性的重置密码验证码，有效期15分钟；无论邮箱是否注册都返回相同的结果。同一邮箱每分钟、同一IP每20秒最多请求一次
+// @Tags        用户
+// @Accept      json
+// @Produce     json
+// @Param       request body model.ForgotPasswordRequest true "邮箱信息"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Failure     429 {object} response.Response
+// @Router      /forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	if err := h.userService.SendPasswordReset(req.Email, c.ClientIP()); err != nil {
+		if err.Error() == "请求过于频繁，请稍后再试" {
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+		} else {
+			response.Error(c, http.StatusInternalServerError, "服务器内部错误")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"message": "如果该邮箱已注册，重置密码的验证码已发送"})
+}
+
+// ResetPassword 重置密码
+// @Summary     重置密码
+// @Description 用邮件中的验证码设置新密码，验证码只能使用一次；重置后所有设备上的登录会话都会注销
+// @Tags        用户
+// @Accept      json
+// @Produce     json
+// @Param       request body model.ResetPasswordRequest true "重置信息"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Router      /reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req model.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	if err := h.userService.ResetPassword(&req); err != nil {
+		switch err.Error() {
+		case "验证码已过期", "验证码错误", "用户不存在":
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "服务器内部错误")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"message": "密码已重置，请重新登录"})
+}
+
+// ChangePassword 修改密码
+// @Summary     修改密码
+// @Description 验证当前密码后设置新密码；修改后所有设备上的登录会话（包括当前会话）都会注销，需要重新登录
+// @Tags        用户
+// @Accept      json
+// @Produce     json
+// @Security    Bearer
+// @Param       request body model.ChangePasswordRequest true "密码信息"
+// @Success     200 {object} response.Response
+// @Failure     400 {object} response.Response
+// @Failure     401 {object} response.Response
+// @Router      /user/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "未授权")
+		return
+	}
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID.(uint), &req); err != nil {
+		switch err.Error() {
+		case "当前密码错误", "新密码不能与当前密码相同", "用户不存在":
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "服务器内部错误")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"message": "密码已修改，请重新登录"})
+}
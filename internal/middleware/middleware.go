@@ -43,8 +43,9 @@ func Logger() gin.HandlerFunc {
 	})
 }
 
-// JWT 认证中间件
-func JWTAuth(jwtSecret string) gin.HandlerFunc {
+// JWT 认证中间件，isRevoked 检查令牌是否已注销或在修改密码、注销全部设备前签发。
+// 旧版token没有 jti 和登录会话，无法注销，一律拒绝并要求重新登录
+func JWTAuth(jwtSecret string, isRevoked func(claims *jwt.Claims) bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if len(token) > 7 && token[:7] == "Bearer " {
@@ -63,7 +64,20 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		if claims.ID == "" || claims.SessionID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token已失效，请重新登录"})
+			c.Abort()
+			return
+		}
+		if isRevoked != nil && isRevoked(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "token已失效，请重新登录"})
+			c.Abort()
+			return
+		}
+
 		c.Set("userID", claims.UserID)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("jti", claims.ID)
 		c.Next()
 	}
 }
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuthSession 登录会话：每次登录（每台设备）一条，刷新令牌轮换时会话保持不变
+// @Description 登录会话，记录设备、最近使用时间和刷新令牌的有效期
+type AuthSession struct {
+	gorm.Model
+	UserID          uint       `json:"user_id" gorm:"index" example:"1"`
+	Device          string     `json:"device" gorm:"size:100" example:"iPhone"` // 客户端登录时传入的设备名称
+	UserAgent       string     `json:"user_agent" gorm:"size:255"`
+	IP              string     `json:"ip" gorm:"size:64" example:"203.0.113.5"`
+	AccessJTI       string     `json:"-" gorm:"size:64"` // 最近签发的访问令牌ID，注销时加入黑名单
+	AccessExpiresAt time.Time  `json:"-"`                // 最近签发的访问令牌的过期时间
+	LastUsedAt      time.Time  `json:"last_used_at"`     // 登录或最近一次刷新的时间
+	ExpiresAt       time.Time  `json:"expires_at"`       // 刷新令牌的过期时间，期间未刷新则会话失效
+	RevokedAt       *time.Time `json:"-" gorm:"index"`   // 注销时间
+	Current         bool       `json:"current" gorm:"-"` // 是否为发起请求的会话
+}
+
+// RefreshToken 签发过的刷新令牌，只保存哈希；每个令牌只能使用一次，再次使用视为泄露
+type RefreshToken struct {
+	ID        uint       `gorm:"primarykey"`
+	SessionID uint       `gorm:"index"`
+	TokenHash string     `gorm:"size:64;uniqueIndex"`
+	UsedAt    *time.Time // 用于换取新令牌的时间
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// RevokedToken 已注销的访问令牌（按 jti），令牌过期后可以删除
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"index"`
+}
+
+// ClientInfo 发起登录或刷新的客户端信息
+type ClientInfo struct {
+	Device    string
+	UserAgent string
+	IP        string
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
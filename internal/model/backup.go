@@ -0,0 +1,103 @@
+package model
+
+import "time"
+
+// 备份归档格式
+const (
+	BackupFormat  = "remindful-backup" // 归档格式标识
+	BackupVersion = 1                  // 当前归档版本
+)
+
+// BackupManifest 备份归档清单（manifest.json）
+// @Description 备份归档的版本、文件列表和字段说明
+type BackupManifest struct {
+	Format     string                       `json:"format" example:"remindful-backup"` // 归档格式标识
+	Version    int                          `json:"version" example:"1"`               // 归档版本
+	ExportedAt time.Time                    `json:"exported_at"`                       // 导出时间
+	UserID     uint                         `json:"user_id"`                           // 导出时的用户ID（恢复时会重新映射）
+	Files      []BackupFile                 `json:"files"`                             // 归档包含的文件
+	Schema     map[string]map[string]string `json:"schema"`                            // 各文件的字段说明
+}
+
+// BackupFile 归档中的单个文件
+type BackupFile struct {
+	Name        string `json:"name" example:"cards.jsonl"` // 文件名
+	Description string `json:"description"`                // 文件说明
+	Records     int    `json:"records"`                    // 记录数
+}
+
+// BackupProfile 备份中的用户资料（profile.json）
+type BackupProfile struct {
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	PhotoURL  string    `json:"photo_url"`
+	Timezone  string    `json:"timezone"`
+	IsPremium bool      `json:"is_premium"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupTag 备份中的标签（tags.jsonl 每行一条）
+type BackupTag struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	ColorCode string    `json:"color_code"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupDeck 备份中的卡组（decks.jsonl 每行一条）
+type BackupDeck struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	ParentID  *uint     `json:"parent_id"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupCard 备份中的学习卡片（cards.jsonl 每行一条）
+type BackupCard struct {
+	ID           uint      `json:"id"`
+	DeckID       uint      `json:"deck_id"`
+	Title        string    `json:"title"`
+	Content      string    `json:"content"`
+	CardType     CardType  `json:"card_type"`
+	NextReview   time.Time `json:"next_review"`
+	LastReviewAt time.Time `json:"last_review_at"`
+	ReviewCount  int       `json:"review_count"`
+	LearningStep int       `json:"learning_step,omitempty"`
+	Interval     int       `json:"interval"`
+	EaseFactor   float64   `json:"ease_factor"`
+	Difficulty   float64   `json:"difficulty"`
+	SourceAnchor string    `json:"source_anchor,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BackupCardTag 备份中的卡片标签关联（card_tags.jsonl 每行一条）
+type BackupCardTag struct {
+	CardID uint `json:"card_id"`
+	TagID  uint `json:"tag_id"`
+}
+
+// BackupReviewLog 备份中的复习日志（review_logs.jsonl 每行一条）
+type BackupReviewLog struct {
+	ID            uint      `json:"id"`
+	CardID        uint      `json:"card_id"`
+	ReviewTime    time.Time `json:"review_time"`
+	Performance   int       `json:"performance"`
+	Duration      int       `json:"duration"`
+	ElapsedDays   float64   `json:"elapsed_days,omitempty"`
+	ScheduledDays float64   `json:"scheduled_days,omitempty"`
+	NewCard       bool      `json:"new_card,omitempty"`
+}
+
+// RestoreResult 恢复结果
+// @Description 从备份归档恢复的数据统计
+type RestoreResult struct {
+	Version    int `json:"version"`     // 归档版本
+	Decks      int `json:"decks"`       // 恢复的卡组数
+	Tags       int `json:"tags"`        // 恢复的标签数
+	Cards      int `json:"cards"`       // 恢复的卡片数
+	CardTags   int `json:"card_tags"`   // 恢复的卡片标签关联数
+	ReviewLogs int `json:"review_logs"` // 恢复的复习日志数
+	Skipped    int `json:"skipped"`     // 因引用缺失而跳过的记录数
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// 批量操作类型
+const (
+	BulkAddTags    = "add_tags"    // 添加标签
+	BulkRemoveTags = "remove_tags" // 移除标签
+	BulkSetType    = "set_type"    // 修改卡片类型
+	BulkMoveDeck   = "move_deck"   // 移动到卡组
+	BulkForget     = "forget"      // 重置为新卡片
+	BulkReschedule = "reschedule"  // 在日期范围内随机安排下次复习时间
+	BulkSuspend    = "suspend"     // 暂停
+	BulkUnsuspend  = "unsuspend"   // 取消暂停
+	BulkDelete     = "delete"      // 删除（移入回收站）
+)
+
+// BulkCardsRequest 批量操作请求
+// @Description 对一批卡片执行同一操作，卡片由 card_ids 或搜索语句 query 指定（二选一）
+type BulkCardsRequest struct {
+	Action      string     `json:"action" binding:"required" example:"add_tags" enums:"add_tags,remove_tags,set_type,move_deck,forget,reschedule,suspend,unsuspend,delete"`
+	CardIDs     []uint     `json:"card_ids"`                                // 卡片ID列表
+	Query       string     `json:"query" example:"tag:git is:due"`          // 搜索语句，语法同卡片列表的 q 参数
+	TagIDs      []uint     `json:"tag_ids"`                                 // add_tags、remove_tags 使用
+	CardType    CardType   `json:"card_type" example:"cloze"`               // set_type 使用
+	DeckID      uint       `json:"deck_id" example:"1"`                     // move_deck 使用
+	DueFrom     *time.Time `json:"due_from" example:"2024-03-01T00:00:00Z"` // reschedule 使用，下次复习时间范围的开始
+	DueTo       *time.Time `json:"due_to" example:"2024-03-07T00:00:00Z"`   // reschedule 使用，下次复习时间范围的结束，为空时等于开始
+	ArchiveLogs bool       `json:"archive_logs"`                            // forget 使用，归档复习日志：日志保留但不再计入统计
+}
+
+// BulkItemResult 单张卡片的操作结果
+type BulkItemResult struct {
+	ID      uint   `json:"id" example:"1"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty" example:"无权限操作此卡片"`
+}
+
+// BulkCardsResult 批量操作结果
+// @Description 批量操作结果，results 按卡片ID列出每张卡片的结果
+type BulkCardsResult struct {
+	Action    string            `json:"action" example:"add_tags"`
+	Total     int               `json:"total" example:"120"`
+	Succeeded int               `json:"succeeded" example:"118"`
+	Failed    int               `json:"failed" example:"2"`
+	Results   []*BulkItemResult `json:"results"`
+}
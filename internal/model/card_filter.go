@@ -0,0 +1,23 @@
+package model
+
+// CardFilter 卡片筛选条件，用于筛选卡组等需要保存查询的场景
+// @Description 卡片筛选条件，各条件之间为"且"的关系
+type CardFilter struct {
+	Query              string     `json:"query,omitempty" example:"tag:git -tag:done due<3d"`                // 搜索语句，语法见 pkg/cardquery
+	TagIDs             []uint     `json:"tag_ids,omitempty"`                                                 // 包含任一标签
+	DeckIDs            []uint     `json:"deck_ids,omitempty"`                                                // 属于任一卡组（包含子卡组）
+	CardTypes          []CardType `json:"card_types,omitempty"`                                              // 卡片类型
+	FailedWithinDays   int        `json:"failed_within_days,omitempty" example:"7"`                          // 最近N天内答错过（自评低于3分）
+	ReviewedWithinDays int        `json:"reviewed_within_days,omitempty" example:"0"`                        // 最近N天内复习过
+	DueWithinDays      *int       `json:"due_within_days,omitempty" example:"0"`                             // N天内到期（0表示已到期）
+	OnlyNew            bool       `json:"only_new,omitempty"`                                                // 仅未复习过的新卡片
+	Order              string     `json:"order,omitempty" example:"due" enums:"due,random,added,difficulty"` // 排序方式
+}
+
+// 筛选结果的排序方式
+const (
+	FilterOrderDue        = "due"        // 按到期时间
+	FilterOrderRandom     = "random"     // 随机
+	FilterOrderAdded      = "added"      // 按创建时间
+	FilterOrderDifficulty = "difficulty" // 难度从高到低
+)
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"ReMindful/pkg/textdiff"
+
+	"gorm.io/gorm"
+)
+
+// 修订的来源
+const (
+	RevisionBaseline = "baseline" // 首次修改前的原始版本
+	RevisionUpdate   = "update"   // 编辑卡片
+	RevisionRestore  = "restore"  // 恢复到旧版本
+)
+
+// CardRevision 卡片修订记录，保存每次修改后的完整内容
+// @Description 卡片修订记录
+type CardRevision struct {
+	gorm.Model
+	CardID        uint          `json:"card_id" gorm:"index" example:"1"`                // 卡片ID
+	EditorID      uint          `json:"editor_id" example:"1"`                           // 修改人ID
+	Action        string        `json:"action" gorm:"size:20" example:"update"`          // 来源：baseline/update/restore
+	Title         string        `json:"title" example:"Git基础知识"`                         // 标题
+	Content       string        `json:"content"`                                         // 内容
+	CardType      CardType      `json:"card_type" gorm:"size:20" example:"basic"`        // 卡片类型
+	Tags          []RevisionTag `json:"tags" gorm:"serializer:json;type:text"`           // 标签
+	ChangedFields []string      `json:"changed_fields" gorm:"serializer:json;type:text"` // 相对上一版本修改的字段
+	RestoredFrom  *uint         `json:"restored_from,omitempty" example:"3"`             // 恢复自哪个修订
+}
+
+// RevisionTag 修订中保存的标签
+type RevisionTag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// RevisionDiff 两个修订之间的差异
+// @Description 两个修订之间的差异
+type RevisionDiff struct {
+	From        uint          `json:"from"`                // 旧修订ID
+	To          uint          `json:"to"`                  // 新修订ID
+	Title       []textdiff.Op `json:"title"`               // 标题按字比较
+	Content     []textdiff.Op `json:"content"`             // 内容按行比较
+	CardType    *FieldChange  `json:"card_type,omitempty"` // 卡片类型的变化
+	TagsAdded   []string      `json:"tags_added"`          // 新增的标签
+	TagsRemoved []string      `json:"tags_removed"`        // 移除的标签
+}
+
+// FieldChange 字段值的变化
+type FieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
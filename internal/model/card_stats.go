@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// CardReviewEvent 卡片复习时间线上的一次复习
+type CardReviewEvent struct {
+	ReviewLogID    uint      `json:"review_log_id" example:"301"`
+	ReviewTime     time.Time `json:"review_time"`
+	Performance    int       `json:"performance" example:"4"`
+	Duration       int       `json:"duration" example:"12"`            // 耗时（秒）
+	Kind           string    `json:"kind" example:"review"`            // new、learn 或 review
+	ElapsedDays    float64   `json:"elapsed_days" example:"9.8"`       // 距上次复习的天数，首次复习为距创建的天数
+	IntervalBefore *float64  `json:"interval_before" example:"10"`     // 复习前的计划间隔（天），旧日志没有记录时为 null
+	IntervalAfter  *float64  `json:"interval_after" example:"24"`      // 复习后安排的间隔（天），无法确定时为 null
+	IntervalChange *float64  `json:"interval_change" example:"14"`     // 间隔的变化（天），前后间隔都已知时才有
+	SessionID      *uint     `json:"session_id,omitempty" example:"5"` // 所属的学习会话
+}
+
+// CardStats 单张卡片的统计
+// @Description 卡片的复习次数、遗忘次数、平均耗时、当前和上一次间隔、预测的回忆概率以及完整的复习时间线
+type CardStats struct {
+	CardID            uint               `json:"card_id" example:"12"`
+	CreatedAt         time.Time          `json:"created_at"`
+	FirstSeen         *time.Time         `json:"first_seen"` // 第一次复习的时间，没有复习过时为 null
+	LastReview        *time.Time         `json:"last_review"`
+	NextReview        *time.Time         `json:"next_review"` // 没有复习过时为 null
+	TotalReviews      int                `json:"total_reviews" example:"9"`
+	Lapses            int                `json:"lapses" example:"2"`                // 评分低于 3 的次数（不含首次学习）
+	AvgSeconds        float64            `json:"avg_seconds" example:"11.5"`        // 每次复习的平均耗时（秒）
+	TotalSeconds      int                `json:"total_seconds" example:"104"`       // 复习耗时之和（秒）
+	CurrentInterval   float64            `json:"current_interval" example:"24"`     // 当前的计划间隔（天）
+	PreviousInterval  *float64           `json:"previous_interval" example:"10"`    // 最后一次复习前的计划间隔（天），无法确定时为 null
+	RetrievabilityNow *float64           `json:"retrievability_now" example:"0.93"` // 按遗忘曲线估算的当前回忆概率，没有复习过时为 null
+	RetrievabilityDue *float64           `json:"retrievability_due" example:"0.9"`  // 到下次复习时的回忆概率
+	Timeline          []*CardReviewEvent `json:"timeline"`                          // 按时间顺序的复习记录
+}
@@ -1,7 +1,12 @@
 package model
 
 
+// CardTag 卡片与标签多对多关系的连接表（对应 LearningCard.Tags 的 card_tags 表）
 type CardTag struct {
-	CardID    uint `json:"card_id" gorm:"primaryKey"`
-	TagID     uint `json:"tag_id" gorm:"primaryKey"`
-  }	
+	LearningCardID uint `json:"card_id" gorm:"primaryKey"`
+	TagID          uint `json:"tag_id" gorm:"primaryKey;index"`
+  }
+
+func (CardTag) TableName() string {
+	return "card_tags"
+}	
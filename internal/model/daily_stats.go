@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// DailyStatDateLayout 每日统计的日期格式
+const DailyStatDateLayout = "2006-01-02"
+
+// DailyStat 用户每天的复习汇总，每次复习时增量更新，统计和热力图从这里读取而不是扫描复习日志。
+// 日期按用户设置的时区划分，写入时在 Go 中计算；已归档的复习日志不计入
+type DailyStat struct {
+	ID             uint      `json:"-" gorm:"primarykey"`
+	UserID         uint      `json:"user_id" gorm:"uniqueIndex:idx_daily_stats_user_date,priority:1"`
+	Date           string    `json:"date" gorm:"size:10;uniqueIndex:idx_daily_stats_user_date,priority:2" example:"2024-05-01"`
+	Reviews        int       `json:"reviews" example:"120"`         // 复习次数
+	Passed         int       `json:"passed" example:"100"`          // 评分不低于 3 的复习次数
+	Duration       int       `json:"duration" example:"1500"`       // 复习耗时之和（秒）
+	PerformanceSum int       `json:"performance_sum" example:"480"` // 评分之和，用于计算平均评分
+	Rating1        int       `json:"rating1" example:"5"`           // 各评分的复习次数
+	Rating2        int       `json:"rating2" example:"5"`
+	Rating3        int       `json:"rating3" example:"20"`
+	Rating4        int       `json:"rating4" example:"50"`
+	Rating5        int       `json:"rating5" example:"40"`
+	NewCards       int       `json:"new_cards" example:"20"`    // 首次学习的新卡片
+	LearnCards     int       `json:"learn_cards" example:"30"`  // 学习或重新学习阶段的复习
+	ReviewCards    int       `json:"review_cards" example:"70"` // 正常复习
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultDeckName 默认卡组的名称
+const DefaultDeckName = "默认"
+
+// Deck 卡组模型，支持父子嵌套，每张卡片属于且仅属于一个卡组
+// @Description 卡组信息
+type Deck struct {
+	gorm.Model
+	UserID    uint   `json:"user_id" gorm:"index" example:"1"`  // 用户ID
+	Name      string `json:"name" gorm:"size:100" example:"英语"` // 卡组名称
+	ParentID  *uint  `json:"parent_id" gorm:"index"`            // 父卡组ID，为空表示顶级卡组
+	PresetID  *uint  `json:"preset_id"`                         // 调度选项预设ID，为空使用默认预设
+	IsDefault bool   `json:"is_default" gorm:"default:false"`   // 是否为默认卡组
+	// 筛选卡组：按保存的查询临时从原卡组中抽取卡片，清空后卡片回到原卡组
+	IsFiltered  bool        `json:"is_filtered" gorm:"default:false"`                        // 是否为筛选卡组
+	FilterQuery *CardFilter `json:"filter_query,omitempty" gorm:"serializer:json;type:text"` // 筛选条件
+	FilterLimit int         `json:"filter_limit,omitempty"`                                  // 最多抽取的卡片数
+	Reschedule  bool        `json:"reschedule"`                                              // 复习是否影响长期间隔，否则为只练习不改排程的冲刺模式
+	Counts      *DeckCounts `json:"counts,omitempty" gorm:"-"`                               // 卡片数量（包含子卡组）
+	Children    []*Deck     `json:"children,omitempty" gorm:"-"`                             // 子卡组
+}
+
+// DeckCounts 卡组中的卡片数量
+type DeckCounts struct {
+	Total int64 `json:"total"` // 卡片总数
+	New   int64 `json:"new"`   // 未复习过的新卡片数
+	Due   int64 `json:"due"`   // 已到期的卡片数
+}
+
+// DeckPreset 卡组调度选项预设，可被多个卡组共用
+// @Description 卡组调度选项预设
+type DeckPreset struct {
+	gorm.Model
+	UserID           uint   `json:"user_id" gorm:"index"`                                 // 用户ID
+	Name             string `json:"name" gorm:"size:50" example:"默认"`                     // 预设名称
+	NewCardsPerDay   int    `json:"new_cards_per_day" gorm:"default:20" example:"20"`     // 每日新卡片上限
+	MaxReviewsPerDay int    `json:"max_reviews_per_day" gorm:"default:200" example:"200"` // 每日复习上限
+	LearningSteps    string `json:"learning_steps" gorm:"size:100" example:"1m 10m"`      // 学习步长，空格分隔，如 1m 10m 1h
+	MaxInterval      int    `json:"max_interval" gorm:"default:36500" example:"36500"`    // 最大间隔天数
+}
+
+// DefaultDeckPreset 未设置预设的卡组使用的调度选项
+var DefaultDeckPreset = DeckPreset{
+	Name:             "默认",
+	NewCardsPerDay:   20,
+	MaxReviewsPerDay: 200,
+	LearningSteps:    "1m 10m",
+	MaxInterval:      36500,
+}
+
+// ParseLearningSteps 解析学习步长，如 "1m 10m 1h 1d"
+func ParseLearningSteps(steps string) ([]time.Duration, error) {
+	var result []time.Duration
+	for _, step := range strings.Fields(steps) {
+		if len(step) < 2 {
+			return nil, fmt.Errorf("无效的学习步长: %s", step)
+		}
+		n, err := strconv.Atoi(step[:len(step)-1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("无效的学习步长: %s", step)
+		}
+		switch step[len(step)-1] {
+		case 'm':
+			result = append(result, time.Duration(n)*time.Minute)
+		case 'h':
+			result = append(result, time.Duration(n)*time.Hour)
+		case 'd':
+			result = append(result, time.Duration(n)*24*time.Hour)
+		default:
+			return nil, fmt.Errorf("无效的学习步长: %s", step)
+		}
+	}
+	return result, nil
+}
+
+// CreateDeckRequest 创建卡组请求
+type CreateDeckRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=100" example:"英语"`
+	ParentID *uint  `json:"parent_id"`
+	PresetID *uint  `json:"preset_id"`
+}
+
+// FilteredDeckRequest 创建/更新筛选卡组请求
+type FilteredDeckRequest struct {
+	Name       string     `json:"name" binding:"required,min=1,max=100" example:"考前冲刺"`
+	Filter     CardFilter `json:"filter"`
+	Limit      int        `json:"limit" binding:"omitempty,min=1,max=9999" example:"100"` // 为空时默认100
+	Reschedule bool       `json:"reschedule"`                                             // 复习是否影响长期间隔
+}
+
+// UpdateDeckRequest 更新卡组请求
+type UpdateDeckRequest struct {
+	Name     string `json:"name" binding:"omitempty,min=1,max=100"`
+	PresetID *uint  `json:"preset_id"` // 设为0表示使用默认预设
+}
+
+// MoveDeckRequest 移动卡组请求
+type MoveDeckRequest struct {
+	ParentID *uint `json:"parent_id"` // 新的父卡组ID，为空表示移动到顶级
+}
+
+// DeckPresetRequest 创建/更新调度选项预设请求
+type DeckPresetRequest struct {
+	Name             string `json:"name" binding:"required,min=1,max=50"`
+	NewCardsPerDay   int    `json:"new_cards_per_day" binding:"min=0,max=9999"`
+	MaxReviewsPerDay int    `json:"max_reviews_per_day" binding:"min=0,max=99999"`
+	LearningSteps    string `json:"learning_steps" binding:"max=100"`
+	MaxInterval      int    `json:"max_interval" binding:"omitempty,min=1,max=36500"` // 为空时使用默认最大间隔
+}
@@ -0,0 +1,37 @@
+package model
+
+// 重复类型
+const (
+	DuplicateExact = "exact" // 规范化后内容完全相同
+	DuplicateNear  = "near"  // 内容近似
+)
+
+// CardBand 卡片 MinHash 签名的 LSH 分段哈希，每张卡片每段一行。
+// 创建卡片时按 (user_id, band, hash) 查找近似重复的候选卡片，不必扫描用户的全部卡片
+type CardBand struct {
+	CardID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Band   uint8  `gorm:"primaryKey;autoIncrement:false;index:idx_card_bands_lookup,priority:2"`
+	UserID uint   `gorm:"index:idx_card_bands_lookup,priority:1"`
+	Hash   uint64 `gorm:"index:idx_card_bands_lookup,priority:3"`
+}
+
+// DuplicateCandidate 重复的候选卡片
+// @Description 重复的候选卡片
+type DuplicateCandidate struct {
+	ID         uint    `json:"id" example:"1"`
+	Title      string  `json:"title" example:"Git基础知识"`
+	Similarity float64 `json:"similarity" example:"0.86"` // 估算的相似度（0-1），完全重复为1
+}
+
+// DuplicateCluster 一组互相重复的卡片
+// @Description 一组互相重复的卡片
+type DuplicateCluster struct {
+	Kind  string                `json:"kind" example:"near" enums:"exact,near"` // 重复类型
+	Cards []*DuplicateCandidate `json:"cards"`
+}
+
+// MergeCardsRequest 合并卡片请求
+type MergeCardsRequest struct {
+	CardIDs []uint `json:"card_ids" binding:"required,min=2,max=50"` // 要合并的卡片ID
+	KeepID  uint   `json:"keep_id"`                                  // 保留的卡片ID，为空时保留复习记录最丰富的卡片
+}
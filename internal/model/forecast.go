@@ -0,0 +1,36 @@
+package model
+
+// 到期预测的分组方式
+const (
+	ForecastGroupTag  = "tag"  // 按卡片直接使用的标签分组，使用多个标签的卡片计入每个标签
+	ForecastGroupDeck = "deck" // 按卡片所属卡组分组（位于筛选卡组中的卡片计入原卡组）
+)
+
+// DueForecastDay 某一天的到期预测
+type DueForecastDay struct {
+	Date           string  `json:"date" example:"2024-03-01"`
+	Due            int64   `json:"due" example:"25"`              // 当天到期的卡片数
+	Backlog        int64   `json:"backlog" example:"40"`          // 假设从今天起不再复习，到当天为止累计待复习的卡片数（含已过期的卡片）
+	Minutes        float64 `json:"minutes" example:"4.2"`         // 复习当天到期卡片的预计用时（分钟）
+	BacklogMinutes float64 `json:"backlog_minutes" example:"6.7"` // 复习累计待复习卡片的预计用时（分钟）
+}
+
+// DueForecastGroup 一个分组的到期预测
+type DueForecastGroup struct {
+	ID      uint              `json:"id" example:"1"`       // 标签或卡组ID，为 0 表示没有标签
+	Name    string            `json:"name" example:"Go语言"`  // 标签路径或卡组名称
+	Overdue int64             `json:"overdue" example:"15"` // 今天之前已到期的卡片数
+	Days    []*DueForecastDay `json:"days"`
+}
+
+// DueForecast 未来到期卡片的预测
+// @Description 未来每天到期的卡片数，日期按用户时区划分，不包含暂停的卡片
+type DueForecast struct {
+	Days             int                 `json:"days" example:"30"`                 // 预测天数
+	Timezone         string              `json:"timezone" example:"Asia/Shanghai"`  // 划分日期使用的时区
+	AvgReviewSeconds float64             `json:"avg_review_seconds" example:"10.5"` // 估算用时使用的每次复习平均耗时（秒）
+	Overdue          int64               `json:"overdue" example:"15"`              // 今天之前已到期的卡片数
+	Forecast         []*DueForecastDay   `json:"forecast"`
+	GroupBy          string              `json:"group_by,omitempty" example:"tag"`
+	Groups           []*DueForecastGroup `json:"groups,omitempty"`
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 每日目标的类型
+const (
+	GoalTypeCards   = "cards"   // 复习卡片数
+	GoalTypeMinutes = "minutes" // 学习分钟数
+)
+
+// StudyGoal 用户的每日目标及连续完成记录，每个用户一条
+// @Description 每日目标设置以及连续完成目标的天数和冻结天数
+type StudyGoal struct {
+	gorm.Model
+	UserID        uint   `json:"user_id" gorm:"uniqueIndex" example:"1"`
+	GoalType      string `json:"goal_type" gorm:"size:20" example:"cards"` // 目标类型：cards 或 minutes
+	Target        int    `json:"target" example:"50"`                      // 每天需要完成的卡片数或分钟数
+	CurrentStreak int    `json:"current_streak" example:"12"`              // 截至最近一次完成目标时的连续天数
+	LongestStreak int    `json:"longest_streak" example:"40"`
+	LastGoalDate  string `json:"last_goal_date" gorm:"size:10" example:"2024-05-01"` // 最近一次完成目标的日期（用户时区）
+	Freezes       int    `json:"freezes" example:"1"`                                // 可用的冻结天数，漏掉一天时自动使用以保持连续
+	FreezesUsed   int    `json:"freezes_used" example:"3"`                           // 累计使用的冻结天数
+}
+
+// UpdateGoalRequest 设置每日目标请求
+type UpdateGoalRequest struct {
+	GoalType string `json:"goal_type" binding:"required,oneof=cards minutes" example:"cards"` // 目标类型：cards 或 minutes
+	Target   int    `json:"target" binding:"required,min=1,max=1440" example:"50"`            // 每天需要完成的卡片数或分钟数
+}
+
+// GoalProgress 今天的目标完成情况
+type GoalProgress struct {
+	Date      string  `json:"date" example:"2024-05-02"` // 用户时区的日期
+	GoalType  string  `json:"goal_type" example:"cards"`
+	Target    int     `json:"target" example:"50"`
+	Done      int     `json:"done" example:"32"`      // 今天已完成的卡片数或分钟数
+	Percent   float64 `json:"percent" example:"0.64"` // 完成比例，最大为 1
+	Completed bool    `json:"completed" example:"false"`
+}
+
+// StreakStatus 连续完成目标的情况
+type StreakStatus struct {
+	Current      int    `json:"current" example:"12"` // 当前连续天数，漏掉的天数超过可用冻结天数时为 0
+	Longest      int    `json:"longest" example:"40"`
+	LastGoalDate string `json:"last_goal_date" example:"2024-05-01"`
+	Freezes      int    `json:"freezes" example:"1"`        // 可用的冻结天数
+	FreezesUsed  int    `json:"freezes_used" example:"3"`   // 累计使用的冻结天数
+	PendingDays  int    `json:"pending_days" example:"0"`   // 上次完成目标后漏掉的天数，下次完成目标时用冻结天数抵扣
+	ReviewStreak int    `json:"review_streak" example:"15"` // 连续有复习记录的天数（不考虑目标）
+}
+
+// GoalStatus 每日目标、今天的进度和连续天数
+// @Description 每日目标、今天的完成进度以及连续完成目标的天数
+type GoalStatus struct {
+	Progress *GoalProgress `json:"progress"`
+	Streak   *StreakStatus `json:"streak"`
+}
+
+// 成就使用的统计指标
+const (
+	MetricReviews     = "reviews"      // 累计复习次数
+	MetricStreak      = "streak"       // 最长连续完成目标天数
+	MetricMatureCards = "mature_cards" // 成熟卡片数
+)
+
+// Achievement 成就定义
+type Achievement struct {
+	Code        string `json:"code" example:"reviews_1000"`
+	Name        string `json:"name" example:"千锤百炼"`
+	Description string `json:"description" example:"累计复习 1000 次"`
+	Metric      string `json:"metric" example:"reviews"` // 统计指标：reviews、streak 或 mature_cards
+	Threshold   int    `json:"threshold" example:"1000"` // 指标达到该值时解锁
+}
+
+// UserAchievement 用户已解锁的成就
+type UserAchievement struct {
+	ID         uint      `json:"id" gorm:"primarykey"`
+	UserID     uint      `json:"user_id" gorm:"uniqueIndex:idx_user_achievements_code,priority:1"`
+	Code       string    `json:"code" gorm:"size:50;uniqueIndex:idx_user_achievements_code,priority:2"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// AchievementStatus 成就及用户的完成情况
+// @Description 成就定义、当前进度以及解锁时间
+type AchievementStatus struct {
+	Achievement
+	Progress   int        `json:"progress" example:"640"` // 指标的当前值
+	Unlocked   bool       `json:"unlocked" example:"false"`
+	UnlockedAt *time.Time `json:"unlocked_at"`
+}
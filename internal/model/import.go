@@ -0,0 +1,49 @@
+package model
+
+// DuplicatePolicy 导入时遇到重复卡片的处理策略
+type DuplicatePolicy string
+
+const (
+	DuplicateSkip   DuplicatePolicy = "skip"   // 跳过重复卡片
+	DuplicateUpdate DuplicatePolicy = "update" // 更新已有卡片
+	DuplicateCreate DuplicatePolicy = "create" // 仍然创建新卡片
+)
+
+// CSVImportOptions CSV/TSV导入选项
+type CSVImportOptions struct {
+	Delimiter       rune              // 字段分隔符
+	HasHeader       *bool             // 是否包含表头，nil 表示自动检测
+	Mapping         map[string]string // 字段映射：title/content/card_type/tags -> 列名或列序号(从0开始)
+	TagSeparator    string            // 标签列中多个标签的分隔符
+	DefaultCardType CardType          // 未指定卡片类型时使用的默认类型
+	DuplicatePolicy DuplicatePolicy   // 重复处理策略
+	DryRun          bool              // 仅校验，不写入数据库
+}
+
+// MarkdownImportOptions Markdown笔记库导入选项
+type MarkdownImportOptions struct {
+	FolderTags bool // 是否将文件夹路径作为标签
+	DryRun     bool // 仅解析，不写入数据库
+}
+
+// ImportRowError 导入时某一行的错误
+// @Description 导入校验失败的行
+type ImportRowError struct {
+	File    string `json:"file,omitempty" example:"notes/go.md"` // 所在文件（Markdown导入）
+	Row     int    `json:"row" example:"3"`                      // 行号（从1开始，包含表头）
+	Field   string `json:"field,omitempty" example:"title"`      // 出错字段
+	Message string `json:"message" example:"标题不能为空"`             // 错误信息
+}
+
+// ImportResult 导入结果
+// @Description 批量导入的结果统计
+type ImportResult struct {
+	DryRun          bool             `json:"dry_run"`          // 是否为试运行
+	TotalRows       int              `json:"total_rows"`       // 处理的数据行数
+	Created         int              `json:"created"`          // 新建卡片数
+	Updated         int              `json:"updated"`          // 更新卡片数
+	Skipped         int              `json:"skipped"`          // 跳过的重复卡片数
+	Failed          int              `json:"failed"`           // 校验失败的行数
+	Errors          []ImportRowError `json:"errors"`           // 错误明细
+	ErrorsTruncated bool             `json:"errors_truncated"` // 错误明细是否被截断
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// TimeSlotStats 某个时间段（小时或星期几）的复习情况
+type TimeSlotStats struct {
+	Slot         int      `json:"slot" example:"21"`        // 小时（0-23）或星期几（0 为星期日）
+	Reviews      int      `json:"reviews" example:"120"`    // 复习次数
+	Passed       int      `json:"passed" example:"100"`     // 评分不低于 3 的复习次数
+	PassRate     *float64 `json:"pass_rate" example:"0.83"` // 通过率，没有复习时为 null
+	StudySeconds int      `json:"study_seconds" example:"1500"`
+}
+
+// Add 记录一次复习
+func (s *TimeSlotStats) Add(passed bool, seconds int) {
+	s.Reviews++
+	if passed {
+		s.Passed++
+	}
+	s.StudySeconds += seconds
+	rate := float64(s.Passed) / float64(s.Reviews)
+	s.PassRate = &rate
+}
+
+// CardTypeTiming 某种卡片类型的平均复习耗时
+type CardTypeTiming struct {
+	CardType   CardType `json:"card_type" example:"basic"`
+	Reviews    int      `json:"reviews" example:"300"`
+	AvgSeconds float64  `json:"avg_seconds" example:"8.5"` // 每张卡片的平均耗时（秒）
+}
+
+// DetectedSession 根据复习时间间隔识别出的一次学习
+type DetectedSession struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	LengthMinutes float64   `json:"length_minutes" example:"18.5"` // 从开始到结束的时长（分钟）
+	StudySeconds  int       `json:"study_seconds" example:"950"`   // 各次复习耗时之和（秒）
+	Reviews       int       `json:"reviews" example:"60"`
+	Passed        int       `json:"passed" example:"51"`
+	Accuracy      float64   `json:"accuracy" example:"0.85"`
+}
+
+// SessionInsights 学习时段的汇总
+type SessionInsights struct {
+	GapMinutes       int                `json:"gap_minutes" example:"30"` // 相邻复习间隔超过该值时视为新的一次学习
+	Count            int                `json:"count" example:"24"`
+	AvgLengthMinutes float64            `json:"avg_length_minutes" example:"15.2"`
+	AvgReviews       float64            `json:"avg_reviews" example:"42.5"`
+	AvgAccuracy      float64            `json:"avg_accuracy" example:"0.84"` // 各次学习的准确率按复习次数加权的平均值
+	Recent           []*DetectedSession `json:"recent"`                      // 最近的学习，按开始时间倒序
+}
+
+// StudyInsights 学习时间分析
+// @Description 根据复习日志分析一天中各小时、一周中各天的复习量和通过率，各卡片类型的平均耗时，以及识别出的学习时段
+type StudyInsights struct {
+	Days        int               `json:"days" example:"90"`                // 统计窗口的天数
+	Since       time.Time         `json:"since"`                            // 统计窗口的开始时间
+	Timezone    string            `json:"timezone" example:"Asia/Shanghai"` // 划分小时和星期使用的时区
+	Hourly      []*TimeSlotStats  `json:"hourly"`                           // 按小时（0-23）
+	Weekday     []*TimeSlotStats  `json:"weekday"`                          // 按星期几（0 为星期日）
+	BestHour    *int              `json:"best_hour"`                        // 复习次数足够的小时中通过率最高的，数据不足时为 null
+	BestWeekday *int              `json:"best_weekday"`                     // 复习次数足够的星期几中通过率最高的，数据不足时为 null
+	ByCardType  []*CardTypeTiming `json:"by_card_type"`
+	Sessions    *SessionInsights  `json:"sessions"`
+}
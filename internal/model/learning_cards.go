@@ -1,6 +1,7 @@
 package model
 
 import (
+	"ReMindful/pkg/dedup"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,18 +11,33 @@ import (
 // @Description 学习卡片信息
 type LearningCard struct {
 	gorm.Model
-	UserID       uint        `json:"user_id" example:"1"`                             // 用户ID
-	Title        string      `json:"title" example:"Git基础知识"`                         // 标题
-	Content      string      `json:"content" example:"Git是分布式版本控制系统..."`              // 内容
-	CardType     CardType    `json:"card_type" example:"basic"`                       // 卡片类型
-	NextReview   time.Time   `json:"next_review" example:"2024-02-22T15:04:05Z07:00"` // 下次复习时间
-	LastReviewAt time.Time   `json:"last_review_at"`                                  // 上次复习时间
-	ReviewCount  int         `json:"review_count" example:"0"`                        // 复习次数
-	Interval     int         `json:"interval" example:"1"`                            // 当前间隔天数
-	EaseFactor   float64     `json:"ease_factor" example:"2.5"`                       // 简易因子
-	Difficulty   float64     `json:"difficulty" example:"0.3"`                        // 难度系数
-	Tags         []Tag       `json:"tags" gorm:"many2many:card_tags;"`
-	ReviewLogs   []ReviewLog `json:"review_logs" gorm:"foreignKey:CardID"`
+	UserID         uint        `json:"user_id" example:"1"`                             // 用户ID
+	DeckID         uint        `json:"deck_id" gorm:"index" example:"1"`                // 所属卡组ID
+	OriginalDeckID uint        `json:"original_deck_id,omitempty" gorm:"index"`         // 位于筛选卡组中时的原卡组ID
+	Title          string      `json:"title" example:"Git基础知识"`                         // 标题
+	Content        string      `json:"content" example:"Git是分布式版本控制系统..."`              // 内容
+	CardType       CardType    `json:"card_type" example:"basic"`                       // 卡片类型
+	NextReview     time.Time   `json:"next_review" example:"2024-02-22T15:04:05Z07:00"` // 下次复习时间
+	LastReviewAt   time.Time   `json:"last_review_at"`                                  // 上次复习时间
+	ReviewCount    int         `json:"review_count" example:"0"`                        // 复习次数
+	LearningStep   int         `json:"learning_step" example:"0"`                       // 当前所处的学习步长（从1开始），0 表示不在学习阶段
+	Interval       int         `json:"interval" example:"1"`                            // 当前间隔天数
+	EaseFactor     float64     `json:"ease_factor" example:"2.5"`                       // 简易因子
+	Difficulty     float64     `json:"difficulty" example:"0.3"`                        // 难度系数
+	Suspended      bool        `json:"suspended" gorm:"default:false;index"`            // 已暂停的卡片不出现在复习和学习队列中
+	SourceAnchor   string      `json:"source_anchor,omitempty" gorm:"size:255;index"`   // 导入来源锚点（如 Markdown 笔记中的位置）
+	ContentHash    string      `json:"-" gorm:"size:64;index"`                          // 规范化后标题和内容的哈希，用于检测完全重复
+	MinHash        []byte      `json:"-"`                                               // MinHash 签名，用于检测近似重复
+	Tags           []Tag       `json:"tags" gorm:"many2many:card_tags;"`
+	ReviewLogs     []ReviewLog `json:"review_logs" gorm:"foreignKey:CardID"`
+
+	SimilarCards []*DuplicateCandidate `json:"similar_cards,omitempty" gorm:"-"` // 创建时发现的近似重复卡片
+}
+
+// UpdateFingerprint 根据标题和内容重新计算重复检测用的哈希和签名
+func (c *LearningCard) UpdateFingerprint() {
+	c.ContentHash = dedup.Hash(c.Title, c.Content)
+	c.MinHash = dedup.Encode(dedup.Signature(c.Title + " " + c.Content))
 }
 
 // CardType 卡片类型
@@ -33,11 +49,25 @@ const (
 	QuestionCard CardType = "question" // 问答卡片
 )
 
+// IsValid 判断卡片类型是否合法
+func (t CardType) IsValid() bool {
+	switch t {
+	case BasicCard, ClozeCard, QuestionCard:
+		return true
+	}
+	return false
+}
+
 // CreateCardRequest 创建卡片请求
 // @Description 创建学习卡片的请求参数
 type CreateCardRequest struct {
-	Title    string   `json:"title" binding:"required" example:"Git基础知识"`
-	Content  string   `json:"content" binding:"required" example:"Git是分布式版本控制系统..."`
-	CardType CardType `json:"card_type" binding:"required" example:"basic"`
-	Tags     []Tag    `json:"tags"`
+	Title          string   `json:"title" binding:"required" example:"Git基础知识"`
+	Content        string   `json:"content" binding:"required" example:"Git是分布式版本控制系统..."`
+	CardType       CardType `json:"card_type" binding:"required" example:"basic"`
+	DeckID         uint     `json:"deck_id" example:"1"` // 所属卡组ID，为空时放入默认卡组
+	AllowDuplicate bool     `json:"allow_duplicate"`     // 允许创建与已有卡片内容相同的卡片
+	// 标签：tag_ids 引用自己已有的标签，tag_names 按名称引用（支持 :: 层级路径），不存在时自动创建
+	// 更新卡片时两者都未提供则保留原有标签，提供任意一个（包括空数组）则替换为给定的标签
+	TagIDs   []uint   `json:"tag_ids" example:"1,2"`
+	TagNames []string `json:"tag_names" example:"编程::Git"`
 }
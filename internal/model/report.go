@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// 学习报告的输出格式
+const (
+	ReportFormatJSON = "json"
+	ReportFormatCSV  = "csv"
+	ReportFormatHTML = "html"
+)
+
+// ReportTotals 报告期间的汇总
+type ReportTotals struct {
+	Reviews        int      `json:"reviews" example:"840"`
+	Passed         int      `json:"passed" example:"710"`          // 评分不低于 3 的复习次数
+	PassRate       *float64 `json:"pass_rate" example:"0.85"`      // 通过率，没有复习时为 null
+	StudyMinutes   float64  `json:"study_minutes" example:"190.5"` // 复习耗时之和（分钟）
+	AvgPerformance float64  `json:"avg_performance" example:"3.9"` // 平均评分
+	NewCards       int      `json:"new_cards" example:"120"`
+	LearnCards     int      `json:"learn_cards" example:"200"`
+	ReviewCards    int      `json:"review_cards" example:"520"`
+	ActiveDays     int      `json:"active_days" example:"6"` // 有复习的天数
+}
+
+// ReportDay 报告中一天的复习情况，没有复习的日期也会列出
+type ReportDay struct {
+	Date         string   `json:"date" example:"2024-05-01"`
+	Reviews      int      `json:"reviews" example:"120"`
+	Passed       int      `json:"passed" example:"100"`
+	PassRate     *float64 `json:"pass_rate" example:"0.83"` // 通过率，没有复习时为 null
+	StudyMinutes float64  `json:"study_minutes" example:"25"`
+	NewCards     int      `json:"new_cards" example:"20"`
+	LearnCards   int      `json:"learn_cards" example:"30"`
+	ReviewCards  int      `json:"review_cards" example:"70"`
+}
+
+// HardCard 报告期间答错次数最多的卡片
+type HardCard struct {
+	CardID         uint    `json:"card_id" example:"12"`
+	Title          string  `json:"title" example:"Git基础知识"`
+	Reviews        int     `json:"reviews" example:"9"`
+	Failures       int     `json:"failures" example:"5"` // 评分低于 3 的次数
+	AvgPerformance float64 `json:"avg_performance" example:"2.4"`
+}
+
+// StudyReport 学习报告
+// @Description 一段日期内的学习报告：汇总、每日明细、各标签的保持率和最难的卡片
+type StudyReport struct {
+	StartDate    string             `json:"start_date" example:"2024-04-25"` // 开始日期（包含，用户时区）
+	EndDate      string             `json:"end_date" example:"2024-05-01"`   // 结束日期（包含，用户时区）
+	GeneratedAt  time.Time          `json:"generated_at"`
+	Totals       *ReportTotals      `json:"totals"`
+	Days         []*ReportDay       `json:"days"`
+	Tags         []*RetentionBucket `json:"tags"`          // 各标签（包含下级标签的卡片）的保持率，通过率低的在前
+	HardestCards []*HardCard        `json:"hardest_cards"` // 答错次数最多的卡片
+}
@@ -0,0 +1,67 @@
+package model
+
+import "time"
+
+// 复习评分不低于该值时视为回忆成功
+const PassPerformance = 3
+
+// RetentionBucket 一组复习的通过率
+type RetentionBucket struct {
+	Key      string   `json:"key" example:"mature"`         // 分组名称（卡片类型、标签路径等）
+	TagID    uint     `json:"tag_id,omitempty" example:"1"` // 按标签分组时的标签ID
+	Reviews  int      `json:"reviews" example:"120"`        // 复习次数
+	Passed   int      `json:"passed" example:"102"`         // 评分不低于 3 的复习次数
+	PassRate *float64 `json:"pass_rate" example:"0.85"`     // 通过率，没有复习时为 null
+}
+
+// Add 记录一次复习
+func (b *RetentionBucket) Add(passed bool) {
+	b.Reviews++
+	if passed {
+		b.Passed++
+	}
+	rate := float64(b.Passed) / float64(b.Reviews)
+	b.PassRate = &rate
+}
+
+// ForgettingCurvePoint 遗忘曲线上的一个点：距上次复习的天数落在 [min_days, max_days) 内的复习的回忆率
+type ForgettingCurvePoint struct {
+	MinDays        float64 `json:"min_days" example:"7"`
+	MaxDays        float64 `json:"max_days,omitempty" example:"14"` // 为空表示无上限
+	AvgElapsedDays float64 `json:"avg_elapsed_days" example:"9.6"`
+	Reviews        int     `json:"reviews" example:"40"`
+	RecallRate     float64 `json:"recall_rate" example:"0.82"`
+}
+
+// CalibrationBin 预测回忆概率落在 [min_predicted, max_predicted) 内的复习
+type CalibrationBin struct {
+	MinPredicted float64 `json:"min_predicted" example:"0.8"`
+	MaxPredicted float64 `json:"max_predicted" example:"0.9"`
+	Reviews      int     `json:"reviews" example:"60"`
+	AvgPredicted float64 `json:"avg_predicted" example:"0.86"` // 调度器预测的平均回忆概率
+	ActualRecall float64 `json:"actual_recall" example:"0.81"` // 实际回忆率
+}
+
+// RetentionCalibration 调度器预测的回忆概率与实际结果的对比
+type RetentionCalibration struct {
+	Reviews          int               `json:"reviews" example:"300"`            // 参与校准的复习次数（仅包含记录了计划间隔的复习）
+	AvgPredicted     float64           `json:"avg_predicted" example:"0.88"`     // 平均预测回忆概率
+	ActualRecall     float64           `json:"actual_recall" example:"0.84"`     // 实际回忆率
+	BrierScore       float64           `json:"brier_score" example:"0.12"`       // 预测概率与结果的均方误差，越小越好
+	CalibrationError float64           `json:"calibration_error" example:"0.04"` // 各区间预测与实际偏差按复习次数加权的平均值
+	Bins             []*CalibrationBin `json:"bins"`
+}
+
+// RetentionStats 记忆保持率分析
+// @Description 根据复习日志计算的记忆保持率，学习阶段（卡片首次复习）的复习不计入
+type RetentionStats struct {
+	Days            int                     `json:"days" example:"90"` // 统计窗口的天数
+	Since           time.Time               `json:"since"`             // 统计窗口的开始时间
+	Overall         *RetentionBucket        `json:"overall"`
+	Young           *RetentionBucket        `json:"young"`  // 复习前计划间隔小于 21 天的卡片
+	Mature          *RetentionBucket        `json:"mature"` // 复习前计划间隔不少于 21 天的卡片
+	ByCardType      []*RetentionBucket      `json:"by_card_type"`
+	ByTag           []*RetentionBucket      `json:"by_tag"` // 包含下级标签的卡片，按通过率从低到高排序
+	ForgettingCurve []*ForgettingCurvePoint `json:"forgetting_curve"`
+	Calibration     *RetentionCalibration   `json:"calibration"`
+}
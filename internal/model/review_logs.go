@@ -12,4 +12,8 @@ type ReviewLog struct {
 	ReviewTime time.Time `json:"review_time"`
 	Performance int    `json:"performance" gorm:"check:performance BETWEEN 1 AND 5"` // 用户自评（1-5分）
 	Duration   int     `json:"duration"` // 本次复习耗时（秒）
+	ElapsedDays   float64 `json:"elapsed_days"`   // 距上次复习的天数（首次复习为距创建的天数）
+	ScheduledDays float64 `json:"scheduled_days"` // 复习前调度器安排的间隔天数，为 0 时表示旧日志没有记录
+	NewCard       bool    `json:"new_card"`       // 复习前卡片从未复习过，学习阶段的复习不计入保持率
+	SessionID     *uint   `json:"session_id,omitempty" gorm:"index"` // 所属的学习会话
   }
\ No newline at end of file
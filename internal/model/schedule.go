@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// ForgetCardRequest 重置卡片请求
+type ForgetCardRequest struct {
+	ArchiveLogs bool `json:"archive_logs"` // 归档复习日志：日志保留但不再计入统计
+}
+
+// RescheduleCardRequest 安排卡片复习时间请求
+// @Description 只指定 due_from 时设为该时间，同时指定 due_to 时在范围内随机选择
+type RescheduleCardRequest struct {
+	DueFrom time.Time  `json:"due_from" binding:"required" example:"2024-03-01T00:00:00Z"`
+	DueTo   *time.Time `json:"due_to" example:"2024-03-07T00:00:00Z"`
+}
+
+// PostponeBacklogRequest 分散积压卡片请求
+type PostponeBacklogRequest struct {
+	Days   int  `json:"days" binding:"required,min=1,max=365" example:"7"` // 分散到的天数（含今天）
+	DeckID uint `json:"deck_id" example:"1"`                               // 只处理该卡组（含子卡组），为空时处理全部卡片
+}
+
+// PostponeBacklogResult 分散积压卡片结果
+// @Description 每天安排的复习卡片数，预计保持率越低的卡片越早复习
+type PostponeBacklogResult struct {
+	Total int            `json:"total" example:"420"` // 已到期的卡片数
+	Days  []*PostponeDay `json:"days"`
+}
+
+// PostponeDay 某天安排的卡片数
+type PostponeDay struct {
+	Date  string `json:"date" example:"2024-03-01"`
+	Count int    `json:"count" example:"60"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// CardSearchHit 全文搜索结果
+// @Description 全文搜索命中的卡片，片段中的命中词用 <mark> 包裹，其余内容已做 HTML 转义
+type CardSearchHit struct {
+	Card           *LearningCard `json:"card"`
+	Score          float64       `json:"score" example:"3.2"`                         // 相关度，越大越相关
+	TitleSnippet   string        `json:"title_snippet" example:"<mark>机器学习</mark>入门"` // 高亮后的标题
+	ContentSnippet string        `json:"content_snippet"`                             // 高亮后的内容片段
+}
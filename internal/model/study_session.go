@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 学习会话的状态
+const (
+	SessionActive    = "active"    // 进行中
+	SessionFinished  = "finished"  // 已正常结束
+	SessionAbandoned = "abandoned" // 长时间没有活动，已自动结束
+)
+
+// 复习在学习会话和每日统计中的分类
+const (
+	ReviewKindNew    = "new"    // 首次学习的新卡片
+	ReviewKindLearn  = "learn"  // 学习或重新学习阶段（计划间隔不足一天）
+	ReviewKindReview = "review" // 正常复习
+)
+
+// Kind 复习的分类，计划间隔为 0 的旧日志没有记录间隔，视为正常复习
+func (l *ReviewLog) Kind() string {
+	switch {
+	case l.NewCard:
+		return ReviewKindNew
+	case l.ScheduledDays > 0 && l.ScheduledDays < 1:
+		return ReviewKindLearn
+	}
+	return ReviewKindReview
+}
+
+// StudySession 学习会话：客户端开始学习时创建，期间的复习都关联到该会话
+// @Description 学习会话，记录一次学习的时间、设备、学习范围以及计划和完成的卡片数
+type StudySession struct {
+	gorm.Model
+	UserID         uint       `json:"user_id" gorm:"index" example:"1"`
+	DeckID         uint       `json:"deck_id,omitempty" gorm:"index" example:"1"`                     // 学习的卡组（可以是筛选卡组），为 0 表示不限卡组
+	FilterQuery    string     `json:"filter_query,omitempty" gorm:"size:500" example:"tag:go is:due"` // 学习时使用的搜索语句
+	Device         string     `json:"device,omitempty" gorm:"size:100" example:"iPhone"`
+	Status         string     `json:"status" gorm:"size:20;index" example:"active"`
+	StartedAt      time.Time  `json:"started_at"`
+	LastActiveAt   time.Time  `json:"last_active_at"` // 最后一次心跳或复习的时间
+	EndedAt        *time.Time `json:"ended_at"`
+	PlannedCards   int        `json:"planned_cards" example:"50"`   // 计划学习的卡片数
+	CompletedCards int        `json:"completed_cards" example:"42"` // 已完成的复习次数
+	PassedCards    int        `json:"passed_cards" example:"36"`    // 评分不低于 3 的复习次数
+	NewCards       int        `json:"new_cards" example:"10"`
+	LearnCards     int        `json:"learn_cards" example:"8"`
+	ReviewCards    int        `json:"review_cards" example:"24"`
+	StudySeconds   int        `json:"study_seconds" example:"840"` // 各次复习耗时之和（秒）
+}
+
+// StartSessionRequest 开始学习会话请求
+type StartSessionRequest struct {
+	DeckID       uint   `json:"deck_id" example:"1"`                             // 学习的卡组
+	FilterQuery  string `json:"filter_query" binding:"max=500" example:"is:due"` // 学习时使用的搜索语句
+	Device       string `json:"device" binding:"max=100" example:"iPhone"`       // 设备名称
+	PlannedCards int    `json:"planned_cards" binding:"min=0" example:"50"`      // 计划学习的卡片数，为 0 且指定卡组时使用卡组学习队列的长度
+}
+
+// StudySessionSummary 学习会话结束时的汇总
+type StudySessionSummary struct {
+	*StudySession
+	Remaining         int      `json:"remaining" example:"8"`             // 计划中尚未完成的卡片数
+	Accuracy          *float64 `json:"accuracy" example:"0.86"`           // 准确率，没有复习时为 null
+	AvgSecondsPerCard float64  `json:"avg_seconds_per_card" example:"20"` // 每次复习的平均耗时（秒）
+	ElapsedMinutes    float64  `json:"elapsed_minutes" example:"16.5"`    // 从开始到结束（进行中为到最后一次活动）的时长（分钟）
+}
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// 标签层级：标签名称是以 TagSeparator 分隔的路径，如 lang::go::concurrency
+const (
+	TagSeparator     = "::"
+	MaxTagNameLength = 255 // 标签完整路径的最大长度
+)
+
+// NormalizeTagName 规范化标签路径：去掉各级名称首尾的空白，任一级为空时返回错误
+func NormalizeTagName(name string) (string, error) {
+	parts := strings.Split(name, TagSeparator)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if parts[i] == "" {
+			return "", errors.New("标签名称不能为空")
+		}
+	}
+	name = strings.Join(parts, TagSeparator)
+	if utf8.RuneCountInString(name) > MaxTagNameLength {
+		return "", errors.New("标签名称过长")
+	}
+	return name, nil
+}
+
+// TagParent 上级标签的路径，顶级标签返回空字符串
+func TagParent(name string) string {
+	if i := strings.LastIndex(name, TagSeparator); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// TagLeaf 标签路径的最后一级名称
+func TagLeaf(name string) string {
+	if i := strings.LastIndex(name, TagSeparator); i >= 0 {
+		return name[i+len(TagSeparator):]
+	}
+	return name
+}
+
+// TagAncestors 标签的所有上级路径，从顶级开始
+func TagAncestors(name string) []string {
+	parts := strings.Split(name, TagSeparator)
+	ancestors := make([]string, 0, len(parts)-1)
+	for i := 1; i < len(parts); i++ {
+		ancestors = append(ancestors, strings.Join(parts[:i], TagSeparator))
+	}
+	return ancestors
+}
+
+// IsTagDescendant name 是否为 ancestor 的下级标签（不含自身）
+func IsTagDescendant(name, ancestor string) bool {
+	return strings.HasPrefix(name, ancestor+TagSeparator)
+}
+
+// TagNode 标签树的节点
+// @Description 标签树的节点，上级标签已删除时 id 为 0
+type TagNode struct {
+	ID        uint       `json:"id" example:"1"`
+	Name      string     `json:"name" example:"concurrency"`           // 本级名称
+	Path      string     `json:"path" example:"lang::go::concurrency"` // 完整路径
+	ColorCode string     `json:"color_code" example:"#4CAF50"`
+	CardCount int64      `json:"card_count" example:"12"` // 直接使用该标签的卡片数
+	Children  []*TagNode `json:"children"`
+}
+
+// MoveTagRequest 移动标签请求
+type MoveTagRequest struct {
+	ParentID uint `json:"parent_id" example:"1"` // 新的上级标签ID，为 0 时移动到顶级
+}
+
+// MergeTagRequest 合并标签请求
+type MergeTagRequest struct {
+	TargetID uint `json:"target_id" binding:"required" example:"2"` // 合并到的标签ID
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// 标签统计的排序方式
+const (
+	TagStatsSortRetention = "retention" // 按记忆保持率从低到高，最薄弱的主题排在前面
+	TagStatsSortCards     = "cards"     // 按卡片数从多到少
+	TagStatsSortDue       = "due"       // 按今日到期卡片数从多到少
+	TagStatsSortName      = "name"      // 按标签路径
+)
+
+// 成熟卡片的计划间隔（天），间隔更短的已复习卡片为新近卡片
+const MatureIntervalDays = 21
+
+// TagStats 标签的学习统计，包含其全部下级标签的卡片
+// @Description 标签的学习统计，卡片状态为当前值，复习相关的统计只计算统计窗口内的复习日志
+type TagStats struct {
+	TagID           uint     `json:"tag_id" example:"1"`
+	Name            string   `json:"name" example:"lang::go"`
+	ColorCode       string   `json:"color_code" example:"#4CAF50"`
+	DirectCardCount int64    `json:"direct_card_count" example:"8"` // 直接使用该标签的卡片数
+	CardCount       int      `json:"card_count" example:"20"`       // 该标签及其下级标签的卡片数
+	DueToday        int      `json:"due_today" example:"3"`         // 今日到期的卡片数（不含暂停的卡片）
+	NewCards        int      `json:"new_cards" example:"5"`         // 从未复习过的卡片数
+	YoungCards      int      `json:"young_cards" example:"10"`      // 计划间隔小于 21 天的卡片数
+	MatureCards     int      `json:"mature_cards" example:"5"`      // 计划间隔不少于 21 天的卡片数
+	AvgEaseFactor   float64  `json:"avg_ease_factor" example:"2.5"` // 已复习卡片的平均简易因子
+	AvgDifficulty   float64  `json:"avg_difficulty" example:"2.3"`  // 已复习卡片的平均难度系数
+	Reviews         int      `json:"reviews" example:"42"`          // 窗口内的复习次数
+	RetentionRate   *float64 `json:"retention_rate" example:"0.85"` // 窗口内学习阶段之后的复习中及格的占比（与保持率分析的口径一致），没有这类复习时为 null
+	StudySeconds    int      `json:"study_seconds" example:"1260"`  // 窗口内的学习时长（秒）
+}
+
+// TagStatsResult 标签统计结果
+type TagStatsResult struct {
+	Days  int         `json:"days" example:"30"` // 复习统计窗口的天数
+	Since time.Time   `json:"since"`             // 复习统计窗口的开始时间
+	Tags  []*TagStats `json:"tags"`
+}
@@ -6,7 +6,7 @@ import (
 
 type Tag struct {
 	gorm.Model
-	Name      string `json:"name" gorm:"uniqueIndex;size:50"`
+	Name      string `json:"name" gorm:"uniqueIndex:idx_tags_user_name,priority:2;size:255"` // 以 :: 分隔的层级路径，同一用户内唯一
 	ColorCode string `json:"color_code" gorm:"size:7;default:'#4CAF50'"`
-	UserID    uint   `json:"user_id" gorm:"index"` // 支持用户自定义标签
+	UserID    uint   `json:"user_id" gorm:"index;uniqueIndex:idx_tags_user_name,priority:1"` // 支持用户自定义标签
   }
\ No newline at end of file
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// TrashedCard 回收站中的卡片
+// @Description 已删除的卡片及其彻底删除时间
+type TrashedCard struct {
+	*LearningCard
+	PurgeAt *time.Time `json:"purge_at,omitempty"` // 到期后将被彻底删除，未开启自动清理时为空
+}
+
+// TrashedTag 回收站中的标签
+// @Description 已删除的标签及其彻底删除时间
+type TrashedTag struct {
+	*Tag
+	CardCount int64      `json:"card_count" example:"12"` // 恢复后重新关联的卡片数
+	PurgeAt   *time.Time `json:"purge_at,omitempty"`      // 到期后将被彻底删除，未开启自动清理时为空
+}
+
+// TrashPurgeResult 彻底删除的条目数
+type TrashPurgeResult struct {
+	Cards int64 `json:"cards" example:"3"`
+	Tags  int64 `json:"tags" example:"1"`
+}
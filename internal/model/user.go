@@ -20,18 +20,24 @@ type User struct {
 	LastLoginAt  *time.Time `json:"last_login_at"`                                   // 最后登录时间
 	IsPremium    bool       `json:"is_premium" gorm:"default:false"`                 // 是否是高级用户
 	Timezone     string     `json:"timezone" gorm:"size:50;default:'Asia/Shanghai'"` // 时区
+	// 早于该时间（精确到秒）签发的访问令牌一律无效，修改密码或注销全部设备时更新
+	TokensValidAfter *time.Time `json:"-"`
 }
 
 // LoginRequest 登录请求
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required"`
+	Device   string `json:"device" binding:"max=100"` // 设备名称，用于区分登录会话
 }
 
-// LoginResponse 登录响应
+// LoginResponse 登录响应，刷新令牌时返回同样的结构
 type LoginResponse struct {
-	Token    string    `json:"token"`
-	ExpireAt time.Time `json:"expire_at"`
+	Token           string    `json:"token"`             // 访问令牌
+	ExpireAt        time.Time `json:"expire_at"`         // 访问令牌的过期时间
+	RefreshToken    string    `json:"refresh_token"`     // 刷新令牌，只能使用一次
+	RefreshExpireAt time.Time `json:"refresh_expire_at"` // 刷新令牌的过期时间
+	SessionID       uint      `json:"session_id"`        // 登录会话ID
 }
 
 // RegisterRequest 注册请求
@@ -70,3 +76,21 @@ type SendCodeRequest struct {
 type SendCodeResponse struct {
 	Message string `json:"message"`
 }
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Email       string `json:"email" binding:"required,email"`
+	Code        string `json:"code" binding:"required,len=16"`               // 邮件中的重置验证码
+	NewPassword string `json:"new_password" binding:"required,min=6,max=72"` // bcrypt 最多支持72字节
+}
+
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6,max=72"`
+}
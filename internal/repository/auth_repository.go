@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AuthRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthRepository(db *gorm.DB) *AuthRepository {
+	return &AuthRepository{db: db}
+}
+
+// 创建登录会话及其第一个刷新令牌
+func (r *AuthRepository) CreateSession(session *model.AuthSession, token *model.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// 根据ID查找登录会话
+func (r *AuthRepository) FindSession(id uint) (*model.AuthSession, error) {
+	var session model.AuthSession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// 查找用户未注销且未过期的登录会话，最近使用的在前
+func (r *AuthRepository) FindActiveSessions(userID uint) ([]*model.AuthSession, error) {
+	var sessions []*model.AuthSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// 根据哈希查找刷新令牌
+func (r *AuthRepository) FindRefreshToken(tokenHash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// 轮换刷新令牌：把旧令牌标记为已使用，保存会话的新访问令牌并创建新的刷新令牌。
+// 旧令牌已经被使用过（包括并发的两次刷新）时返回 false，不做任何修改
+func (r *AuthRepository) RotateRefreshToken(oldTokenID uint, session *model.AuthSession, token *model.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", oldTokenID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Model(session).Updates(map[string]interface{}{
+			"access_jti":        session.AccessJTI,
+			"access_expires_at": session.AccessExpiresAt,
+			"last_used_at":      session.LastUsedAt,
+			"expires_at":        session.ExpiresAt,
+			"user_agent":        session.UserAgent,
+			"ip":                session.IP,
+		}).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// 注销登录会话，并删除会话的刷新令牌
+func (r *AuthRepository) RevokeSessions(sessionIDs []uint) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.AuthSession{}).
+			Where("id IN ? AND revoked_at IS NULL", sessionIDs).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Where("session_id IN ?", sessionIDs).Delete(&model.RefreshToken{}).Error
+	})
+}
+
+// 把访问令牌加入黑名单
+func (r *AuthRepository) CreateRevokedToken(jti string, expiresAt time.Time) error {
+	token := &model.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// 检查访问令牌是否在黑名单中
+func (r *AuthRepository) IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RevokedToken{}).
+		Where("jti = ? AND expires_at > ?", jti, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// 获取尚未过期的黑名单条目
+func (r *AuthRepository) FindActiveRevokedTokens() ([]*model.RevokedToken, error) {
+	var tokens []*model.RevokedToken
+	err := r.db.Where("expires_at > ?", time.Now()).Find(&tokens).Error
+	return tokens, err
+}
+
+// 删除已经过期的黑名单条目
+func (r *AuthRepository) DeleteExpiredRevokedTokens() error {
+	return r.db.Where("expires_at <= ?", time.Now()).Delete(&model.RevokedToken{}).Error
+}
+
+// 查询用户的令牌生效时间，早于该时间签发的访问令牌无效；没有设置时返回 nil
+func (r *AuthRepository) FindTokensValidAfter(userID uint) (*time.Time, error) {
+	var user model.User
+	err := r.db.Select("id", "tokens_valid_after").First(&user, userID).Error
+	if err != nil {
+		return nil, err
+	}
+	return user.TokensValidAfter, nil
+}
+
+// 更新用户的令牌生效时间，使此前签发的访问令牌全部失效
+func (r *AuthRepository) UpdateTokensValidAfter(userID uint, validAfter time.Time) error {
+	return r.db.Model(&model.User{}).Where("id = ?", userID).Update("tokens_valid_after", validAfter).Error
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// BackupRepository 备份与恢复的数据访问
+type BackupRepository struct {
+	db *gorm.DB
+}
+
+func NewBackupRepository(db *gorm.DB) *BackupRepository {
+	return &BackupRepository{db: db}
+}
+
+// 在事务中执行，回调中的仓库绑定到同一事务
+func (r *BackupRepository) Transaction(fn func(repo *BackupRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&BackupRepository{db: tx})
+	})
+}
+
+// 统计用户已有的卡片和标签数
+func (r *BackupRepository) CountUserContent(userID uint) (int64, error) {
+	var cards, tags int64
+	if err := r.db.Model(&model.LearningCard{}).Where("user_id = ?", userID).Count(&cards).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&model.Tag{}).Where("user_id = ?", userID).Count(&tags).Error; err != nil {
+		return 0, err
+	}
+	return cards + tags, nil
+}
+
+// 获取用户全部标签
+func (r *BackupRepository) FindTags(userID uint) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&tags).Error
+	return tags, err
+}
+
+// 获取用户全部卡组
+func (r *BackupRepository) FindDecks(userID uint) ([]*model.Deck, error) {
+	var decks []*model.Deck
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&decks).Error
+	return decks, err
+}
+
+// 获取用户的默认卡组
+func (r *BackupRepository) FindDefaultDeck(userID uint) (*model.Deck, error) {
+	var deck model.Deck
+	err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&deck).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// 创建卡组
+func (r *BackupRepository) CreateDeck(deck *model.Deck) error {
+	return r.db.Create(deck).Error
+}
+
+// 分批遍历用户的卡片
+func (r *BackupRepository) EachCard(userID uint, batchSize int, fn func(cards []*model.LearningCard) error) error {
+	var cards []*model.LearningCard
+	return r.db.Where("user_id = ?", userID).Order("id").
+		FindInBatches(&cards, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(cards)
+		}).Error
+}
+
+// 分批遍历用户卡片的标签关联
+func (r *BackupRepository) EachCardTag(userID uint, batchSize int, fn func(links []model.CardTag) error) error {
+	lastCardID, lastTagID := uint(0), uint(0)
+	for {
+		var links []model.CardTag
+		err := r.db.Model(&model.CardTag{}).
+			Select("card_tags.learning_card_id, card_tags.tag_id").
+			Joins("JOIN learning_cards ON learning_cards.id = card_tags.learning_card_id").
+			Where("learning_cards.user_id = ? AND learning_cards.deleted_at IS NULL", userID).
+			Where("(card_tags.learning_card_id > ? OR (card_tags.learning_card_id = ? AND card_tags.tag_id > ?))", lastCardID, lastCardID, lastTagID).
+			Order("card_tags.learning_card_id, card_tags.tag_id").
+			Limit(batchSize).
+			Scan(&links).Error
+		if err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		if err := fn(links); err != nil {
+			return err
+		}
+		last := links[len(links)-1]
+		lastCardID, lastTagID = last.LearningCardID, last.TagID
+	}
+}
+
+// 分批遍历用户的复习日志
+func (r *BackupRepository) EachReviewLog(userID uint, batchSize int, fn func(logs []*model.ReviewLog) error) error {
+	var logs []*model.ReviewLog
+	return r.db.Where("user_id = ?", userID).Order("id").
+		FindInBatches(&logs, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(logs)
+		}).Error
+}
+
+// 批量创建标签
+func (r *BackupRepository) CreateTags(tags []*model.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return r.db.Create(tags).Error
+}
+
+// 批量创建卡片（不级联创建关联）
+func (r *BackupRepository) CreateCards(cards []*model.LearningCard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags", "ReviewLogs").Create(cards).Error; err != nil {
+			return err
+		}
+		return saveCardBands(tx, cards)
+	})
+}
+
+// 批量创建卡片标签关联
+func (r *BackupRepository) CreateCardTags(links []model.CardTag) error {
+	if len(links) == 0 {
+		return nil
+	}
+	return r.db.Create(&links).Error
+}
+
+// 批量创建复习日志，并重新生成涉及日期的每日统计
+func (r *BackupRepository) CreateReviewLogs(logs []*model.ReviewLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(logs).Error; err != nil {
+			return err
+		}
+		ids := make([]uint, len(logs))
+		for i, log := range logs {
+			ids[i] = log.ID
+		}
+		days, err := reviewLogDays(tx, "id IN ?", ids)
+		if err != nil {
+			return err
+		}
+		return refreshDailyStats(tx, days)
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/pkg/dedup"
+
+	"gorm.io/gorm"
+)
+
+// 重写卡片的 LSH 分段哈希，签名无效的卡片只删除旧的分段
+func saveCardBands(tx *gorm.DB, cards []*model.LearningCard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(cards))
+	var rows []model.CardBand
+	for _, card := range cards {
+		ids = append(ids, card.ID)
+		for band, hash := range dedup.BandHashes(dedup.Decode(card.MinHash)) {
+			rows = append(rows, model.CardBand{CardID: card.ID, Band: uint8(band), UserID: card.UserID, Hash: hash})
+		}
+	}
+	if err := tx.Where("card_id IN ?", ids).Delete(&model.CardBand{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(rows, 1000).Error
+}
+
+// FindByContentHash 查找内容哈希相同的卡片（完全重复），走 content_hash 索引
+func (r *LearningCardsRepository) FindByContentHash(userID uint, contentHash string, excludeID uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	err := r.db.Select("id", "title").
+		Where("user_id = ? AND content_hash = ? AND id <> ?", userID, contentHash, excludeID).
+		Order("id").
+		Find(&cards).Error
+	return cards, err
+}
+
+// FindBandCandidates 查找与签名有任一段哈希相同的卡片（近似重复的候选），按分段哈希索引查询
+func (r *LearningCardsRepository) FindBandCandidates(userID uint, bandHashes []uint64, excludeID uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	if len(bandHashes) == 0 {
+		return cards, nil
+	}
+	pairs := make([][]interface{}, len(bandHashes))
+	for band, hash := range bandHashes {
+		pairs[band] = []interface{}{band, hash}
+	}
+	candidates := r.db.Model(&model.CardBand{}).
+		Distinct("card_id").
+		Where("user_id = ? AND (band, hash) IN ?", userID, pairs)
+	err := r.db.Select("id", "title", "content_hash", "min_hash").
+		Where("user_id = ? AND id <> ? AND id IN (?)", userID, excludeID, candidates).
+		Order("id").
+		Find(&cards).Error
+	return cards, err
+}
+
+// BackfillFingerprints 为所有卡片（包括回收站中的卡片）补算缺失的重复检测信息并重写分段哈希，
+// 每批处理 batchSize 张卡片后调用 progress
+func (r *LearningCardsRepository) BackfillFingerprints(batchSize int, progress func(done int)) error {
+	var cards []*model.LearningCard
+	done := 0
+	return r.db.Unscoped().Select("id", "user_id", "title", "content", "content_hash", "min_hash").
+		Order("id").
+		FindInBatches(&cards, batchSize, func(tx *gorm.DB, batch int) error {
+			var missing []*model.LearningCard
+			for _, card := range cards {
+				if card.ContentHash == "" || dedup.Decode(card.MinHash) == nil {
+					card.UpdateFingerprint()
+					missing = append(missing, card)
+				}
+			}
+			err := r.db.Transaction(func(tx *gorm.DB) error {
+				for _, card := range missing {
+					if err := tx.Unscoped().Model(card).UpdateColumns(map[string]interface{}{
+						"content_hash": card.ContentHash,
+						"min_hash":     card.MinHash,
+					}).Error; err != nil {
+						return err
+					}
+				}
+				return saveCardBands(tx, cards)
+			})
+			if err != nil {
+				return err
+			}
+			done += len(cards)
+			if progress != nil {
+				progress(done)
+			}
+			return nil
+		}).Error
+}
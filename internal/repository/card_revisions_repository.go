@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CardRevisionsRepository struct {
+	db *gorm.DB
+}
+
+func NewCardRevisionsRepository(db *gorm.DB) *CardRevisionsRepository {
+	return &CardRevisionsRepository{db: db}
+}
+
+// 创建修订记录
+func (r *CardRevisionsRepository) Create(revision *model.CardRevision) error {
+	return r.db.Create(revision).Error
+}
+
+// 根据ID查找修订记录
+func (r *CardRevisionsRepository) FindByID(id uint) (*model.CardRevision, error) {
+	var revision model.CardRevision
+	err := r.db.First(&revision, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// 查找卡片的全部修订记录，最新的在前
+func (r *CardRevisionsRepository) FindByCardID(cardID uint) ([]*model.CardRevision, error) {
+	var revisions []*model.CardRevision
+	err := r.db.Where("card_id = ?", cardID).Order("id DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// 查找卡片最新的修订记录
+func (r *CardRevisionsRepository) FindLatest(cardID uint) (*model.CardRevision, error) {
+	var revision model.CardRevision
+	err := r.db.Where("card_id = ?", cardID).Order("id DESC").First(&revision).Error
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// 按保留策略清理卡片的旧修订：只保留最新的 maxCount 条和 since 之后的记录，最新一条和 keep 中的修订始终保留
+func (r *CardRevisionsRepository) Prune(cardID uint, maxCount int, since time.Time, keep ...uint) error {
+	var revisions []*model.CardRevision
+	if err := r.db.Select("id", "created_at").
+		Where("card_id = ?", cardID).
+		Order("id DESC").
+		Find(&revisions).Error; err != nil {
+		return err
+	}
+
+	kept := make(map[uint]bool, len(keep))
+	for _, id := range keep {
+		kept[id] = true
+	}
+	var expired []uint
+	for i, revision := range revisions {
+		if i == 0 || kept[revision.ID] {
+			continue
+		}
+		if (maxCount > 0 && i >= maxCount) || (!since.IsZero() && revision.CreatedAt.Before(since)) {
+			expired = append(expired, revision.ID)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+	return r.db.Unscoped().Delete(&model.CardRevision{}, expired).Error
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 重建每日统计时每批读取的复习日志数
+const dailyStatsBatchSize = 1000
+
+// 汇总每日统计需要的复习日志字段
+var dailyStatsLogColumns = []string{"id", "user_id", "review_time", "performance", "duration", "new_card", "scheduled_days"}
+
+type DailyStatsRepository struct {
+	db *gorm.DB
+}
+
+func NewDailyStatsRepository(db *gorm.DB) *DailyStatsRepository {
+	return &DailyStatsRepository{db: db}
+}
+
+// 所有用户的ID，用于逐个重建每日统计
+func (r *DailyStatsRepository) UserIDs() ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.User{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Rebuild 根据复习日志重新生成用户的全部每日统计
+func (r *DailyStatsRepository) Rebuild(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.DailyStat{}).Error; err != nil {
+			return err
+		}
+		return buildDailyStats(tx, tx.Where("user_id = ?", userID), statLocation(tx, userID), nil)
+	})
+}
+
+// 已加载的时区，按名称缓存
+var statLocations sync.Map
+
+// 用户的统计时区：每日统计按用户设置的时区划分日期，与目标、预测和报告的日期一致；
+// 用户不存在或时区无效时使用服务器时区
+func statLocation(db *gorm.DB, userID uint) *time.Location {
+	var user model.User
+	if err := db.Unscoped().Select("id", "timezone").First(&user, userID).Error; err != nil || user.Timezone == "" {
+		return time.Local
+	}
+	if loc, ok := statLocations.Load(user.Timezone); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	statLocations.Store(user.Timezone, loc)
+	return loc
+}
+
+// 复习日志在用户时区中所在的日期。
+// 增量累加和重建都用它在 Go 中计算日期，不依赖 MySQL 会话的时区
+func statDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(model.DailyStatDateLayout)
+}
+
+// 统计日期当天在用户时区中的起止时间 [start, end)
+func statDayRange(date string, loc *time.Location) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation(model.DailyStatDateLayout, date, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 0, 1), nil
+}
+
+// 把一条复习日志计入统计
+func accumulateDailyStat(stat *model.DailyStat, log *model.ReviewLog) {
+	stat.Reviews++
+	stat.Duration += log.Duration
+	stat.PerformanceSum += log.Performance
+	if log.Performance >= model.PassPerformance {
+		stat.Passed++
+	}
+	switch log.Performance {
+	case 1:
+		stat.Rating1++
+	case 2:
+		stat.Rating2++
+	case 3:
+		stat.Rating3++
+	case 4:
+		stat.Rating4++
+	case 5:
+		stat.Rating5++
+	}
+	switch log.Kind() {
+	case model.ReviewKindNew:
+		stat.NewCards++
+	case model.ReviewKindLearn:
+		stat.LearnCards++
+	default:
+		stat.ReviewCards++
+	}
+}
+
+// 按 query 分批读取一个用户的复习日志，按 loc 时区的日期汇总为每日统计；dates 不为空时只汇总这些日期的日志
+func summarizeDailyStats(query *gorm.DB, loc *time.Location, dates map[string]bool) (map[userDay]*model.DailyStat, error) {
+	stats := make(map[userDay]*model.DailyStat)
+	var logs []*model.ReviewLog
+	err := query.Model(&model.ReviewLog{}).
+		Select(dailyStatsLogColumns).
+		FindInBatches(&logs, dailyStatsBatchSize, func(*gorm.DB, int) error {
+			for _, log := range logs {
+				key := userDay{UserID: log.UserID, Date: statDate(log.ReviewTime, loc)}
+				if dates != nil && !dates[key.Date] {
+					continue
+				}
+				stat, ok := stats[key]
+				if !ok {
+					stat = &model.DailyStat{UserID: key.UserID, Date: key.Date, UpdatedAt: time.Now()}
+					stats[key] = stat
+				}
+				accumulateDailyStat(stat, log)
+			}
+			return nil
+		}).Error
+	return stats, err
+}
+
+// 按 query 读取一个用户的复习日志，汇总为每日统计（loc 为用户时区）后写入；dates 不为空时只汇总这些日期的日志。
+// 调用方需要先删除将要重建的统计
+func buildDailyStats(tx *gorm.DB, query *gorm.DB, loc *time.Location, dates map[string]bool) error {
+	stats, err := summarizeDailyStats(query, loc, dates)
+	if err != nil || len(stats) == 0 {
+		return err
+	}
+
+	rows := make([]*model.DailyStat, 0, len(stats))
+	for _, stat := range stats {
+		rows = append(rows, stat)
+	}
+	return tx.CreateInBatches(rows, dailyStatsBatchSize).Error
+}
+
+// 把一条新的复习日志累加到当天的统计中
+func addToDailyStats(tx *gorm.DB, log *model.ReviewLog) error {
+	stat := &model.DailyStat{
+		UserID:    log.UserID,
+		Date:      statDate(log.ReviewTime, statLocation(tx, log.UserID)),
+		UpdatedAt: time.Now(),
+	}
+	accumulateDailyStat(stat, log)
+
+	increments := map[string]interface{}{"updated_at": stat.UpdatedAt}
+	for column, value := range map[string]int{
+		"reviews":         stat.Reviews,
+		"passed":          stat.Passed,
+		"duration":        stat.Duration,
+		"performance_sum": stat.PerformanceSum,
+		"rating1":         stat.Rating1,
+		"rating2":         stat.Rating2,
+		"rating3":         stat.Rating3,
+		"rating4":         stat.Rating4,
+		"rating5":         stat.Rating5,
+		"new_cards":       stat.NewCards,
+		"learn_cards":     stat.LearnCards,
+		"review_cards":    stat.ReviewCards,
+	} {
+		increments[column] = gorm.Expr(column+" + ?", value)
+	}
+	return tx.Clauses(clause.OnConflict{DoUpdates: clause.Assignments(increments)}).Create(stat).Error
+}
+
+// 用户某天的统计
+type userDay struct {
+	UserID uint
+	Date   string
+}
+
+// 查找符合条件的复习日志涉及的用户和日期，在删除或归档日志之前调用
+func reviewLogDays(tx *gorm.DB, query interface{}, args ...interface{}) ([]userDay, error) {
+	var logs []*model.ReviewLog
+	if err := tx.Model(&model.ReviewLog{}).
+		Select("user_id", "review_time").
+		Where(query, args...).
+		Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	locs := make(map[uint]*time.Location)
+	seen := make(map[userDay]bool)
+	var days []userDay
+	for _, log := range logs {
+		loc, ok := locs[log.UserID]
+		if !ok {
+			loc = statLocation(tx, log.UserID)
+			locs[log.UserID] = loc
+		}
+		day := userDay{UserID: log.UserID, Date: statDate(log.ReviewTime, loc)}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// 根据复习日志重新生成这些用户和日期的统计，用于日志被删除、归档或批量写入之后
+func refreshDailyStats(tx *gorm.DB, days []userDay) error {
+	dates := make(map[uint][]string)
+	for _, day := range days {
+		dates[day.UserID] = append(dates[day.UserID], day.Date)
+	}
+	for userID, list := range dates {
+		if err := tx.Where("user_id = ? AND date IN ?", userID, list).Delete(&model.DailyStat{}).Error; err != nil {
+			return err
+		}
+
+		// 按 Go 计算的每天起止时间查询日志，与 statDate 的日期划分一致
+		loc := statLocation(tx, userID)
+		var ranges *gorm.DB
+		wanted := make(map[string]bool, len(list))
+		for _, date := range list {
+			start, end, err := statDayRange(date, loc)
+			if err != nil {
+				return err
+			}
+			if ranges == nil {
+				ranges = tx.Where("review_time >= ? AND review_time < ?", start, end)
+			} else {
+				ranges = ranges.Or("review_time >= ? AND review_time < ?", start, end)
+			}
+			wanted[date] = true
+		}
+		if err := buildDailyStats(tx, tx.Where("user_id = ?", userID).Where(ranges), loc, wanted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
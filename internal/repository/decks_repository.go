@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DecksRepository struct {
+	db *gorm.DB
+}
+
+func NewDecksRepository(db *gorm.DB) *DecksRepository {
+	return &DecksRepository{db: db}
+}
+
+// 创建卡组
+func (r *DecksRepository) Create(deck *model.Deck) error {
+	return r.db.Create(deck).Error
+}
+
+// 根据ID查找卡组
+func (r *DecksRepository) FindByID(id uint) (*model.Deck, error) {
+	var deck model.Deck
+	err := r.db.First(&deck, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// 根据用户ID查找全部卡组
+func (r *DecksRepository) FindByUserID(userID uint) ([]*model.Deck, error) {
+	var decks []*model.Deck
+	err := r.db.Where("user_id = ?", userID).Order("name").Find(&decks).Error
+	return decks, err
+}
+
+// 获取卡组及其全部子卡组的ID
+func (r *DecksRepository) FindSubtreeIDs(userID, deckID uint) ([]uint, error) {
+	var decks []*model.Deck
+	if err := r.db.Select("id", "parent_id").Where("user_id = ?", userID).Find(&decks).Error; err != nil {
+		return nil, err
+	}
+	children := make(map[uint][]uint)
+	for _, deck := range decks {
+		if deck.ParentID != nil {
+			children[*deck.ParentID] = append(children[*deck.ParentID], deck.ID)
+		}
+	}
+	ids := []uint{deckID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// 查找同一父卡组下的同名卡组
+func (r *DecksRepository) FindSibling(userID uint, parentID *uint, name string) (*model.Deck, error) {
+	var deck model.Deck
+	query := r.db.Where("user_id = ? AND name = ?", userID, name)
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	err := query.First(&deck).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// 获取用户的默认卡组，不存在则创建。
+// 每个用户只能有一个默认卡组（由迁移创建的唯一索引保证），并发创建失败时读取已创建的卡组
+func (r *DecksRepository) FindOrCreateDefault(userID uint) (*model.Deck, error) {
+	deck, err := r.findDefault(userID)
+	if err == nil {
+		return deck, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	deck = &model.Deck{UserID: userID, Name: model.DefaultDeckName, IsDefault: true}
+	if err := r.db.Create(deck).Error; err != nil {
+		if existing, findErr := r.findDefault(userID); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return deck, nil
+}
+
+// 查找用户的默认卡组
+func (r *DecksRepository) findDefault(userID uint) (*model.Deck, error) {
+	var deck model.Deck
+	err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&deck).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+// 更新卡组
+func (r *DecksRepository) Update(deck *model.Deck) error {
+	return r.db.Model(deck).Updates(map[string]interface{}{
+		"name":      deck.Name,
+		"parent_id": deck.ParentID,
+		"preset_id": deck.PresetID,
+	}).Error
+}
+
+// 删除卡组：卡片移入 moveCardsTo，子卡组挂到被删卡组的父卡组下
+func (r *DecksRepository) Delete(deck *model.Deck, moveCardsTo uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.LearningCard{}).
+			Where("deck_id = ?", deck.ID).
+			Update("deck_id", moveCardsTo).Error; err != nil {
+			return err
+		}
+		// 位于筛选卡组中的卡片，原卡组随之改为 moveCardsTo
+		if err := tx.Model(&model.LearningCard{}).
+			Where("original_deck_id = ?", deck.ID).
+			Update("original_deck_id", moveCardsTo).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Deck{}).
+			Where("parent_id = ?", deck.ID).
+			Update("parent_id", deck.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(deck).Error
+	})
+}
+
+// 更新筛选卡组的筛选条件
+func (r *DecksRepository) UpdateFilter(deck *model.Deck) error {
+	return r.db.Model(deck).Updates(map[string]interface{}{
+		"name":         deck.Name,
+		"filter_query": deck.FilterQuery,
+		"filter_limit": deck.FilterLimit,
+		"reschedule":   deck.Reschedule,
+	}).Error
+}
+
+// 查找卡组中的卡片ID
+func (r *DecksRepository) FindCardIDs(deckID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.LearningCard{}).Where("deck_id = ?", deckID).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// 将卡片抽入筛选卡组，并记录其原卡组
+func (r *DecksRepository) PullCards(deckID uint, cardIDs []uint) (int64, error) {
+	if len(cardIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.Exec("UPDATE learning_cards SET original_deck_id = deck_id, deck_id = ? "+
+		"WHERE id IN ? AND original_deck_id = 0 AND deleted_at IS NULL", deckID, cardIDs)
+	return result.RowsAffected, result.Error
+}
+
+// 将筛选卡组中的卡片送回原卡组，cardIDs 为空时送回全部卡片
+func (r *DecksRepository) ReturnCards(deckID uint, cardIDs []uint) error {
+	query := "UPDATE learning_cards SET deck_id = original_deck_id, original_deck_id = 0 " +
+		"WHERE deck_id = ? AND original_deck_id <> 0"
+	args := []interface{}{deckID}
+	if len(cardIDs) > 0 {
+		query += " AND id IN ?"
+		args = append(args, cardIDs)
+	}
+	return r.db.Exec(query, args...).Error
+}
+
+// DeckCardCount 单个卡组（不含子卡组）的卡片统计
+type DeckCardCount struct {
+	DeckID   uint
+	Total    int64
+	NewCount int64
+	DueCount int64
+}
+
+// 按卡组统计用户的卡片数量
+func (r *DecksRepository) CountCardsByDeck(userID uint, now time.Time) ([]DeckCardCount, error) {
+	var counts []DeckCardCount
+	err := r.db.Model(&model.LearningCard{}).
+		Select("deck_id, COUNT(*) AS total, "+
+			"SUM(CASE WHEN review_count = 0 AND suspended = FALSE THEN 1 ELSE 0 END) AS new_count, "+
+			"SUM(CASE WHEN review_count > 0 AND suspended = FALSE AND next_review <= ? THEN 1 ELSE 0 END) AS due_count", now).
+		Where("user_id = ?", userID).
+		Group("deck_id").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// 统计卡组中今天已完成的复习数和新学卡片数
+func (r *DecksRepository) CountStudiedSince(deckIDs []uint, since time.Time) (reviews int64, newCards int64, err error) {
+	err = r.db.Model(&model.ReviewLog{}).
+		Joins("JOIN learning_cards ON learning_cards.id = review_logs.card_id").
+		Where("learning_cards.deck_id IN ? AND review_logs.review_time >= ?", deckIDs, since).
+		Count(&reviews).Error
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// 第一次复习发生在今天的卡片视为今天学习的新卡片
+	firstReviews := r.db.Model(&model.ReviewLog{}).
+		Select("card_id, MIN(review_time) AS first_review").
+		Group("card_id")
+	err = r.db.Table("(?) AS fr", firstReviews).
+		Joins("JOIN learning_cards ON learning_cards.id = fr.card_id").
+		Where("learning_cards.deck_id IN ? AND fr.first_review >= ?", deckIDs, since).
+		Count(&newCards).Error
+	return reviews, newCards, err
+}
+
+// 获取卡组中的学习队列：到期的复习卡片和新卡片
+func (r *DecksRepository) FindStudyCards(deckIDs []uint, now time.Time, reviewLimit, newLimit int) ([]*model.LearningCard, error) {
+	var due, fresh []*model.LearningCard
+	if reviewLimit > 0 {
+		if err := r.db.Where("deck_id IN ? AND review_count > 0 AND suspended = ? AND next_review <= ?", deckIDs, false, now).
+			Order("next_review").
+			Limit(reviewLimit).
+			Preload("Tags").
+			Find(&due).Error; err != nil {
+			return nil, err
+		}
+	}
+	if newLimit > 0 {
+		if err := r.db.Where("deck_id IN ? AND review_count = 0 AND suspended = ?", deckIDs, false).
+			Order("id").
+			Limit(newLimit).
+			Preload("Tags").
+			Find(&fresh).Error; err != nil {
+			return nil, err
+		}
+	}
+	return append(due, fresh...), nil
+}
+
+// 获取卡组使用的调度选项预设，未设置或预设已删除时返回默认预设
+func (r *DecksRepository) FindPresetForDeck(deckID uint) (*model.DeckPreset, error) {
+	preset := model.DefaultDeckPreset
+	deck, err := r.FindByID(deckID)
+	if err != nil {
+		return nil, err
+	}
+	if deck.PresetID == nil {
+		return &preset, nil
+	}
+	found, err := r.FindPresetByID(*deck.PresetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &preset, nil
+	}
+	return found, err
+}
+
+// 创建调度选项预设
+func (r *DecksRepository) CreatePreset(preset *model.DeckPreset) error {
+	return r.db.Create(preset).Error
+}
+
+// 根据ID查找调度选项预设
+func (r *DecksRepository) FindPresetByID(id uint) (*model.DeckPreset, error) {
+	var preset model.DeckPreset
+	err := r.db.First(&preset, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// 根据用户ID查找调度选项预设
+func (r *DecksRepository) FindPresetsByUserID(userID uint) ([]*model.DeckPreset, error) {
+	var presets []*model.DeckPreset
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&presets).Error
+	return presets, err
+}
+
+// 更新调度选项预设
+func (r *DecksRepository) UpdatePreset(preset *model.DeckPreset) error {
+	return r.db.Save(preset).Error
+}
+
+// 删除调度选项预设，使用该预设的卡组（包括已删除的卡组）改用默认预设
+func (r *DecksRepository) DeletePreset(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Deck{}).
+			Where("preset_id = ?", id).
+			Update("preset_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.DeckPreset{}, id).Error
+	})
+}
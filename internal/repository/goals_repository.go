@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalsRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalsRepository(db *gorm.DB) *GoalsRepository {
+	return &GoalsRepository{db: db}
+}
+
+// 查找用户的每日目标
+func (r *GoalsRepository) FindGoal(userID uint) (*model.StudyGoal, error) {
+	var goal model.StudyGoal
+	err := r.db.Where("user_id = ?", userID).First(&goal).Error
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// 保存每日目标，没有ID时创建
+func (r *GoalsRepository) SaveGoal(goal *model.StudyGoal) error {
+	return r.db.Save(goal).Error
+}
+
+// 查找用户已解锁的成就
+func (r *GoalsRepository) FindAchievements(userID uint) ([]*model.UserAchievement, error) {
+	var achievements []*model.UserAchievement
+	err := r.db.Where("user_id = ?", userID).Order("unlocked_at").Find(&achievements).Error
+	return achievements, err
+}
+
+// 记录解锁的成就，已解锁的忽略
+func (r *GoalsRepository) CreateAchievements(achievements []*model.UserAchievement) error {
+	if len(achievements) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&achievements).Error
+}
+
+// 统计用户的累计复习次数（不含已归档的日志）
+func (r *GoalsRepository) CountReviews(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.ReviewLog{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// 统计用户的成熟卡片数（计划间隔不少于 model.MatureIntervalDays 天）
+func (r *GoalsRepository) CountMatureCards(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.LearningCard{}).
+		Where("user_id = ? AND review_count > 0 AND TIMESTAMPDIFF(SECOND, last_review_at, next_review) >= ?",
+			userID, model.MatureIntervalDays*24*3600).
+		Count(&count).Error
+	return count, err
+}
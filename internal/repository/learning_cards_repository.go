@@ -2,8 +2,12 @@ package repository
 
 import (
 	"ReMindful/internal/model"
+	"ReMindful/pkg/cardquery"
+	"strings"
 	"time"
+	"unicode/utf8"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LearningCardsRepository struct {
@@ -14,9 +18,14 @@ func NewLearningCardsRepository(db *gorm.DB) *LearningCardsRepository {
 	return &LearningCardsRepository{db: db}
 }
 
-// Create 创建学习卡片
+// Create 创建学习卡片，只写入与已有标签的关联，不修改标签本身
 func (r *LearningCardsRepository) Create(learningCard *model.LearningCard) error {
-	return r.db.Create(learningCard).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags.*").Create(learningCard).Error; err != nil {
+			return err
+		}
+		return saveCardBands(tx, []*model.LearningCard{learningCard})
+	})
 }
 
 // FindByID 通过ID查找学习卡片
@@ -39,9 +48,17 @@ func (r *LearningCardsRepository) FindByUserID(userID uint) ([]*model.LearningCa
 	return learningCards, nil
 }
 
-// 更新学习卡片
+// 更新学习卡片（不级联更新关联，标签通过 ReplaceTags 修改）
 func (r *LearningCardsRepository) UpdateLearningCard(learningCard *model.LearningCard) error {
-	return r.db.Model(&model.LearningCard{}).Where("id = ?", learningCard.ID).Updates(learningCard).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.LearningCard{}).Where("id = ?", learningCard.ID).Omit("Tags", "ReviewLogs").Updates(learningCard).Error; err != nil {
+			return err
+		}
+		if learningCard.MinHash == nil {
+			return nil
+		}
+		return saveCardBands(tx, []*model.LearningCard{learningCard})
+	})
 }
 
 
@@ -71,12 +88,36 @@ func (r *LearningCardsRepository) FindByUserIDWithPagination(userID uint, page,
 	return learningCards, total, nil
 }
 
+// 根据卡组分页查询
+func (r *LearningCardsRepository) FindByDeckIDsWithPagination(userID uint, deckIDs []uint, page, pageSize int) ([]*model.LearningCard, int64, error) {
+	var learningCards []*model.LearningCard
+	var total int64
+
+	query := r.db.Model(&model.LearningCard{}).
+		Where("user_id = ? AND deck_id IN ?", userID, deckIDs)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Tags").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Order("next_review DESC").
+		Find(&learningCards).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return learningCards, total, nil
+}
+
 // 根据复习时间范围查询（用于间隔重复算法）
 func (r *LearningCardsRepository) FindByReviewTimeRange(userID uint, start, end time.Time) ([]*model.LearningCard, error) {
 	var cards []*model.LearningCard
 	err := r.db.Model(&model.LearningCard{}).
 	Where("user_id = ?", userID).
 	Where("next_review BETWEEN ? AND ?", start, end).
+	Where("suspended = ?", false).
 	Preload("ReviewLogs").	
 	Find(&cards).Error
 	if err != nil {
@@ -107,11 +148,15 @@ func (r *LearningCardsRepository) SoftDelete(id uint) error {
 	return r.db.Model(&model.LearningCard{}).Where("id = ?", id).Update("deleted_at", time.Now()).Error
 }
 
-// 根据标签过滤查询
+// 根据标签过滤查询（包含下级标签）
 func (r *LearningCardsRepository) FindByTag(userID uint, tagID uint) ([]*model.LearningCard, error) {
 	var cards []*model.LearningCard
-	err := r.db.Joins("JOIN card_tags ON card_tags.learning_card_id = learning_cards.id").
-	Where("learning_cards.user_id = ? AND card_tags.tag_id = ?", userID, tagID).
+	tagIDs, err := r.expandTagIDs(userID, []uint{tagID})
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Where("user_id = ?", userID).
+	Where("id IN (?)", r.db.Model(&model.CardTag{}).Select("learning_card_id").Where("tag_id IN ?", tagIDs)).
 	Preload("Tags").
 	Find(&cards).Error
 	if err != nil {
@@ -147,3 +192,708 @@ func (r *LearningCardsRepository) FindByCardType(userID uint, cardType model.Car
 	}
 	return cards, nil
 }	
+
+// 根据标题批量查询用户的卡片（用于导入去重）
+func (r *LearningCardsRepository) FindByTitles(userID uint, titles []string) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	if len(titles) == 0 {
+		return cards, nil
+	}
+	err := r.db.Where("user_id = ? AND title IN ?", userID, titles).
+		Find(&cards).Error
+	return cards, err
+}
+
+// 根据来源锚点前缀（每个文件一个）批量查询用户导入过的卡片，包括回收站中的卡片，用于重复导入时定位已有卡片
+func (r *LearningCardsRepository) FindBySourcePrefixes(userID uint, prefixes []string) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	if len(prefixes) == 0 {
+		return cards, nil
+	}
+	var match *gorm.DB
+	for _, prefix := range prefixes {
+		if match == nil {
+			match = r.db.Where("source_anchor LIKE ?", escapeLike(prefix)+"%")
+		} else {
+			match = match.Or("source_anchor LIKE ?", escapeLike(prefix)+"%")
+		}
+	}
+	err := r.db.Unscoped().Where("user_id = ?", userID).Where(match).
+		Order("id").
+		Find(&cards).Error
+	return cards, err
+}
+
+// 批量导入：在同一事务中创建新卡片并更新已有卡片
+func (r *LearningCardsRepository) ImportBatch(toCreate, toUpdate []*model.LearningCard) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(toCreate) > 0 {
+			if err := tx.Omit("Tags.*").Create(toCreate).Error; err != nil {
+				return err
+			}
+			if err := saveCardBands(tx, toCreate); err != nil {
+				return err
+			}
+		}
+		for _, card := range toUpdate {
+			if err := tx.Model(card).
+				Updates(map[string]interface{}{
+					"title":         card.Title,
+					"content":       card.Content,
+					"card_type":     card.CardType,
+					"source_anchor": card.SourceAnchor,
+					"content_hash":  card.ContentHash,
+					"min_hash":      card.MinHash,
+				}).Error; err != nil {
+				return err
+			}
+			if card.Tags != nil {
+				if err := tx.Model(card).Association("Tags").Replace(card.Tags); err != nil {
+					return err
+				}
+			}
+		}
+		return saveCardBands(tx, toUpdate)
+	})
+}
+
+// FindIDsByFilter 按筛选条件查找卡片ID，已在筛选卡组中的卡片不会被再次抽取
+func (r *LearningCardsRepository) FindIDsByFilter(userID uint, filter *model.CardFilter, now time.Time, limit int) ([]uint, error) {
+	query, err := r.applyFilter(r.db.Model(&model.LearningCard{}).
+		Where("user_id = ? AND original_deck_id = 0 AND suspended = ?", userID, false), userID, filter, now)
+	if err != nil {
+		return nil, err
+	}
+	query = orderCards(query, filter.Order)
+
+	var ids []uint
+	err = query.Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindByFilteredDeck 获取筛选卡组中的卡片
+func (r *LearningCardsRepository) FindByFilteredDeck(deckID uint, order string, limit int) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	query := r.db.Where("deck_id = ?", deckID).Preload("Tags")
+	err := orderCards(query, order).Limit(limit).Find(&cards).Error
+	return cards, err
+}
+
+// 将筛选条件转换为查询条件
+func (r *LearningCardsRepository) applyFilter(query *gorm.DB, userID uint, filter *model.CardFilter, now time.Time) (*gorm.DB, error) {
+	if filter.Query != "" {
+		node, err := cardquery.Parse(filter.Query)
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			sql, args, err := compileQuery(node, userID, now)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(sql, args...)
+		}
+	}
+	if len(filter.TagIDs) > 0 {
+		tagIDs, err := r.expandTagIDs(userID, filter.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id IN (?)", r.db.Table("card_tags").
+			Select("learning_card_id").
+			Where("tag_id IN ?", tagIDs))
+	}
+	if len(filter.DeckIDs) > 0 {
+		query = query.Where("deck_id IN ?", filter.DeckIDs)
+	}
+	if len(filter.CardTypes) > 0 {
+		query = query.Where("card_type IN ?", filter.CardTypes)
+	}
+	if filter.FailedWithinDays > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&model.ReviewLog{}).
+			Select("card_id").
+			Where("performance < 3 AND review_time >= ?", now.AddDate(0, 0, -filter.FailedWithinDays)))
+	}
+	if filter.ReviewedWithinDays > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&model.ReviewLog{}).
+			Select("card_id").
+			Where("review_time >= ?", now.AddDate(0, 0, -filter.ReviewedWithinDays)))
+	}
+	if filter.DueWithinDays != nil {
+		query = query.Where("review_count > 0 AND next_review <= ?", now.AddDate(0, 0, *filter.DueWithinDays))
+	}
+	if filter.OnlyNew {
+		query = query.Where("review_count = 0")
+	}
+	return query, nil
+}
+
+// 将标签ID扩展为包含全部下级标签的ID
+func (r *LearningCardsRepository) expandTagIDs(userID uint, ids []uint) ([]uint, error) {
+	var names []string
+	if err := r.db.Model(&model.Tag{}).Where("user_id = ? AND id IN ?", userID, ids).Pluck("name", &names).Error; err != nil {
+		return nil, err
+	}
+	cond := r.db.Where("id IN ?", ids)
+	for _, name := range names {
+		cond = cond.Or("name LIKE ?", escapeLike(name)+model.TagSeparator+"%")
+	}
+	var expanded []uint
+	err := r.db.Model(&model.Tag{}).Where("user_id = ?", userID).Where(cond).Pluck("id", &expanded).Error
+	return expanded, err
+}
+
+// 按筛选卡组的排序方式排序
+func orderCards(query *gorm.DB, order string) *gorm.DB {
+	switch order {
+	case model.FilterOrderRandom:
+		return query.Order("RAND()")
+	case model.FilterOrderAdded:
+		return query.Order("id")
+	case model.FilterOrderDifficulty:
+		return query.Order("difficulty DESC").Order("id")
+	default:
+		return query.Order("next_review").Order("id")
+	}
+}
+
+// 卡片列表支持的排序字段
+var cardSortColumns = map[string]string{
+	"due":        "next_review",
+	"created":    "created_at",
+	"updated":    "updated_at",
+	"ease":       "ease_factor",
+	"difficulty": "difficulty",
+	"reviews":    "review_count",
+	"title":      "title",
+}
+
+// ParseCardSort 解析排序参数，如 "due"、"-created"（- 表示降序），为空时按到期时间降序
+func ParseCardSort(sort string) (string, error) {
+	if sort == "" {
+		return "next_review DESC", nil
+	}
+	direction := "ASC"
+	if strings.HasPrefix(sort, "-") {
+		direction, sort = "DESC", sort[1:]
+	}
+	column, ok := cardSortColumns[sort]
+	if !ok {
+		return "", cardquery.Errorf("不支持的排序字段 %s", sort)
+	}
+	return column + " " + direction, nil
+}
+
+// Search 按筛选条件分页查询卡片，排序相同时按ID排序以保证分页稳定
+func (r *LearningCardsRepository) Search(userID uint, filter *model.CardFilter, sort string, page, pageSize int) ([]*model.LearningCard, int64, error) {
+	order, err := ParseCardSort(sort)
+	if err != nil {
+		return nil, 0, err
+	}
+	query, err := r.applyFilter(r.db.Model(&model.LearningCard{}).Where("user_id = ?", userID), userID, filter, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var learningCards []*model.LearningCard
+	if err := query.Preload("Tags").
+		Order(order).
+		Order("id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&learningCards).Error; err != nil {
+		return nil, 0, err
+	}
+	return learningCards, total, nil
+}
+
+// 将搜索语法树编译为 SQL 条件
+func compileQuery(node cardquery.Node, userID uint, now time.Time) (string, []interface{}, error) {
+	switch n := node.(type) {
+	case cardquery.And:
+		return compileGroup(n.Nodes, " AND ", userID, now)
+	case cardquery.Or:
+		return compileGroup(n.Nodes, " OR ", userID, now)
+	case cardquery.Not:
+		sql, args, err := compileQuery(n.Node, userID, now)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT (" + sql + ")", args, nil
+	case cardquery.Term:
+		return compileTerm(n, userID, now)
+	}
+	return "", nil, cardquery.Errorf("无法识别的条件")
+}
+
+func compileGroup(nodes []cardquery.Node, sep string, userID uint, now time.Time) (string, []interface{}, error) {
+	parts := make([]string, 0, len(nodes))
+	var args []interface{}
+	for _, child := range nodes {
+		sql, childArgs, err := compileQuery(child, userID, now)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "("+sql+")")
+		args = append(args, childArgs...)
+	}
+	return strings.Join(parts, sep), args, nil
+}
+
+func compileTerm(term cardquery.Term, userID uint, now time.Time) (string, []interface{}, error) {
+	switch term.Field {
+	case "":
+		pattern := "%" + escapeLike(term.Value) + "%"
+		return "title LIKE ? OR content LIKE ?", []interface{}{pattern, pattern}, nil
+	case "tag":
+		return "id IN (SELECT card_tags.learning_card_id FROM card_tags " +
+				"JOIN tags ON tags.id = card_tags.tag_id " +
+				"WHERE tags.user_id = ? AND tags.deleted_at IS NULL AND (tags.name LIKE ? OR tags.name LIKE ?))",
+			[]interface{}{userID, wildcardPattern(term.Value), wildcardPattern(term.Value) + model.TagSeparator + "%"}, nil
+	case "deck":
+		return "deck_id IN (SELECT id FROM decks WHERE user_id = ? AND deleted_at IS NULL AND name LIKE ?)",
+			[]interface{}{userID, wildcardPattern(term.Value)}, nil
+	case "type":
+		cardType := model.CardType(strings.ToLower(term.Value))
+		if !cardType.IsValid() {
+			return "", nil, cardquery.Errorf("未知的卡片类型 %s", term.Value)
+		}
+		return "card_type = ?", []interface{}{cardType}, nil
+	case "is":
+		switch term.Value {
+		case "new":
+			return "review_count = 0", nil, nil
+		case "due":
+			return "review_count > 0 AND next_review <= ?", []interface{}{now}, nil
+		case "filtered":
+			return "original_deck_id <> 0", nil, nil
+		case "suspended":
+			return "suspended = ?", []interface{}{true}, nil
+		}
+	case "ease", "difficulty", "reviews", "lapses":
+		number, _ := term.Number()
+		column := map[string]string{
+			"ease":       "ease_factor",
+			"difficulty": "difficulty",
+			"reviews":    "review_count",
+			"lapses": "(SELECT COUNT(*) FROM review_logs WHERE review_logs.card_id = learning_cards.id " +
+				"AND review_logs.performance < 3 AND review_logs.deleted_at IS NULL)",
+		}[term.Field]
+		op := term.Op
+		if op == ":" {
+			op = "="
+		}
+		return column + " " + op + " ?", []interface{}{number}, nil
+	case "due":
+		// due<3d：3天内到期（含已到期），due>3d：3天后才到期
+		d, _ := term.Duration()
+		return "review_count > 0 AND next_review " + sqlOperator(term.Op) + " ?", []interface{}{now.Add(d)}, nil
+	case "created":
+		// created:7d / created<7d：最近7天内创建，created>7d：7天前创建
+		d, _ := term.Duration()
+		return "created_at " + reverseOperator(sqlOperator(term.Op)) + " ?", []interface{}{now.Add(-d)}, nil
+	case "reviewed", "failed":
+		d, _ := term.Duration()
+		sql := "id IN (SELECT card_id FROM review_logs WHERE review_time >= ? AND deleted_at IS NULL"
+		if term.Field == "failed" {
+			sql += " AND performance < 3"
+		}
+		return sql + ")", []interface{}{now.Add(-d)}, nil
+	}
+	return "", nil, cardquery.Errorf("不支持的搜索条件 %s:%s", term.Field, term.Value)
+}
+
+// 比较符对应的 SQL 运算符，":" 对时间字段表示"不超过"
+func sqlOperator(op string) string {
+	switch op {
+	case ":":
+		return "<="
+	case "=":
+		return "="
+	}
+	return op
+}
+
+// 对时间取反方向的运算符：创建时间越早，距今越久
+func reverseOperator(op string) string {
+	switch op {
+	case "<":
+		return ">"
+	case "<=":
+		return ">="
+	case ">":
+		return "<"
+	case ">=":
+		return "<="
+	}
+	return op
+}
+
+// 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return replacer.Replace(s)
+}
+
+// 将 * 通配符转换为 LIKE 模式
+func wildcardPattern(s string) string {
+	return strings.ReplaceAll(escapeLike(s), "*", "%")
+}
+
+// 卡片标题和内容的全文索引名，由 database.CreateIndexes 创建
+const fullTextIndexName = "idx_learning_cards_fulltext"
+
+// MySQL ngram 分词器的默认 ngram_token_size
+const ngramTokenSize = 2
+
+// CardScore 全文搜索命中的卡片ID及相关度
+type CardScore struct {
+	ID    uint
+	Score float64
+}
+
+// SupportsFullText 数据库是否支持并已建立全文索引（MySQL ngram）
+func (r *LearningCardsRepository) SupportsFullText() bool {
+	return r.db.Dialector.Name() == "mysql" &&
+		r.db.Migrator().HasIndex(&model.LearningCard{}, fullTextIndexName)
+}
+
+// FullTextSearch 使用 MySQL FULLTEXT 索引搜索，要求包含全部查询词，按相关度排序
+func (r *LearningCardsRepository) FullTextSearch(userID uint, terms []string, page, pageSize int) ([]CardScore, int64, error) {
+	query := r.db.Model(&model.LearningCard{}).Where("user_id = ?", userID)
+
+	// 布尔模式下每个词都必须出现，词作为短语匹配以保持 ngram 的先后顺序。
+	// 短于 ngram 长度的词（如单个汉字）不会被切分出来，改用 LIKE 匹配
+	var phrases []string
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < ngramTokenSize {
+			pattern := "%" + escapeLike(term) + "%"
+			query = query.Where("(title LIKE ? OR content LIKE ?)", pattern, pattern)
+			continue
+		}
+		phrases = append(phrases, `+"`+term+`"`)
+	}
+	against := strings.Join(phrases, " ")
+	match := "MATCH(title, content) AGAINST (? IN BOOLEAN MODE)"
+	score := clause.Expr{SQL: "0"}
+	if len(phrases) > 0 {
+		query = query.Where(match, against)
+		score = clause.Expr{SQL: match, Vars: []interface{}{against}}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var scores []CardScore
+	err := query.Select("id, ? AS score", score).
+		Order("score DESC").
+		Order("id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&scores).Error
+	return scores, total, err
+}
+
+// SearchFingerprint 用户卡片的数量和最后修改时间，用于判断内存索引是否需要重建
+func (r *LearningCardsRepository) SearchFingerprint(userID uint) (int64, time.Time, error) {
+	var result struct {
+		Total  int64
+		Latest *time.Time
+	}
+	err := r.db.Model(&model.LearningCard{}).
+		Select("COUNT(*) AS total, MAX(updated_at) AS latest").
+		Where("user_id = ?", userID).
+		Scan(&result).Error
+	if err != nil || result.Latest == nil {
+		return result.Total, time.Time{}, err
+	}
+	return result.Total, *result.Latest, nil
+}
+
+// FindSearchDocuments 获取用户全部卡片的标题和内容，用于建立内存索引
+func (r *LearningCardsRepository) FindSearchDocuments(userID uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	err := r.db.Select("id", "title", "content").Where("user_id = ?", userID).Find(&cards).Error
+	return cards, err
+}
+
+// FindByIDs 根据ID批量查找卡片（带标签）
+func (r *LearningCardsRepository) FindByIDs(ids []uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	if len(ids) == 0 {
+		return cards, nil
+	}
+	err := r.db.Where("id IN ?", ids).Preload("Tags").Find(&cards).Error
+	return cards, err
+}
+
+// FindFingerprints 获取用户全部卡片的重复检测信息
+func (r *LearningCardsRepository) FindFingerprints(userID uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	err := r.db.Select("id", "title", "content", "content_hash", "min_hash").
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&cards).Error
+	return cards, err
+}
+
+// SaveFingerprints 保存卡片的重复检测信息，不更新修改时间
+func (r *LearningCardsRepository) SaveFingerprints(cards []*model.LearningCard) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, card := range cards {
+			if err := tx.Model(card).UpdateColumns(map[string]interface{}{
+				"content_hash": card.ContentHash,
+				"min_hash":     card.MinHash,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return saveCardBands(tx, cards)
+	})
+}
+
+// CountReviewLogsByCard 统计每张卡片的复习日志数
+func (r *LearningCardsRepository) CountReviewLogsByCard(ids []uint) (map[uint]int64, error) {
+	var rows []struct {
+		CardID uint
+		Total  int64
+	}
+	err := r.db.Model(&model.ReviewLog{}).
+		Select("card_id, COUNT(*) AS total").
+		Where("card_id IN ?", ids).
+		Group("card_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CardID] = row.Total
+	}
+	return counts, nil
+}
+
+// MergeCards 合并卡片：保留 keep 并设置合并后的标签，其余卡片的复习日志转移到 keep 后删除。
+// keep 的复习次数和上次复习时间按合并后的日志重新计算，最后一次复习发生在被删除的卡片上时沿用该卡片的排程
+func (r *LearningCardsRepository) MergeCards(keep *model.LearningCard, removeIDs []uint, tags []model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(keep).Association("Tags").Replace(tags); err != nil {
+			return err
+		}
+		if err := mergeSchedule(tx, keep, removeIDs); err != nil {
+			return err
+		}
+		if err := tx.Model(&model.ReviewLog{}).
+			Where("card_id IN ?", removeIDs).
+			Update("card_id", keep.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.LearningCard{}, removeIDs).Error
+	})
+}
+
+// 按全部卡片的复习日志重新计算 keep 的复习次数（与 SM-2 一致，答错时重置为 1）和上次复习时间，
+// 日志转移前调用，以便找出最后一次复习所在的卡片
+func mergeSchedule(tx *gorm.DB, keep *model.LearningCard, removeIDs []uint) error {
+	var logs []model.ReviewLog
+	if err := tx.Select("card_id", "review_time", "performance").
+		Where("card_id IN ?", append([]uint{keep.ID}, removeIDs...)).
+		Order("review_time, id").
+		Find(&logs).Error; err != nil {
+		return err
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	reviewCount := 0
+	for _, log := range logs {
+		if log.Performance < model.PassPerformance {
+			reviewCount = 1
+		} else {
+			reviewCount++
+		}
+	}
+	latest := logs[len(logs)-1]
+	if latest.CardID != keep.ID {
+		var source model.LearningCard
+		if err := tx.First(&source, latest.CardID).Error; err != nil {
+			return err
+		}
+		keep.NextReview = source.NextReview
+		keep.Interval = source.Interval
+		keep.EaseFactor = source.EaseFactor
+		keep.Difficulty = source.Difficulty
+		keep.LearningStep = source.LearningStep
+	}
+	keep.ReviewCount = reviewCount
+	keep.LastReviewAt = latest.ReviewTime
+
+	return tx.Model(&model.LearningCard{}).Where("id = ?", keep.ID).Updates(map[string]interface{}{
+		"review_count":   keep.ReviewCount,
+		"last_review_at": keep.LastReviewAt,
+		"next_review":    keep.NextReview,
+		"interval":       keep.Interval,
+		"ease_factor":    keep.EaseFactor,
+		"difficulty":     keep.Difficulty,
+		"learning_step":  keep.LearningStep,
+	}).Error
+}
+
+// ReplaceTags 替换卡片的标签
+func (r *LearningCardsRepository) ReplaceTags(card *model.LearningCard, tags []model.Tag) error {
+	return r.db.Model(card).Association("Tags").Replace(tags)
+}
+
+// ExistingTags 过滤出仍然存在且属于用户的标签
+func (r *LearningCardsRepository) ExistingTags(userID uint, ids []uint) ([]model.Tag, error) {
+	var tags []model.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Find(&tags).Error
+	return tags, err
+}
+
+// UpdateContent 更新卡片的标题、内容和类型（允许设置为空值）
+func (r *LearningCardsRepository) UpdateContent(card *model.LearningCard) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(card).Updates(map[string]interface{}{
+			"title":        card.Title,
+			"content":      card.Content,
+			"card_type":    card.CardType,
+			"content_hash": card.ContentHash,
+			"min_hash":     card.MinHash,
+		}).Error; err != nil {
+			return err
+		}
+		return saveCardBands(tx, []*model.LearningCard{card})
+	})
+}
+
+// Transaction 在事务中执行 fn，fn 中使用的仓库绑定到该事务
+func (r *LearningCardsRepository) Transaction(fn func(repo *LearningCardsRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&LearningCardsRepository{db: tx})
+	})
+}
+
+// Revisions 返回与卡片仓库使用同一连接的修订仓库，在 Transaction 中调用时修订的读写属于同一事务
+func (r *LearningCardsRepository) Revisions() *CardRevisionsRepository {
+	return &CardRevisionsRepository{db: r.db}
+}
+
+// FindIDsBySearch 按搜索条件查找用户的卡片ID，最多返回 limit 个
+func (r *LearningCardsRepository) FindIDsBySearch(userID uint, filter *model.CardFilter, limit int) ([]uint, error) {
+	query, err := r.applyFilter(r.db.Model(&model.LearningCard{}).Where("user_id = ?", userID), userID, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	err = query.Order("id").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindBulkTargets 获取批量操作需要校验的卡片字段
+func (r *LearningCardsRepository) FindBulkTargets(ids []uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	if len(ids) == 0 {
+		return cards, nil
+	}
+	err := r.db.Select("id", "user_id", "deck_id", "original_deck_id", "review_count").
+		Where("id IN ?", ids).
+		Find(&cards).Error
+	return cards, err
+}
+
+// AddTags 为卡片添加标签，已有的关联保持不变
+func (r *LearningCardsRepository) AddTags(cardIDs, tagIDs []uint) error {
+	if len(cardIDs) == 0 || len(tagIDs) == 0 {
+		return nil
+	}
+	if err := r.RemoveTags(cardIDs, tagIDs); err != nil {
+		return err
+	}
+	links := make([]model.CardTag, 0, len(cardIDs)*len(tagIDs))
+	for _, cardID := range cardIDs {
+		for _, tagID := range tagIDs {
+			links = append(links, model.CardTag{LearningCardID: cardID, TagID: tagID})
+		}
+	}
+	return r.db.Create(&links).Error
+}
+
+// RemoveTags 移除卡片的标签
+func (r *LearningCardsRepository) RemoveTags(cardIDs, tagIDs []uint) error {
+	if len(cardIDs) == 0 || len(tagIDs) == 0 {
+		return nil
+	}
+	return r.db.Exec("DELETE FROM card_tags WHERE learning_card_id IN ? AND tag_id IN ?", cardIDs, tagIDs).Error
+}
+
+// UpdateColumns 批量更新卡片的字段
+func (r *LearningCardsRepository) UpdateColumns(ids []uint, values map[string]interface{}) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.LearningCard{}).Where("id IN ?", ids).Updates(values).Error
+}
+
+// MoveToDeck 将卡片移动到卡组，位于筛选卡组中的卡片改为修改其原卡组
+func (r *LearningCardsRepository) MoveToDeck(ids []uint, deckID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.Model(&model.LearningCard{}).
+		Where("id IN ? AND original_deck_id = 0", ids).
+		Update("deck_id", deckID).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&model.LearningCard{}).
+		Where("id IN ? AND original_deck_id <> 0", ids).
+		Update("original_deck_id", deckID).Error
+}
+
+// SoftDeleteByIDs 批量软删除卡片
+func (r *LearningCardsRepository) SoftDeleteByIDs(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Delete(&model.LearningCard{}, ids).Error
+}
+
+// ArchiveReviewLogs 归档卡片的复习日志（软删除），日志保留但不再计入统计
+func (r *LearningCardsRepository) ArchiveReviewLogs(cardIDs []uint) error {
+	if len(cardIDs) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		days, err := reviewLogDays(tx, "card_id IN ?", cardIDs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("card_id IN ?", cardIDs).Delete(&model.ReviewLog{}).Error; err != nil {
+			return err
+		}
+		return refreshDailyStats(tx, days)
+	})
+}
+
+// FindOverdue 获取用户已到期的复习卡片，deckIDs 为空时不限卡组
+func (r *LearningCardsRepository) FindOverdue(userID uint, deckIDs []uint, now time.Time) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	query := r.db.Select("id", "last_review_at", "next_review").
+		Where("user_id = ? AND review_count > 0 AND suspended = ? AND next_review <= ?", userID, false, now)
+	if len(deckIDs) > 0 {
+		query = query.Where("deck_id IN ?", deckIDs)
+	}
+	err := query.Find(&cards).Error
+	return cards, err
+}
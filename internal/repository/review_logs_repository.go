@@ -2,6 +2,8 @@ package repository
 
 import (
 	"ReMindful/internal/model"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,9 +17,14 @@ func NewReviewLogsRepository(db *gorm.DB) *ReviewLogsRepository {
 	return &ReviewLogsRepository{db: db}
 }
 
-// 创建复习日志
+// 创建复习日志，同时累加到当天的统计
 func (r *ReviewLogsRepository) Create(log *model.ReviewLog) error {
-	return r.db.Create(log).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		return addToDailyStats(tx, log)
+	})
 }
 
 // 根据用户ID查找复习日志（支持分页和过滤）
@@ -52,6 +59,14 @@ func (r *ReviewLogsRepository) FindByUserIDWithFilters(userID uint, page, pageSi
 	return logs, total, err
 }
 
+// 查询用户在时间范围内（按用户时区的日期）的每日统计
+func (r *ReviewLogsRepository) statDays(userID uint, startTime, endTime time.Time) func(db *gorm.DB) *gorm.DB {
+	loc := statLocation(r.db, userID)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? AND date BETWEEN ? AND ?", userID, statDate(startTime, loc), statDate(endTime, loc))
+	}
+}
+
 // 统计时间范围内的复习次数
 func (r *ReviewLogsRepository) CountReviewsByTimeRange(userID uint, startTime, endTime time.Time) (int64, error) {
 	var count int64
@@ -61,18 +76,31 @@ func (r *ReviewLogsRepository) CountReviewsByTimeRange(userID uint, startTime, e
 	return count, err
 }
 
-// 获取平均性能
+// 获取时间范围内各天（用户时区）的平均评分，读取每日统计
 func (r *ReviewLogsRepository) GetAveragePerformance(userID uint, startTime, endTime time.Time) (float64, error) {
 	var result struct {
 		AvgPerformance float64
 	}
-	err := r.db.Model(&model.ReviewLog{}).
-		Select("AVG(performance) as avg_performance").
-		Where("user_id = ? AND review_time BETWEEN ? AND ?", userID, startTime, endTime).
+	err := r.db.Model(&model.DailyStat{}).
+		Select("COALESCE(SUM(performance_sum) / NULLIF(SUM(reviews), 0), 0) as avg_performance").
+		Scopes(r.statDays(userID, startTime, endTime)).
 		Scan(&result).Error
 	return result.AvgPerformance, err
 }
 
+// 获取时间范围内各天（用户时区）的复习次数和总时长（秒），读取每日统计
+func (r *ReviewLogsRepository) GetDailyTotals(userID uint, startTime, endTime time.Time) (int64, int, error) {
+	var result struct {
+		Reviews  int64
+		Duration int
+	}
+	err := r.db.Model(&model.DailyStat{}).
+		Select("COALESCE(SUM(reviews), 0) as reviews, COALESCE(SUM(duration), 0) as duration").
+		Scopes(r.statDays(userID, startTime, endTime)).
+		Scan(&result).Error
+	return result.Reviews, result.Duration, err
+}
+
 // 获取总复习时长
 func (r *ReviewLogsRepository) GetTotalDuration(userID uint, startTime, endTime time.Time) (int, error) {
 	var result struct {
@@ -85,21 +113,18 @@ func (r *ReviewLogsRepository) GetTotalDuration(userID uint, startTime, endTime
 	return result.TotalDuration, err
 }
 
-// 获取每日复习统计
-func (r *ReviewLogsRepository) GetDailyReviewStats(userID uint, startTime, endTime time.Time) ([]map[string]interface{}, error) {
-	var results []struct {
-		Date           string  `json:"date"`
-		Count          int64   `json:"count"`
-		AvgPerformance float64 `json:"avg_performance"`
-	}
-
-	err := r.db.Model(&model.ReviewLog{}).
-		Select("DATE(review_time) as date, COUNT(*) as count, AVG(performance) as avg_performance").
-		Where("user_id = ? AND review_time BETWEEN ? AND ?", userID, startTime, endTime).
-		Group("DATE(review_time)").
+// 查找时间范围内各天（用户时区）有复习的每日统计，按日期排序
+func (r *ReviewLogsRepository) FindDailyStats(userID uint, startTime, endTime time.Time) ([]*model.DailyStat, error) {
+	var stats []*model.DailyStat
+	err := r.db.Scopes(r.statDays(userID, startTime, endTime)).Where("reviews > 0").
 		Order("date").
-		Scan(&results).Error
+		Find(&stats).Error
+	return stats, err
+}
 
+// 获取每日复习统计，读取每日统计
+func (r *ReviewLogsRepository) GetDailyReviewStats(userID uint, startTime, endTime time.Time) ([]map[string]interface{}, error) {
+	results, err := r.FindDailyStats(userID, startTime, endTime)
 	if err != nil {
 		return nil, err
 	}
@@ -109,34 +134,40 @@ func (r *ReviewLogsRepository) GetDailyReviewStats(userID uint, startTime, endTi
 	for i, result := range results {
 		stats[i] = map[string]interface{}{
 			"date":            result.Date,
-			"count":           result.Count,
-			"avg_performance": result.AvgPerformance,
+			"count":           result.Reviews,
+			"avg_performance": float64(result.PerformanceSum) / float64(result.Reviews),
+			"passed":          result.Passed,
+			"duration":        result.Duration,
+			"new_cards":       result.NewCards,
+			"learn_cards":     result.LearnCards,
+			"review_cards":    result.ReviewCards,
 		}
 	}
 
 	return stats, nil
 }
 
-// 获取性能分布
+// 获取性能分布，读取每日统计
 func (r *ReviewLogsRepository) GetPerformanceDistribution(userID uint, startTime, endTime time.Time) (map[int]int64, error) {
-	var results []struct {
-		Performance int   `json:"performance"`
-		Count       int64 `json:"count"`
+	var result struct {
+		Rating1, Rating2, Rating3, Rating4, Rating5 int64
 	}
 
-	err := r.db.Model(&model.ReviewLog{}).
-		Select("performance, COUNT(*) as count").
-		Where("user_id = ? AND review_time BETWEEN ? AND ?", userID, startTime, endTime).
-		Group("performance").
-		Scan(&results).Error
+	err := r.db.Model(&model.DailyStat{}).
+		Select("COALESCE(SUM(rating1), 0) as rating1, COALESCE(SUM(rating2), 0) as rating2, COALESCE(SUM(rating3), 0) as rating3, " +
+			"COALESCE(SUM(rating4), 0) as rating4, COALESCE(SUM(rating5), 0) as rating5").
+		Scopes(r.statDays(userID, startTime, endTime)).
+		Scan(&result).Error
 
 	if err != nil {
 		return nil, err
 	}
 
 	distribution := make(map[int]int64)
-	for _, result := range results {
-		distribution[result.Performance] = result.Count
+	for performance, count := range []int64{result.Rating1, result.Rating2, result.Rating3, result.Rating4, result.Rating5} {
+		if count > 0 {
+			distribution[performance+1] = count
+		}
 	}
 
 	return distribution, nil
@@ -184,22 +215,21 @@ func (r *ReviewLogsRepository) GetDueCardsByUser(userID uint) (int64, error) {
 	var count int64
 	now := time.Now()
 	err := r.db.Model(&model.LearningCard{}).
-		Where("user_id = ? AND next_review <= ?", userID, now).
+		Where("user_id = ? AND next_review <= ? AND suspended = ?", userID, now, false).
 		Count(&count).Error
 	return count, err
 }
 
-// 获取复习热力图数据
+// 获取复习热力图数据，读取每日统计
 func (r *ReviewLogsRepository) GetReviewHeatmapData(userID uint, startDate, endDate time.Time) (map[string]int, error) {
 	var results []struct {
 		Date  string `json:"date"`
 		Count int    `json:"count"`
 	}
 
-	err := r.db.Model(&model.ReviewLog{}).
-		Select("DATE(review_time) as date, COUNT(*) as count").
-		Where("user_id = ? AND review_time BETWEEN ? AND ?", userID, startDate, endDate).
-		Group("DATE(review_time)").
+	err := r.db.Model(&model.DailyStat{}).
+		Select("date, reviews as count").
+		Scopes(r.statDays(userID, startDate, endDate)).Where("reviews > 0").
 		Scan(&results).Error
 
 	if err != nil {
@@ -225,12 +255,11 @@ func (r *ReviewLogsRepository) GetRecentReviews(userID uint, limit int) ([]*mode
 	return logs, err
 }
 
-// 获取复习连续天数
+// 获取复习连续天数，读取每日统计
 func (r *ReviewLogsRepository) GetReviewStreak(userID uint) (int, error) {
 	var dates []string
-	err := r.db.Model(&model.ReviewLog{}).
-		Select("DISTINCT DATE(review_time) as date").
-		Where("user_id = ?", userID).
+	err := r.db.Model(&model.DailyStat{}).
+		Where("user_id = ? AND reviews > 0", userID).
 		Order("date DESC").
 		Pluck("date", &dates).Error
 
@@ -242,17 +271,18 @@ func (r *ReviewLogsRepository) GetReviewStreak(userID uint) (int, error) {
 		return 0, nil
 	}
 
-	// 计算连续天数
+	// 计算连续天数，日期与每日统计一样按用户时区划分
 	streak := 0
-	today := time.Now().Format("2006-01-02")
-	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	now := time.Now().In(statLocation(r.db, userID))
+	today := now.Format("2006-01-02")
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
 
 	// 检查今天或昨天是否有复习记录
 	if len(dates) > 0 && (dates[0] == today || dates[0] == yesterday) {
 		streak = 1
 
 		for i := 1; i < len(dates); i++ {
-			expectedDate := time.Now().AddDate(0, 0, -i).Format("2006-01-02")
+			expectedDate := now.AddDate(0, 0, -i).Format("2006-01-02")
 			if dates[i] == expectedDate {
 				streak++
 			} else {
@@ -263,3 +293,111 @@ func (r *ReviewLogsRepository) GetReviewStreak(userID uint) (int, error) {
 
 	return streak, nil
 }
+
+// RetentionLog 保持率分析使用的复习日志
+type RetentionLog struct {
+	CardID        uint
+	ReviewTime    time.Time
+	Performance   int
+	ElapsedDays   float64
+	ScheduledDays float64
+	NewCard       bool
+	CardType      model.CardType
+}
+
+// 查找 since 到 until 之间的复习日志及卡片类型，按卡片和复习时间排序；until 为零值时不限结束时间
+func (r *ReviewLogsRepository) FindRetentionLogs(userID uint, since, until time.Time) ([]RetentionLog, error) {
+	var logs []RetentionLog
+	query := r.db.Model(&model.ReviewLog{}).
+		Select("review_logs.card_id, review_logs.review_time, review_logs.performance, "+
+			"review_logs.elapsed_days, review_logs.scheduled_days, review_logs.new_card, learning_cards.card_type").
+		Joins("JOIN learning_cards ON learning_cards.id = review_logs.card_id").
+		Where("review_logs.user_id = ? AND review_logs.review_time >= ?", userID, since)
+	if !until.IsZero() {
+		query = query.Where("review_logs.review_time <= ?", until)
+	}
+	err := query.Order("review_logs.card_id, review_logs.review_time").
+		Scan(&logs).Error
+	return logs, err
+}
+
+// DueCount 某天某分组到期的卡片数，Day 为 CountDueByDay 的 bounds 中的日序号，-1 表示第一天之前
+type DueCount struct {
+	Day     int
+	GroupID uint
+	Count   int64
+}
+
+// 统计最后一个分界之前到期的未暂停卡片数，按日序号和分组汇总。
+// bounds 为各天零点（由调用方按用户时区计算，夏令时切换的日子也准确），第 i 天为 [bounds[i], bounds[i+1])，
+// 早于 bounds[0] 的卡片日序号为 -1。
+// groupBy 为 model.ForecastGroupTag 时按标签分组（没有标签的分组ID为 0），为 model.ForecastGroupDeck 时按卡组分组
+func (r *ReviewLogsRepository) CountDueByDay(userID uint, bounds []time.Time, groupBy string) ([]DueCount, error) {
+	var counts []DueCount
+	if len(bounds) == 0 {
+		return counts, nil
+	}
+	query := r.db.Model(&model.LearningCard{}).
+		Where("learning_cards.user_id = ? AND learning_cards.suspended = ? AND learning_cards.next_review < ?", userID, false, bounds[len(bounds)-1])
+
+	group := "0"
+	switch groupBy {
+	case model.ForecastGroupTag:
+		query = query.Joins("LEFT JOIN card_tags ON card_tags.learning_card_id = learning_cards.id "+
+			"AND card_tags.tag_id IN (SELECT id FROM tags WHERE user_id = ? AND deleted_at IS NULL)", userID)
+		group = "COALESCE(card_tags.tag_id, 0)"
+	case model.ForecastGroupDeck:
+		group = "CASE WHEN learning_cards.original_deck_id <> 0 THEN learning_cards.original_deck_id ELSE learning_cards.deck_id END"
+	}
+
+	var day strings.Builder
+	args := make([]interface{}, 0, len(bounds))
+	day.WriteString("CASE")
+	for i, bound := range bounds[:len(bounds)-1] {
+		day.WriteString(" WHEN learning_cards.next_review < ? THEN " + strconv.Itoa(i-1))
+		args = append(args, bound)
+	}
+	day.WriteString(" ELSE " + strconv.Itoa(len(bounds)-2) + " END")
+
+	err := query.
+		Select(day.String()+" AS day, "+group+" AS group_id, COUNT(*) AS count", args...).
+		Group("day, group_id").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// InsightLog 学习时间分析使用的复习日志
+type InsightLog struct {
+	ReviewTime  time.Time
+	Duration    int
+	Performance int
+	CardType    model.CardType
+}
+
+// 查找 since 之后的复习日志及卡片类型，按复习时间排序
+func (r *ReviewLogsRepository) FindInsightLogs(userID uint, since time.Time) ([]InsightLog, error) {
+	var logs []InsightLog
+	err := r.db.Model(&model.ReviewLog{}).
+		Select("review_logs.review_time, review_logs.duration, review_logs.performance, learning_cards.card_type").
+		Joins("JOIN learning_cards ON learning_cards.id = review_logs.card_id").
+		Where("review_logs.user_id = ? AND review_logs.review_time >= ?", userID, since).
+		Order("review_logs.review_time").
+		Scan(&logs).Error
+	return logs, err
+}
+
+// 查找时间范围内答错次数最多的卡片，答错次数相同时平均评分低的在前
+func (r *ReviewLogsRepository) FindHardestCards(userID uint, startTime, endTime time.Time, limit int) ([]*model.HardCard, error) {
+	var cards []*model.HardCard
+	err := r.db.Model(&model.ReviewLog{}).
+		Select("review_logs.card_id, learning_cards.title, COUNT(*) AS reviews, "+
+			"SUM(review_logs.performance < ?) AS failures, AVG(review_logs.performance) AS avg_performance", model.PassPerformance).
+		Joins("JOIN learning_cards ON learning_cards.id = review_logs.card_id AND learning_cards.deleted_at IS NULL").
+		Where("review_logs.user_id = ? AND review_logs.review_time BETWEEN ? AND ?", userID, startTime, endTime).
+		Group("review_logs.card_id, learning_cards.title").
+		Having("failures > 0").
+		Order("failures DESC, avg_performance, review_logs.card_id").
+		Limit(limit).
+		Scan(&cards).Error
+	return cards, err
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type StudySessionsRepository struct {
+	db *gorm.DB
+}
+
+func NewStudySessionsRepository(db *gorm.DB) *StudySessionsRepository {
+	return &StudySessionsRepository{db: db}
+}
+
+// 创建学习会话
+func (r *StudySessionsRepository) Create(session *model.StudySession) error {
+	return r.db.Create(session).Error
+}
+
+// 根据ID查找学习会话
+func (r *StudySessionsRepository) FindByID(id uint) (*model.StudySession, error) {
+	var session model.StudySession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// 分页查询用户的学习会话，最新的在前
+func (r *StudySessionsRepository) FindByUserID(userID uint, page, pageSize int) ([]*model.StudySession, int64, error) {
+	var sessions []*model.StudySession
+	var total int64
+
+	query := r.db.Model(&model.StudySession{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("started_at DESC").Offset(offset).Limit(pageSize).Find(&sessions).Error
+	return sessions, total, err
+}
+
+// 更新学习会话的最后活动时间
+func (r *StudySessionsRepository) Touch(id uint, now time.Time) error {
+	return r.db.Model(&model.StudySession{}).
+		Where("id = ? AND status = ?", id, model.SessionActive).
+		Update("last_active_at", now).Error
+}
+
+// 结束学习会话
+func (r *StudySessionsRepository) Finish(session *model.StudySession) error {
+	return r.db.Model(session).Updates(map[string]interface{}{
+		"status":   session.Status,
+		"ended_at": session.EndedAt,
+	}).Error
+}
+
+// 累加会话中的一次复习，kind 为 model.ReviewKind* 之一
+func (r *StudySessionsRepository) RecordReview(id uint, kind string, passed bool, seconds int, now time.Time) error {
+	updates := map[string]interface{}{
+		"completed_cards": gorm.Expr("completed_cards + 1"),
+		"study_seconds":   gorm.Expr("study_seconds + ?", seconds),
+		"last_active_at":  now,
+	}
+	if passed {
+		updates["passed_cards"] = gorm.Expr("passed_cards + 1")
+	}
+	switch kind {
+	case model.ReviewKindNew:
+		updates["new_cards"] = gorm.Expr("new_cards + 1")
+	case model.ReviewKindLearn:
+		updates["learn_cards"] = gorm.Expr("learn_cards + 1")
+	default:
+		updates["review_cards"] = gorm.Expr("review_cards + 1")
+	}
+	return r.db.Model(&model.StudySession{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// 将用户在 idleBefore 之后没有活动的进行中会话标记为已放弃，结束时间为最后一次活动的时间
+func (r *StudySessionsRepository) AbandonIdle(userID uint, idleBefore time.Time) error {
+	return r.db.Model(&model.StudySession{}).
+		Where("user_id = ? AND status = ? AND last_active_at < ?", userID, model.SessionActive, idleBefore).
+		Updates(map[string]interface{}{
+			"status":   model.SessionAbandoned,
+			"ended_at": gorm.Expr("last_active_at"),
+		}).Error
+}
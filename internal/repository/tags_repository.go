@@ -2,10 +2,16 @@ package repository
 
 import (
 	"ReMindful/internal/model"
+	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// 自动创建的上级标签使用的颜色
+const defaultTagColor = "#4CAF50"
+
 type TagsRepository struct {
 	db *gorm.DB
 }
@@ -14,9 +20,38 @@ func NewTagsRepository(db *gorm.DB) *TagsRepository {
 	return &TagsRepository{db: db}
 }
 
-// 创建标签
+// 创建标签，不存在的上级标签一并创建
 func (r *TagsRepository) Create(tag *model.Tag) error {
-	return r.db.Create(tag).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createAncestors(tx, tag.UserID, tag.Name); err != nil {
+			return err
+		}
+		return tx.Create(tag).Error
+	})
+}
+
+// 创建标签路径中不存在的上级标签，已在回收站中的上级标签保持不变
+func createAncestors(tx *gorm.DB, userID uint, name string) error {
+	for _, ancestor := range model.TagAncestors(name) {
+		var count int64
+		if err := tx.Unscoped().Model(&model.Tag{}).
+			Where("name = ? AND user_id = ?", ancestor, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := tx.Create(&model.Tag{Name: ancestor, UserID: userID, ColorCode: defaultTagColor}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 下级标签的 LIKE 模式
+func descendantPattern(name string) string {
+	return escapeLike(name) + model.TagSeparator + "%"
 }
 
 // 根据ID查找标签
@@ -51,9 +86,13 @@ func (r *TagsRepository) Update(tag *model.Tag) error {
 	return r.db.Save(tag).Error
 }
 
-// 删除标签
-func (r *TagsRepository) Delete(id uint) error {
-	return r.db.Delete(&model.Tag{}, id).Error
+// 删除标签（移入回收站），卡片关联保留以便恢复，彻底删除时再清理。
+// 同一次删除的标签删除时间相同，恢复时据此找回一起删除的下级标签
+func (r *TagsRepository) Delete(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Delete(&model.Tag{}, ids).Error
 }
 
 // 根据名称搜索标签
@@ -73,7 +112,8 @@ func (r *TagsRepository) GetTagUsageStats(userID uint) (map[uint]int64, error) {
 	err := r.db.Table("card_tags").
 		Select("tag_id, COUNT(*) as count").
 		Joins("JOIN tags ON tags.id = card_tags.tag_id").
-		Where("tags.user_id = ?", userID).
+		Joins("JOIN learning_cards ON learning_cards.id = card_tags.learning_card_id").
+		Where("tags.user_id = ? AND tags.deleted_at IS NULL AND learning_cards.deleted_at IS NULL", userID).
 		Group("tag_id").
 		Scan(&results).Error
 
@@ -88,3 +128,178 @@ func (r *TagsRepository) GetTagUsageStats(userID uint) (map[uint]int64, error) {
 
 	return stats, nil
 }
+
+// 根据名称查找标签（回收站中的同名标签会被恢复），不存在则连同上级标签一起创建
+func (r *TagsRepository) FirstOrCreateByName(userID uint, name string) (*model.Tag, error) {
+	tag := model.Tag{Name: name, UserID: userID}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := createAncestors(tx, userID, name); err != nil {
+			return err
+		}
+		err := tx.Unscoped().Where("name = ? AND user_id = ?", name, userID).
+			Attrs(model.Tag{ColorCode: defaultTagColor}).
+			FirstOrCreate(&tag).Error
+		if err != nil || !tag.DeletedAt.Valid {
+			return err
+		}
+		// 同名标签在回收站中时直接恢复，避免违反唯一约束
+		tag.DeletedAt = gorm.DeletedAt{}
+		return tx.Unscoped().Model(&tag).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// 查找标签及其全部下级标签（含回收站中的），按路径排序
+func (r *TagsRepository) FindSubtree(userID uint, name string) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	err := r.db.Unscoped().
+		Where("user_id = ? AND (name = ? OR name LIKE ?)", userID, name, descendantPattern(name)).
+		Order("name").
+		Find(&tags).Error
+	return tags, err
+}
+
+// 按名称查找用户的标签（含回收站中的）
+func (r *TagsRepository) FindByNames(userID uint, names []string) ([]*model.Tag, error) {
+	var tags []*model.Tag
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.db.Unscoped().Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error
+	return tags, err
+}
+
+// 查找使用这些标签的卡片ID
+func (r *TagsRepository) FindCardIDsByTags(tagIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(tagIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Table("card_tags").Distinct("learning_card_id").Where("tag_id IN ?", tagIDs).Pluck("learning_card_id", &ids).Error
+	return ids, err
+}
+
+// 重命名标签（可同时修改上级路径），下级标签的路径随之修改
+func (r *TagsRepository) Rename(tag *model.Tag, oldName string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children []*model.Tag
+		if err := tx.Unscoped().
+			Where("user_id = ? AND name LIKE ?", tag.UserID, descendantPattern(oldName)).
+			Find(&children).Error; err != nil {
+			return err
+		}
+		if err := createAncestors(tx, tag.UserID, tag.Name); err != nil {
+			return err
+		}
+		if err := tx.Save(tag).Error; err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err := tx.Unscoped().Model(child).
+				Update("name", tag.Name+strings.TrimPrefix(child.Name, oldName)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 合并标签：source 及其下级标签并入 target 下对应路径的标签，
+// 对应标签已存在时转移卡片关联后删除原标签，不存在时直接移动到 target 下
+func (r *TagsRepository) Merge(source, target *model.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var subtree []*model.Tag
+		if err := tx.Unscoped().
+			Where("user_id = ? AND (name = ? OR name LIKE ?)", source.UserID, source.Name, descendantPattern(source.Name)).
+			Order("name").
+			Find(&subtree).Error; err != nil {
+			return err
+		}
+
+		for _, tag := range subtree {
+			name := target.Name + strings.TrimPrefix(tag.Name, source.Name)
+			var existing model.Tag
+			err := tx.Unscoped().Where("user_id = ? AND name = ?", tag.UserID, name).First(&existing).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if err := tx.Unscoped().Model(tag).Update("name", name).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			// 合并到回收站中的标签时将其恢复
+			if existing.DeletedAt.Valid {
+				if err := tx.Unscoped().Model(&existing).Update("deleted_at", nil).Error; err != nil {
+					return err
+				}
+			}
+			if err := mergeCardTags(tx, tag.ID, existing.ID); err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&model.Tag{}, tag.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// 将标签 from 的卡片关联转移到 to，已同时使用两个标签的卡片只保留一条关联
+func mergeCardTags(tx *gorm.DB, from, to uint) error {
+	var cardIDs []uint
+	if err := tx.Table("card_tags").Where("tag_id = ?", to).Pluck("learning_card_id", &cardIDs).Error; err != nil {
+		return err
+	}
+	if len(cardIDs) > 0 {
+		if err := tx.Exec("DELETE FROM card_tags WHERE tag_id = ? AND learning_card_id IN ?", from, cardIDs).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Exec("UPDATE card_tags SET tag_id = ? WHERE tag_id = ?", to, from).Error
+}
+
+// CardReviewSummary 卡片在一段时间内的复习汇总
+type CardReviewSummary struct {
+	CardID   uint
+	Reviews  int // 复习次数
+	Duration int // 复习总耗时（秒）
+}
+
+// 查找用户未删除的卡片与未删除标签之间的关联
+func (r *TagsRepository) FindCardTagLinks(userID uint) ([]model.CardTag, error) {
+	var links []model.CardTag
+	err := r.db.Model(&model.CardTag{}).
+		Select("card_tags.learning_card_id, card_tags.tag_id").
+		Joins("JOIN tags ON tags.id = card_tags.tag_id").
+		Joins("JOIN learning_cards ON learning_cards.id = card_tags.learning_card_id").
+		Where("tags.user_id = ? AND tags.deleted_at IS NULL AND learning_cards.deleted_at IS NULL", userID).
+		Scan(&links).Error
+	return links, err
+}
+
+// 查找用户带有标签的卡片的调度状态
+func (r *TagsRepository) FindTaggedCards(userID uint) ([]*model.LearningCard, error) {
+	var cards []*model.LearningCard
+	err := r.db.Select("id", "next_review", "last_review_at", "review_count", "ease_factor", "difficulty", "suspended").
+		Where("user_id = ?", userID).
+		Where("EXISTS (SELECT 1 FROM card_tags WHERE card_tags.learning_card_id = learning_cards.id)").
+		Find(&cards).Error
+	return cards, err
+}
+
+// 按卡片汇总 since 之后的复习日志
+func (r *TagsRepository) SumReviewsByCard(userID uint, since time.Time) ([]CardReviewSummary, error) {
+	var summaries []CardReviewSummary
+	err := r.db.Model(&model.ReviewLog{}).
+		Select("card_id, COUNT(*) AS reviews, COALESCE(SUM(duration), 0) AS duration").
+		Where("user_id = ? AND review_time >= ?", userID, since).
+		Group("card_id").
+		Scan(&summaries).Error
+	return summaries, err
+}
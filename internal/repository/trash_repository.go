@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"ReMindful/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TrashRepository 回收站：查询、恢复和彻底删除已软删除的卡片和标签
+type TrashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) *TrashRepository {
+	return &TrashRepository{db: db}
+}
+
+// 分页查询用户回收站中的卡片，最近删除的在前
+func (r *TrashRepository) FindCards(userID uint, page, pageSize int) ([]*model.LearningCard, int64, error) {
+	var cards []*model.LearningCard
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.LearningCard{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Preload("Tags").
+		Order("deleted_at DESC").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&cards).Error
+	return cards, total, err
+}
+
+// 分页查询用户回收站中的标签，最近删除的在前
+func (r *TrashRepository) FindTags(userID uint, page, pageSize int) ([]*model.Tag, int64, error) {
+	var tags []*model.Tag
+	var total int64
+
+	query := r.db.Unscoped().Model(&model.Tag{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := query.Order("deleted_at DESC").Order("id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&tags).Error
+	return tags, total, err
+}
+
+// 统计标签关联的未删除卡片数
+func (r *TrashRepository) CountTagCards(tagIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		TagID uint
+		Count int64
+	}
+	err := r.db.Table("card_tags").
+		Select("card_tags.tag_id, COUNT(*) AS count").
+		Joins("JOIN learning_cards ON learning_cards.id = card_tags.learning_card_id").
+		Where("card_tags.tag_id IN ? AND learning_cards.deleted_at IS NULL", tagIDs).
+		Group("card_tags.tag_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.TagID] = row.Count
+	}
+	return counts, nil
+}
+
+// 查找回收站中的卡片
+func (r *TrashRepository) FindCardByID(id uint) (*model.LearningCard, error) {
+	var card model.LearningCard
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&card, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// 查找回收站中的标签
+func (r *TrashRepository) FindTagByID(id uint) (*model.Tag, error) {
+	var tag model.Tag
+	err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&tag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// 恢复卡片并放回指定卡组
+func (r *TrashRepository) RestoreCard(id, deckID uint) error {
+	return r.db.Unscoped().Model(&model.LearningCard{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"deleted_at":       nil,
+			"deck_id":          deckID,
+			"original_deck_id": 0,
+		}).Error
+}
+
+// 恢复标签，原有的卡片关联随之恢复
+func (r *TrashRepository) RestoreTags(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Unscoped().Model(&model.Tag{}).
+		Where("id IN ?", ids).
+		Update("deleted_at", nil).Error
+}
+
+// 查找删除时间早于 before 的卡片ID，userID 为 0 时不限用户
+func (r *TrashRepository) FindCardIDs(userID uint, before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.trashedBefore(&model.LearningCard{}, userID, before).Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// 查找删除时间早于 before 的标签ID，userID 为 0 时不限用户
+func (r *TrashRepository) FindTagIDs(userID uint, before time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.trashedBefore(&model.Tag{}, userID, before).Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *TrashRepository) trashedBefore(value interface{}, userID uint, before time.Time) *gorm.DB {
+	query := r.db.Unscoped().Model(value).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	return query.Order("id")
+}
+
+// 彻底删除回收站中的卡片及其标签关联、复习日志和修订历史
+func (r *TrashRepository) PurgeCards(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 只删除仍在回收站中的卡片，避免误删刚被恢复的卡片
+		var trashed []uint
+		if err := tx.Unscoped().Model(&model.LearningCard{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Pluck("id", &trashed).Error; err != nil {
+			return err
+		}
+		if len(trashed) == 0 {
+			return nil
+		}
+		if err := tx.Exec("DELETE FROM card_tags WHERE learning_card_id IN ?", trashed).Error; err != nil {
+			return err
+		}
+		days, err := reviewLogDays(tx, "card_id IN ?", trashed)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("card_id IN ?", trashed).Delete(&model.ReviewLog{}).Error; err != nil {
+			return err
+		}
+		if err := refreshDailyStats(tx, days); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("card_id IN ?", trashed).Delete(&model.CardRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("card_id IN ?", trashed).Delete(&model.CardBand{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", trashed).Delete(&model.LearningCard{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+// 彻底删除回收站中的标签及其卡片关联
+func (r *TrashRepository) PurgeTags(ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	var purged int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var trashed []uint
+		if err := tx.Unscoped().Model(&model.Tag{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Pluck("id", &trashed).Error; err != nil {
+			return err
+		}
+		if len(trashed) == 0 {
+			return nil
+		}
+		if err := tx.Exec("DELETE FROM card_tags WHERE tag_id IN ?", trashed).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("id IN ?", trashed).Delete(&model.Tag{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
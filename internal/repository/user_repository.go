@@ -54,9 +54,17 @@ func (r *UserRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
-// UpdateFields 更新指定字段
+// UpdateFields 更新指定字段；修改时区时在同一事务中按新时区重建每日统计
 func (r *UserRepository) UpdateFields(id uint, fields map[string]interface{}) error {
-	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+	if _, ok := fields["timezone"]; !ok {
+		return r.db.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", id).Updates(fields).Error; err != nil {
+			return err
+		}
+		return NewDailyStatsRepository(tx).Rebuild(id)
+	})
 }
 
 // CheckEmailExists 检查邮箱是否已存在
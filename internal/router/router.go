@@ -1,8 +1,11 @@
 package router
 
 import (
+	"context"
+	"log"
 	"os"
 
+	"ReMindful/internal/config"
 	"ReMindful/internal/handler"
 	"ReMindful/internal/middleware"
 	"ReMindful/internal/repository"
@@ -18,7 +21,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func InitRouter(r *gin.Engine, db *gorm.DB, rdb *redis.Client, emailSender *email.EmailSender) {
+func InitRouter(r *gin.Engine, db *gorm.DB, rdb *redis.Client, emailSender *email.EmailSender, cfg *config.Config) {
 	// 全局中间件
 	r.Use(middleware.Cors())
 	r.Use(middleware.Logger())
@@ -30,19 +33,66 @@ func InitRouter(r *gin.Engine, db *gorm.DB, rdb *redis.Client, emailSender *emai
 	learningCardsRepo := repository.NewLearningCardsRepository(db)
 	tagsRepo := repository.NewTagsRepository(db)
 	reviewLogsRepo := repository.NewReviewLogsRepository(db)
+	backupRepo := repository.NewBackupRepository(db)
+	decksRepo := repository.NewDecksRepository(db)
+	revisionsRepo := repository.NewCardRevisionsRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	studySessionsRepo := repository.NewStudySessionsRepository(db)
+	goalsRepo := repository.NewGoalsRepository(db)
+	authRepo := repository.NewAuthRepository(db)
 
 	// 初始化服务
+	authService := service.NewAuthService(authRepo, rdb, jwtSecret, cfg.JWT)
 	userService := service.NewUserService(userRepo, rdb, emailSender)
+	userService.SetAuthService(authService)
 	learningCardsService := service.NewLearningCardsService(learningCardsRepo, rdb)
 	learningCardsService.SetReviewLogsRepository(reviewLogsRepo)
-	tagsService := service.NewTagsService(tagsRepo)
+	learningCardsService.SetDecksRepository(decksRepo)
+	learningCardsService.SetTagsRepository(tagsRepo)
+	learningCardsService.SetStudySessionsRepository(studySessionsRepo)
+	learningCardsService.SetUserRepository(userRepo)
+	tagsService := service.NewTagsService(tagsRepo, rdb)
+	tagsService.SetReviewLogsRepository(reviewLogsRepo)
+	tagsService.SetUserRepository(userRepo)
 	reviewLogsService := service.NewReviewLogsService(reviewLogsRepo)
+	reviewLogsService.SetTagsRepository(tagsRepo)
+	reviewLogsService.SetDecksRepository(decksRepo)
+	reviewLogsService.SetUserRepository(userRepo)
+	importService := service.NewImportService(learningCardsRepo, tagsRepo, rdb)
+	importService.SetDecksRepository(decksRepo)
+	backupService := service.NewBackupService(backupRepo, userRepo)
+	decksService := service.NewDecksService(decksRepo, learningCardsRepo, rdb)
+	searchService := service.NewSearchService(learningCardsRepo)
+	revisionsService := service.NewCardRevisionsService(revisionsRepo, learningCardsRepo, userRepo, rdb, cfg.Revision)
+	learningCardsService.SetRevisionsService(revisionsService)
+	importService.SetRevisionsService(revisionsService)
+	trashService := service.NewTrashService(trashRepo, decksRepo, tagsRepo, rdb, cfg.Trash)
+	studySessionsService := service.NewStudySessionsService(studySessionsRepo, decksService)
+	goalsService := service.NewGoalsService(goalsRepo, reviewLogsRepo, userRepo)
+	learningCardsService.SetGoalsService(goalsService)
+
+	// Redis中的令牌黑名单以数据库为准重新加载
+	if err := authService.LoadRevokedTokens(); err != nil {
+		log.Printf("加载令牌黑名单失败: %v", err)
+	}
+
+	// 定期彻底删除回收站中的过期条目
+	trashService.StartPurgeJob(context.Background())
 
 	// 初始化处理器
 	userHandler := handler.NewUserHandler(userService)
+	authHandler := handler.NewAuthHandler(authService)
 	learningCardsHandler := handler.NewLearningCardsHandler(learningCardsService)
 	tagsHandler := handler.NewTagsHandler(tagsService)
 	reviewLogsHandler := handler.NewReviewLogsHandler(reviewLogsService)
+	importHandler := handler.NewImportHandler(importService)
+	backupHandler := handler.NewBackupHandler(backupService)
+	decksHandler := handler.NewDecksHandler(decksService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	revisionsHandler := handler.NewCardRevisionsHandler(revisionsService, learningCardsService)
+	trashHandler := handler.NewTrashHandler(trashService)
+	studySessionsHandler := handler.NewStudySessionsHandler(studySessionsService)
+	goalsHandler := handler.NewGoalsHandler(goalsService)
 
 	// Swagger API文档
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -54,37 +104,111 @@ func InitRouter(r *gin.Engine, db *gorm.DB, rdb *redis.Client, emailSender *emai
 		api.POST("/send-code", userHandler.SendCode)
 		api.POST("/register", userHandler.Register)
 		api.POST("/login", userHandler.Login)
+		api.POST("/refresh", authHandler.Refresh)
+		api.POST("/forgot-password", userHandler.ForgotPassword)
+		api.POST("/reset-password", userHandler.ResetPassword)
 
 		// 需要认证的路由
 		auth := api.Group("/")
-		auth.Use(middleware.JWTAuth(jwtSecret))
+		auth.Use(middleware.JWTAuth(jwtSecret, authService.IsRevoked))
 		{
 			// 用户信息管理
 			auth.GET("/user", userHandler.GetUserInfo)
 			auth.PUT("/user", userHandler.UpdateUser)
+			auth.PUT("/user/password", userHandler.ChangePassword)
+
+			// 登录会话管理
+			auth.POST("/logout", authHandler.Logout)                     // 注销当前会话
+			auth.POST("/logout-all", authHandler.LogoutAll)              // 注销全部设备
+			auth.GET("/user/sessions", authHandler.GetSessions)          // 获取各设备的登录会话
+			auth.DELETE("/user/sessions/:id", authHandler.RevokeSession) // 注销指定会话
 
 			// 学习卡片相关路由
 			cards := auth.Group("/learning-cards")
 			{
-				cards.POST("", learningCardsHandler.CreateLearningCard)       // 创建卡片
-				cards.GET("", learningCardsHandler.GetLearningCards)          // 获取卡片列表
-				cards.GET("/review", learningCardsHandler.GetCardsToReview)   // 获取需要复习的卡片
-				cards.GET("/:id", learningCardsHandler.GetLearningCardByID)   // 获取单个卡片
-				cards.PUT("/:id", learningCardsHandler.UpdateLearningCard)    // 更新卡片
-				cards.DELETE("/:id", learningCardsHandler.DeleteLearningCard) // 删除卡片
-				cards.POST("/:id/review", learningCardsHandler.ReviewCard)    // 复习卡片
+				cards.POST("", learningCardsHandler.CreateLearningCard)                             // 创建卡片
+				cards.GET("", learningCardsHandler.GetLearningCards)                                // 获取卡片列表
+				cards.GET("/review", learningCardsHandler.GetCardsToReview)                         // 获取需要复习的卡片
+				cards.GET("/search", searchHandler.SearchCards)                                     // 全文搜索卡片
+				cards.GET("/duplicates", learningCardsHandler.GetDuplicates)                        // 查找重复卡片
+				cards.POST("/merge", learningCardsHandler.MergeCards)                               // 合并重复卡片
+				cards.POST("/bulk", learningCardsHandler.BulkCards)                                 // 批量操作卡片
+				cards.POST("/postpone-backlog", learningCardsHandler.PostponeBacklog)               // 分散积压的到期卡片
+				cards.POST("/import/csv", importHandler.ImportCSV)                                  // 从CSV/TSV导入卡片
+				cards.POST("/import/markdown", importHandler.ImportMarkdown)                        // 从Markdown笔记库导入卡片
+				cards.GET("/:id", learningCardsHandler.GetLearningCardByID)                         // 获取单个卡片
+				cards.PUT("/:id", learningCardsHandler.UpdateLearningCard)                          // 更新卡片
+				cards.DELETE("/:id", learningCardsHandler.DeleteLearningCard)                       // 删除卡片
+				cards.POST("/:id/review", learningCardsHandler.ReviewCard)                          // 复习卡片
+				cards.POST("/:id/forget", learningCardsHandler.ForgetCard)                          // 重置为新卡片
+				cards.POST("/:id/reschedule", learningCardsHandler.RescheduleCard)                  // 安排复习时间
+				cards.GET("/:id/stats", learningCardsHandler.GetCardStats)                          // 获取卡片统计和复习时间线
+				cards.GET("/:id/revisions", revisionsHandler.GetRevisions)                          // 获取卡片修订历史
+				cards.GET("/:id/revisions/diff", revisionsHandler.DiffRevisions)                    // 比较两个修订
+				cards.POST("/:id/revisions/:revision_id/restore", revisionsHandler.RestoreRevision) // 恢复修订
+			}
+
+			// 卡组管理路由
+			decks := auth.Group("/decks")
+			{
+				decks.POST("", decksHandler.CreateDeck)                      // 创建卡组
+				decks.GET("", decksHandler.GetDecks)                         // 获取卡组树
+				decks.POST("/filtered", decksHandler.CreateFilteredDeck)     // 创建筛选卡组
+				decks.GET("/presets", decksHandler.GetPresets)               // 获取调度选项预设列表
+				decks.POST("/presets", decksHandler.CreatePreset)            // 创建调度选项预设
+				decks.PUT("/presets/:id", decksHandler.UpdatePreset)         // 更新调度选项预设
+				decks.DELETE("/presets/:id", decksHandler.DeletePreset)      // 删除调度选项预设
+				decks.GET("/:id", decksHandler.GetDeckByID)                  // 获取单个卡组
+				decks.PUT("/:id", decksHandler.UpdateDeck)                   // 更新卡组
+				decks.DELETE("/:id", decksHandler.DeleteDeck)                // 删除卡组
+				decks.POST("/:id/move", decksHandler.MoveDeck)               // 移动卡组
+				decks.GET("/:id/study", decksHandler.GetStudyQueue)          // 获取卡组学习队列
+				decks.PUT("/:id/filter", decksHandler.UpdateFilteredDeck)    // 更新筛选卡组
+				decks.POST("/:id/rebuild", decksHandler.RebuildFilteredDeck) // 重建筛选卡组
+				decks.POST("/:id/empty", decksHandler.EmptyFilteredDeck)     // 清空筛选卡组
 			}
 
 			// 标签管理路由
 			tags := auth.Group("/tags")
 			{
-				tags.POST("", tagsHandler.CreateTag)       // 创建标签
-				tags.GET("", tagsHandler.GetTags)          // 获取标签列表
-				tags.GET("/:id", tagsHandler.GetTagByID)   // 获取单个标签
-				tags.PUT("/:id", tagsHandler.UpdateTag)    // 更新标签
-				tags.DELETE("/:id", tagsHandler.DeleteTag) // 删除标签
+				tags.POST("", tagsHandler.CreateTag)          // 创建标签
+				tags.GET("", tagsHandler.GetTags)             // 获取标签列表
+				tags.GET("/tree", tagsHandler.GetTagTree)     // 获取标签树
+				tags.GET("/stats", tagsHandler.GetTagStats)   // 获取标签统计
+				tags.GET("/:id", tagsHandler.GetTagByID)      // 获取单个标签
+				tags.PUT("/:id", tagsHandler.UpdateTag)       // 更新标签
+				tags.DELETE("/:id", tagsHandler.DeleteTag)    // 删除标签
+				tags.POST("/:id/move", tagsHandler.MoveTag)   // 移动标签
+				tags.POST("/:id/merge", tagsHandler.MergeTag) // 合并标签
 			}
 
+			// 回收站路由
+			trash := auth.Group("/trash")
+			{
+				trash.DELETE("", trashHandler.EmptyTrash)                  // 清空回收站
+				trash.GET("/cards", trashHandler.GetTrashedCards)          // 获取回收站中的卡片
+				trash.POST("/cards/:id/restore", trashHandler.RestoreCard) // 恢复卡片
+				trash.DELETE("/cards/:id", trashHandler.DeleteCard)        // 彻底删除卡片
+				trash.GET("/tags", trashHandler.GetTrashedTags)            // 获取回收站中的标签
+				trash.POST("/tags/:id/restore", trashHandler.RestoreTag)   // 恢复标签
+				trash.DELETE("/tags/:id", trashHandler.DeleteTag)          // 彻底删除标签
+			}
+
+			// 学习会话路由
+			studySessions := auth.Group("/study-sessions")
+			{
+				studySessions.POST("", studySessionsHandler.StartSession)             // 开始学习会话
+				studySessions.GET("", studySessionsHandler.GetSessions)               // 获取学习会话列表
+				studySessions.GET("/:id", studySessionsHandler.GetSession)            // 获取学习会话汇总
+				studySessions.POST("/:id/heartbeat", studySessionsHandler.Heartbeat)  // 学习会话心跳
+				studySessions.POST("/:id/finish", studySessionsHandler.FinishSession) // 结束学习会话
+			}
+
+			// 每日目标和成就路由
+			auth.GET("/goals", goalsHandler.GetGoal)                // 获取每日目标、进度和连续天数
+			auth.PUT("/goals", goalsHandler.UpdateGoal)             // 设置每日目标
+			auth.GET("/achievements", goalsHandler.GetAchievements) // 获取成就列表
+
 			// 复习日志路由
 			reviewLogs := auth.Group("/review-logs")
 			{
@@ -92,6 +216,18 @@ func InitRouter(r *gin.Engine, db *gorm.DB, rdb *redis.Client, emailSender *emai
 				reviewLogs.GET("/stats", reviewLogsHandler.GetReviewStats)         // 获取复习统计
 				reviewLogs.GET("/progress", reviewLogsHandler.GetLearningProgress) // 获取学习进度
 				reviewLogs.GET("/heatmap", reviewLogsHandler.GetReviewHeatmap)     // 获取复习热力图
+				reviewLogs.GET("/retention", reviewLogsHandler.GetRetentionStats)  // 获取记忆保持率分析
+				reviewLogs.GET("/forecast", reviewLogsHandler.GetDueForecast)      // 获取到期预测
+				reviewLogs.GET("/insights", reviewLogsHandler.GetStudyInsights)    // 获取学习时间分析
+				reviewLogs.GET("/streak", reviewLogsHandler.GetReviewStreak)       // 获取复习连续天数
+				reviewLogs.GET("/report", reviewLogsHandler.GetStudyReport)        // 导出学习报告
+			}
+
+			// 备份与恢复路由
+			backup := auth.Group("/backup")
+			{
+				backup.GET("/export", backupHandler.ExportBackup)    // 导出账户备份
+				backup.POST("/restore", backupHandler.RestoreBackup) // 从备份恢复
 			}
 		}
 	}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"time"
+)
+
+// 触发成就检查的事件
+const (
+	EventReview  = "review"   // 完成一次复习
+	EventGoalMet = "goal_met" // 完成当天的目标
+)
+
+// 成就规则：在订阅的事件发生时检查指标是否达到阈值
+type achievementRule struct {
+	model.Achievement
+	Events []string
+}
+
+// 所有成就，按指标和阈值排列
+var achievementRules = []achievementRule{
+	{model.Achievement{Code: "first_review", Name: "初次启程", Description: "完成第一次复习", Metric: model.MetricReviews, Threshold: 1}, []string{EventReview}},
+	{model.Achievement{Code: "reviews_100", Name: "渐入佳境", Description: "累计复习 100 次", Metric: model.MetricReviews, Threshold: 100}, []string{EventReview}},
+	{model.Achievement{Code: "reviews_1000", Name: "千锤百炼", Description: "累计复习 1000 次", Metric: model.MetricReviews, Threshold: 1000}, []string{EventReview}},
+	{model.Achievement{Code: "reviews_10000", Name: "万里之行", Description: "累计复习 10000 次", Metric: model.MetricReviews, Threshold: 10000}, []string{EventReview}},
+	{model.Achievement{Code: "streak_7", Name: "一周坚持", Description: "连续 7 天完成每日目标", Metric: model.MetricStreak, Threshold: 7}, []string{EventGoalMet}},
+	{model.Achievement{Code: "streak_30", Name: "月度达人", Description: "连续 30 天完成每日目标", Metric: model.MetricStreak, Threshold: 30}, []string{EventGoalMet}},
+	{model.Achievement{Code: "streak_100", Name: "百日不辍", Description: "连续 100 天完成每日目标", Metric: model.MetricStreak, Threshold: 100}, []string{EventGoalMet}},
+	{model.Achievement{Code: "mature_100", Name: "根深蒂固", Description: "拥有 100 张成熟卡片", Metric: model.MetricMatureCards, Threshold: 100}, []string{EventReview}},
+	{model.Achievement{Code: "mature_1000", Name: "博闻强记", Description: "拥有 1000 张成熟卡片", Metric: model.MetricMatureCards, Threshold: 1000}, []string{EventReview}},
+}
+
+func (r *achievementRule) subscribes(events []string) bool {
+	for _, want := range r.Events {
+		for _, event := range events {
+			if want == event {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 成就指标的计算，同一次检查中每个指标只计算一次
+type achievementMetrics struct {
+	s      *GoalsService
+	goal   *model.StudyGoal
+	values map[string]int
+}
+
+func (m *achievementMetrics) get(metric string) (int, error) {
+	if value, ok := m.values[metric]; ok {
+		return value, nil
+	}
+
+	var value int
+	switch metric {
+	case model.MetricReviews:
+		count, err := m.s.repo.CountReviews(m.goal.UserID)
+		if err != nil {
+			return 0, err
+		}
+		value = int(count)
+	case model.MetricStreak:
+		value = m.goal.LongestStreak
+	case model.MetricMatureCards:
+		count, err := m.s.repo.CountMatureCards(m.goal.UserID)
+		if err != nil {
+			return 0, err
+		}
+		value = int(count)
+	}
+	m.values[metric] = value
+	return value, nil
+}
+
+// 检查订阅了这些事件且尚未解锁的成就，保存并返回新解锁的成就
+func (s *GoalsService) evaluateAchievements(goal *model.StudyGoal, events []string) ([]*model.UserAchievement, error) {
+	unlocked, err := s.repo.FindAchievements(goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(unlocked))
+	for _, a := range unlocked {
+		done[a.Code] = true
+	}
+
+	metrics := &achievementMetrics{s: s, goal: goal, values: make(map[string]int)}
+	now := time.Now()
+	var newly []*model.UserAchievement
+	for i := range achievementRules {
+		rule := &achievementRules[i]
+		if done[rule.Code] || !rule.subscribes(events) {
+			continue
+		}
+		value, err := metrics.get(rule.Metric)
+		if err != nil {
+			return nil, err
+		}
+		if value >= rule.Threshold {
+			newly = append(newly, &model.UserAchievement{UserID: goal.UserID, Code: rule.Code, UnlockedAt: now})
+		}
+	}
+
+	if err := s.repo.CreateAchievements(newly); err != nil {
+		return nil, err
+	}
+	return newly, nil
+}
+
+// GetAchievements 获取所有成就以及用户的进度和解锁时间
+func (s *GoalsService) GetAchievements(userID uint) ([]*model.AchievementStatus, error) {
+	goal, err := s.getGoal(userID)
+	if err != nil {
+		return nil, err
+	}
+	unlocked, err := s.repo.FindAchievements(userID)
+	if err != nil {
+		return nil, err
+	}
+	unlockedAt := make(map[string]time.Time, len(unlocked))
+	for _, a := range unlocked {
+		unlockedAt[a.Code] = a.UnlockedAt
+	}
+
+	metrics := &achievementMetrics{s: s, goal: goal, values: make(map[string]int)}
+	list := make([]*model.AchievementStatus, 0, len(achievementRules))
+	for _, rule := range achievementRules {
+		progress, err := metrics.get(rule.Metric)
+		if err != nil {
+			return nil, err
+		}
+		status := &model.AchievementStatus{Achievement: rule.Achievement, Progress: progress}
+		if at, ok := unlockedAt[rule.Code]; ok {
+			status.Unlocked = true
+			status.UnlockedAt = &at
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"ReMindful/internal/config"
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/jwt"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// AuthService 管理登录会话：签发短期访问令牌和一次性的刷新令牌，注销时把访问令牌加入黑名单
+type AuthService struct {
+	repo       *repository.AuthRepository
+	redis      *redis.Client
+	secret     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	loading    sync.Mutex // 同一时间只加载一次黑名单
+}
+
+func NewAuthService(repo *repository.AuthRepository, redis *redis.Client, secret string, cfg config.JWTConfig) *AuthService {
+	return &AuthService{
+		repo:       repo,
+		redis:      redis,
+		secret:     secret,
+		accessTTL:  cfg.Expiration,
+		refreshTTL: cfg.RefreshExpiration,
+	}
+}
+
+// 生成指定字节数的随机十六进制字符串
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// 刷新令牌只保存 SHA-256 哈希
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 令牌生效时间精确到秒，与访问令牌的 iat 一致
+func tokensValidAfter(now time.Time) time.Time {
+	return now.Truncate(time.Second)
+}
+
+func revokedTokenKey(jti string) string {
+	return fmt.Sprintf("revoked_token:%s", jti)
+}
+
+// 标记Redis中的黑名单已从数据库加载完整；Redis被清空后标记随之消失
+const revokedTokensLoadedKey = "revoked_tokens:loaded"
+
+// 缓存用户的令牌生效时间（Unix秒，未设置时为0）
+func tokensValidAfterKey(userID uint) string {
+	return fmt.Sprintf("tokens_valid_after:%d", userID)
+}
+
+// 为会话生成新的访问令牌ID和刷新令牌，会话的 AccessJTI 和过期时间等字段随之更新
+func (s *AuthService) rotateTokens(session *model.AuthSession) (string, *model.RefreshToken, error) {
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
+	refresh, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	session.AccessJTI = jti
+	session.AccessExpiresAt = now.Add(s.accessTTL)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTTL)
+	return refresh, &model.RefreshToken{
+		TokenHash: hashRefreshToken(refresh),
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// 签发会话的访问令牌，和刷新令牌一起返回
+func (s *AuthService) loginResponse(session *model.AuthSession, refresh string) (*model.LoginResponse, error) {
+	token, err := jwt.GenerateAccessToken(session.UserID, session.ID, session.AccessJTI, s.secret, session.AccessExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{
+		Token:           token,
+		ExpireAt:        session.AccessExpiresAt,
+		RefreshToken:    refresh,
+		RefreshExpireAt: session.ExpiresAt,
+		SessionID:       session.ID,
+	}, nil
+}
+
+// CreateSession 为登录的用户创建会话并签发令牌
+func (s *AuthService) CreateSession(userID uint, client model.ClientInfo) (*model.LoginResponse, error) {
+	session := &model.AuthSession{
+		UserID:    userID,
+		Device:    client.Device,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+	}
+	refresh, token, err := s.rotateTokens(session)
+	if err != nil {
+		return nil, err
+	}
+	// 访问令牌里带有会话ID，保存会话后再签发
+	if err := s.repo.CreateSession(session, token); err != nil {
+		return nil, err
+	}
+	return s.loginResponse(session, refresh)
+}
+
+// Refresh 用刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随即失效。
+// 已经用过的刷新令牌再次出现说明令牌可能泄露，整个会话会被注销
+func (s *AuthService) Refresh(refreshToken string, client model.ClientInfo) (*model.LoginResponse, error) {
+	stored, err := s.repo.FindRefreshToken(hashRefreshToken(refreshToken))
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("无效的刷新令牌")
+		}
+		return nil, err
+	}
+
+	session, err := s.repo.FindSession(stored.SessionID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.New("无效的刷新令牌")
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, errors.New("会话已注销")
+	}
+
+	reused := errors.New("刷新令牌已被使用，会话已注销")
+	if stored.UsedAt != nil {
+		if err := s.revokeSessions([]*model.AuthSession{session}); err != nil {
+			return nil, err
+		}
+		return nil, reused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("刷新令牌已过期")
+	}
+
+	previousJTI, previousExpiresAt := session.AccessJTI, session.AccessExpiresAt
+	refresh, newToken, err := s.rotateTokens(session)
+	if err != nil {
+		return nil, err
+	}
+	if client.UserAgent != "" {
+		session.UserAgent = client.UserAgent
+	}
+	if client.IP != "" {
+		session.IP = client.IP
+	}
+
+	rotated, err := s.repo.RotateRefreshToken(stored.ID, session, newToken)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 并发的另一次刷新已经用掉了这个令牌，同样视为重复使用；
+		// 重新读取会话，把另一次刷新签发的访问令牌加入黑名单
+		current, err := s.repo.FindSession(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.revokeSessions([]*model.AuthSession{current}); err != nil {
+			return nil, err
+		}
+		return nil, reused
+	}
+
+	// 每个会话只保留最新的访问令牌，旧的加入黑名单
+	if previousJTI != "" {
+		if err := s.revokeToken(previousJTI, previousExpiresAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.loginResponse(session, refresh)
+}
+
+// GetSession 获取登录会话
+func (s *AuthService) GetSession(id uint) (*model.AuthSession, error) {
+	return s.repo.FindSession(id)
+}
+
+// ListSessions 获取用户当前有效的登录会话（每台设备一个），标记发起请求的会话
+func (s *AuthService) ListSessions(userID, currentSessionID uint) ([]*model.AuthSession, error) {
+	sessions, err := s.repo.FindActiveSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 注销单个登录会话，会话的访问令牌立即失效
+func (s *AuthService) RevokeSession(session *model.AuthSession) error {
+	if session.RevokedAt != nil {
+		return nil
+	}
+	return s.revokeSessions([]*model.AuthSession{session})
+}
+
+// LogoutAll 注销用户的全部登录会话，exceptSessionID 不为 0 时保留该会话，返回注销的会话数。
+// 注销全部会话时同时更新用户的令牌生效时间，此前签发的访问令牌都不能再使用
+func (s *AuthService) LogoutAll(userID, exceptSessionID uint) (int, error) {
+	if exceptSessionID == 0 {
+		validAfter := tokensValidAfter(time.Now())
+		if err := s.repo.UpdateTokensValidAfter(userID, validAfter); err != nil {
+			return 0, err
+		}
+		// 直接覆盖缓存，不能只删除：并发的认证请求可能刚从数据库读到旧值
+		if s.redis != nil {
+			if err := s.redis.Set(context.Background(), tokensValidAfterKey(userID),
+				validAfter.Unix(), s.accessTTL).Err(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	sessions, err := s.repo.FindActiveSessions(userID)
+	if err != nil {
+		return 0, err
+	}
+	revoke := make([]*model.AuthSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ID != exceptSessionID {
+			revoke = append(revoke, session)
+		}
+	}
+	if err := s.revokeSessions(revoke); err != nil {
+		return 0, err
+	}
+	return len(revoke), nil
+}
+
+// 注销会话并把会话当前的访问令牌加入黑名单
+func (s *AuthService) revokeSessions(sessions []*model.AuthSession) error {
+	ids := make([]uint, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+		if session.AccessJTI != "" {
+			if err := s.revokeToken(session.AccessJTI, session.AccessExpiresAt); err != nil {
+				return err
+			}
+		}
+	}
+	if err := s.repo.RevokeSessions(ids); err != nil {
+		return err
+	}
+
+	// 顺便清理已经过期的黑名单条目
+	if err := s.repo.DeleteExpiredRevokedTokens(); err != nil {
+		log.Printf("清理过期的令牌黑名单失败: %v", err)
+	}
+	return nil
+}
+
+// 把访问令牌加入黑名单，令牌过期后条目就没有用了。
+// 数据库保存完整的黑名单，配置了Redis时同时写入Redis，认证中间件只查询Redis
+func (s *AuthService) revokeToken(jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.repo.CreateRevokedToken(jti, expiresAt); err != nil {
+		return err
+	}
+	if s.redis != nil {
+		return s.redis.Set(context.Background(), revokedTokenKey(jti), 1, ttl).Err()
+	}
+	return nil
+}
+
+// LoadRevokedTokens 把数据库中尚未过期的黑名单条目写入Redis，完成后设置加载标记。
+// 服务启动时调用；Redis被清空后认证中间件发现标记丢失，也会重新加载
+func (s *AuthService) LoadRevokedTokens() error {
+	if s.redis == nil {
+		return nil
+	}
+	if !s.loading.TryLock() {
+		return nil
+	}
+	defer s.loading.Unlock()
+
+	tokens, err := s.repo.FindActiveRevokedTokens()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	pipe := s.redis.Pipeline()
+	for _, token := range tokens {
+		if ttl := time.Until(token.ExpiresAt); ttl > 0 {
+			pipe.Set(ctx, revokedTokenKey(token.JTI), 1, ttl)
+		}
+	}
+	pipe.Set(ctx, revokedTokensLoadedKey, 1, 0)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// IsRevoked 检查访问令牌是否已失效，供认证中间件调用：
+// 签发时间早于用户的令牌生效时间，或者 jti 在黑名单中。
+// 配置了Redis时一次 MGET 取得缓存的令牌生效时间和黑名单条目，Redis中的黑名单是完整的，
+// 不再查询数据库；没有Redis、Redis出错或黑名单尚未加载时以数据库为准，数据库出错时按已失效处理
+func (s *AuthService) IsRevoked(claims *jwt.Claims) bool {
+	if s.redis != nil {
+		revoked, ok := s.isRevokedCached(claims)
+		if ok {
+			return revoked
+		}
+	}
+
+	validAfter, err := s.repo.FindTokensValidAfter(claims.UserID)
+	if err != nil {
+		log.Printf("查询令牌生效时间失败: %v", err)
+		return true
+	}
+	if issuedBefore(claims, validAfter) {
+		return true
+	}
+	revoked, err := s.repo.IsTokenRevoked(claims.ID)
+	if err != nil {
+		log.Printf("查询令牌黑名单失败: %v", err)
+		return true
+	}
+	return revoked
+}
+
+// 令牌的签发时间是否早于令牌生效时间
+func issuedBefore(claims *jwt.Claims, validAfter *time.Time) bool {
+	return validAfter != nil && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(*validAfter))
+}
+
+// 通过Redis判断令牌是否失效，ok 为 false 时需要回退到数据库
+func (s *AuthService) isRevokedCached(claims *jwt.Claims) (revoked, ok bool) {
+	ctx := context.Background()
+	validAfterKey := tokensValidAfterKey(claims.UserID)
+	values, err := s.redis.MGet(ctx, validAfterKey, revokedTokenKey(claims.ID), revokedTokensLoadedKey).Result()
+	if err != nil {
+		log.Printf("查询Redis令牌黑名单失败: %v", err)
+		return false, false
+	}
+
+	var validAfter *time.Time
+	if cached, isString := values[0].(string); isString {
+		sec, err := strconv.ParseInt(cached, 10, 64)
+		if err != nil {
+			return false, false
+		}
+		if sec > 0 {
+			t := time.Unix(sec, 0)
+			validAfter = &t
+		}
+	} else {
+		validAfter, err = s.repo.FindTokensValidAfter(claims.UserID)
+		if err != nil {
+			log.Printf("查询令牌生效时间失败: %v", err)
+			return true, true
+		}
+		var sec int64
+		if validAfter != nil {
+			sec = validAfter.Unix()
+		}
+		// SetNX 不会覆盖注销全部设备时刚写入的新值
+		if err := s.redis.SetNX(ctx, validAfterKey, sec, s.accessTTL).Err(); err != nil {
+			log.Printf("缓存令牌生效时间失败: %v", err)
+		}
+	}
+	if issuedBefore(claims, validAfter) {
+		return true, true
+	}
+
+	if values[1] != nil {
+		return true, true
+	}
+	if values[2] == nil {
+		// 黑名单不完整（Redis重启或被清空），重新加载，本次以数据库为准
+		go func() {
+			if err := s.LoadRevokedTokens(); err != nil {
+				log.Printf("加载令牌黑名单失败: %v", err)
+			}
+		}()
+		revoked, err := s.repo.IsTokenRevoked(claims.ID)
+		if err != nil {
+			log.Printf("查询令牌黑名单失败: %v", err)
+			return true, true
+		}
+		return revoked, true
+	}
+	return false, true
+}
@@ -0,0 +1,585 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type BackupService struct {
+	repo     *repository.BackupRepository
+	userRepo *repository.UserRepository
+}
+
+func NewBackupService(repo *repository.BackupRepository, userRepo *repository.UserRepository) *BackupService {
+	return &BackupService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// 归档内的文件名
+const (
+	backupManifestFile   = "manifest.json"
+	backupProfileFile    = "profile.json"
+	backupDecksFile      = "decks.jsonl"
+	backupTagsFile       = "tags.jsonl"
+	backupCardsFile      = "cards.jsonl"
+	backupCardTagsFile   = "card_tags.jsonl"
+	backupReviewLogsFile = "review_logs.jsonl"
+	backupBatchSize      = 500
+)
+
+// 各文件的字段说明，写入 manifest.json
+var backupSchema = map[string]map[string]string{
+	backupProfileFile: {
+		"username":   "用户名",
+		"email":      "邮箱",
+		"photo_url":  "头像URL",
+		"timezone":   "时区",
+		"is_premium": "是否是高级用户",
+		"created_at": "注册时间 (RFC3339)",
+	},
+	backupDecksFile: {
+		"id":         "导出时的卡组ID，仅用于归档内引用",
+		"name":       "卡组名称",
+		"parent_id":  "引用 decks.jsonl 中的父卡组 id，为空表示顶级卡组",
+		"is_default": "是否为默认卡组",
+		"created_at": "创建时间 (RFC3339)",
+	},
+	backupTagsFile: {
+		"id":         "导出时的标签ID，仅用于归档内引用",
+		"name":       "标签名称",
+		"color_code": "颜色代码",
+		"created_at": "创建时间 (RFC3339)",
+	},
+	backupCardsFile: {
+		"id":             "导出时的卡片ID，仅用于归档内引用",
+		"deck_id":        "引用 decks.jsonl 中的 id",
+		"title":          "标题",
+		"content":        "内容",
+		"card_type":      "卡片类型: basic/cloze/question",
+		"next_review":    "下次复习时间 (RFC3339)",
+		"last_review_at": "上次复习时间 (RFC3339)",
+		"review_count":   "复习次数",
+		"learning_step":  "当前所处的学习步长（从1开始），0 或缺省表示不在学习阶段",
+		"interval":       "当前间隔天数",
+		"ease_factor":    "简易因子",
+		"difficulty":     "难度系数",
+		"source_anchor":  "导入来源锚点，可为空",
+		"created_at":     "创建时间 (RFC3339)",
+		"updated_at":     "更新时间 (RFC3339)",
+	},
+	backupCardTagsFile: {
+		"card_id": "引用 cards.jsonl 中的 id",
+		"tag_id":  "引用 tags.jsonl 中的 id",
+	},
+	backupReviewLogsFile: {
+		"id":             "导出时的日志ID",
+		"card_id":        "引用 cards.jsonl 中的 id",
+		"review_time":    "复习时间 (RFC3339)",
+		"performance":    "自评分数 (1-5)",
+		"duration":       "复习耗时（秒）",
+		"elapsed_days":   "距上次复习的天数，可为空",
+		"scheduled_days": "复习前安排的间隔天数，可为空",
+		"new_card":       "复习前卡片是否从未复习过，可为空",
+	},
+}
+
+// Export 将用户的全部数据写入zip归档
+func (s *BackupService) Export(userID uint, w io.Writer) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	manifest := &model.BackupManifest{
+		Format:     model.BackupFormat,
+		Version:    model.BackupVersion,
+		ExportedAt: time.Now(),
+		UserID:     userID,
+		Schema:     backupSchema,
+	}
+
+	// 用户资料
+	profile := model.BackupProfile{
+		Username:  user.Username,
+		Email:     user.Email,
+		PhotoURL:  user.PhotoURL,
+		Timezone:  user.Timezone,
+		IsPremium: user.IsPremium,
+		CreatedAt: user.CreatedAt,
+	}
+	if err := writeJSONFile(zw, backupProfileFile, profile); err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupProfileFile, Description: "用户资料", Records: 1})
+
+	// 卡组
+	count, err := writeJSONLines(zw, backupDecksFile, func(emit func(v interface{}) error) error {
+		decks, err := s.repo.FindDecks(userID)
+		if err != nil {
+			return err
+		}
+		for _, deck := range decks {
+			if err := emit(model.BackupDeck{ID: deck.ID, Name: deck.Name, ParentID: deck.ParentID, IsDefault: deck.IsDefault, CreatedAt: deck.CreatedAt}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupDecksFile, Description: "卡组", Records: count})
+
+	// 标签
+	count, err = writeJSONLines(zw, backupTagsFile, func(emit func(v interface{}) error) error {
+		tags, err := s.repo.FindTags(userID)
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := emit(model.BackupTag{ID: tag.ID, Name: tag.Name, ColorCode: tag.ColorCode, CreatedAt: tag.CreatedAt}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupTagsFile, Description: "标签", Records: count})
+
+	// 卡片
+	count, err = writeJSONLines(zw, backupCardsFile, func(emit func(v interface{}) error) error {
+		return s.repo.EachCard(userID, backupBatchSize, func(cards []*model.LearningCard) error {
+			for _, card := range cards {
+				if err := emit(model.BackupCard{
+					ID:           card.ID,
+					DeckID:       card.DeckID,
+					Title:        card.Title,
+					Content:      card.Content,
+					CardType:     card.CardType,
+					NextReview:   card.NextReview,
+					LastReviewAt: card.LastReviewAt,
+					ReviewCount:  card.ReviewCount,
+					LearningStep: card.LearningStep,
+					Interval:     card.Interval,
+					EaseFactor:   card.EaseFactor,
+					Difficulty:   card.Difficulty,
+					SourceAnchor: card.SourceAnchor,
+					CreatedAt:    card.CreatedAt,
+					UpdatedAt:    card.UpdatedAt,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupCardsFile, Description: "学习卡片", Records: count})
+
+	// 卡片标签关联
+	count, err = writeJSONLines(zw, backupCardTagsFile, func(emit func(v interface{}) error) error {
+		return s.repo.EachCardTag(userID, backupBatchSize, func(links []model.CardTag) error {
+			for _, link := range links {
+				if err := emit(model.BackupCardTag{CardID: link.LearningCardID, TagID: link.TagID}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupCardTagsFile, Description: "卡片与标签的关联", Records: count})
+
+	// 复习日志
+	count, err = writeJSONLines(zw, backupReviewLogsFile, func(emit func(v interface{}) error) error {
+		return s.repo.EachReviewLog(userID, backupBatchSize, func(logs []*model.ReviewLog) error {
+			for _, log := range logs {
+				if err := emit(model.BackupReviewLog{
+					ID:            log.ID,
+					CardID:        log.CardID,
+					ReviewTime:    log.ReviewTime,
+					Performance:   log.Performance,
+					Duration:      log.Duration,
+					ElapsedDays:   log.ElapsedDays,
+					ScheduledDays: log.ScheduledDays,
+					NewCard:       log.NewCard,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, model.BackupFile{Name: backupReviewLogsFile, Description: "复习日志", Records: count})
+
+	// 清单最后写入，以便包含各文件的记录数
+	if err := writeJSONFile(zw, backupManifestFile, manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// 写入单个JSON文件
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// 写入JSON Lines文件，返回写入的记录数
+func writeJSONLines(zw *zip.Writer, name string, produce func(emit func(v interface{}) error) error) (int, error) {
+	f, err := zw.Create(name)
+	if err != nil {
+		return 0, err
+	}
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	count := 0
+	err = produce(func(v interface{}) error {
+		count++
+		return enc.Encode(v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, bw.Flush()
+}
+
+// Restore 将备份归档恢复到当前账户，所有ID重新分配
+func (s *BackupService) Restore(userID uint, r io.ReaderAt, size int64) (*model.RestoreResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("无效的备份文件")
+	}
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var manifest model.BackupManifest
+	if err := readJSONFile(files, backupManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Format != model.BackupFormat {
+		return nil, errors.New("不是ReMindful备份文件")
+	}
+	if manifest.Version < 1 || manifest.Version > model.BackupVersion {
+		return nil, fmt.Errorf("不支持的备份版本: %d", manifest.Version)
+	}
+
+	result := &model.RestoreResult{Version: manifest.Version}
+	err = s.repo.Transaction(func(repo *repository.BackupRepository) error {
+		existing, err := repo.CountUserContent(userID)
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errors.New("只能恢复到没有卡片和标签的新账户")
+		}
+
+		// 卡组：记录旧ID到新ID的映射
+		deckIDs, err := restoreDecks(repo, files, userID)
+		if err != nil {
+			return err
+		}
+		result.Decks = len(deckIDs)
+		// 旧版本的备份没有卡组，卡片所属卡组缺失时放入默认卡组
+		defaultDeck, err := repo.FindDefaultDeck(userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			defaultDeck = &model.Deck{UserID: userID, Name: model.DefaultDeckName, IsDefault: true}
+			err = repo.CreateDeck(defaultDeck)
+		}
+		if err != nil {
+			return err
+		}
+
+		// 标签：记录旧ID到新ID的映射
+		tagIDs := make(map[uint]uint)
+		var tags []*model.Tag
+		var oldTagIDs []uint
+		err = readJSONLines(files, backupTagsFile, func(dec *json.Decoder) error {
+			var t model.BackupTag
+			if err := dec.Decode(&t); err != nil {
+				return err
+			}
+			tag := &model.Tag{Name: t.Name, ColorCode: t.ColorCode, UserID: userID}
+			tag.CreatedAt = t.CreatedAt
+			tags = append(tags, tag)
+			oldTagIDs = append(oldTagIDs, t.ID)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := repo.CreateTags(tags); err != nil {
+			return err
+		}
+		for i, tag := range tags {
+			tagIDs[oldTagIDs[i]] = tag.ID
+		}
+		result.Tags = len(tags)
+
+		// 卡片：分批创建并记录ID映射
+		cardIDs := make(map[uint]uint)
+		var cards []*model.LearningCard
+		var oldCardIDs []uint
+		flushCards := func() error {
+			if err := repo.CreateCards(cards); err != nil {
+				return err
+			}
+			for i, card := range cards {
+				cardIDs[oldCardIDs[i]] = card.ID
+			}
+			result.Cards += len(cards)
+			cards, oldCardIDs = cards[:0], oldCardIDs[:0]
+			return nil
+		}
+		err = readJSONLines(files, backupCardsFile, func(dec *json.Decoder) error {
+			var c model.BackupCard
+			if err := dec.Decode(&c); err != nil {
+				return err
+			}
+			deckID, ok := deckIDs[c.DeckID]
+			if !ok {
+				deckID = defaultDeck.ID
+			}
+			card := &model.LearningCard{
+				UserID:       userID,
+				DeckID:       deckID,
+				Title:        c.Title,
+				Content:      c.Content,
+				CardType:     c.CardType,
+				NextReview:   c.NextReview,
+				LastReviewAt: c.LastReviewAt,
+				ReviewCount:  c.ReviewCount,
+				LearningStep: c.LearningStep,
+				Interval:     c.Interval,
+				EaseFactor:   c.EaseFactor,
+				Difficulty:   c.Difficulty,
+				SourceAnchor: c.SourceAnchor,
+			}
+			card.CreatedAt = c.CreatedAt
+			card.UpdatedAt = c.UpdatedAt
+			card.UpdateFingerprint()
+			cards = append(cards, card)
+			oldCardIDs = append(oldCardIDs, c.ID)
+			if len(cards) >= backupBatchSize {
+				return flushCards()
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := flushCards(); err != nil {
+			return err
+		}
+
+		// 卡片标签关联
+		var links []model.CardTag
+		err = readJSONLines(files, backupCardTagsFile, func(dec *json.Decoder) error {
+			var l model.BackupCardTag
+			if err := dec.Decode(&l); err != nil {
+				return err
+			}
+			cardID, okCard := cardIDs[l.CardID]
+			tagID, okTag := tagIDs[l.TagID]
+			if !okCard || !okTag {
+				result.Skipped++
+				return nil
+			}
+			links = append(links, model.CardTag{LearningCardID: cardID, TagID: tagID})
+			if len(links) >= backupBatchSize {
+				if err := repo.CreateCardTags(links); err != nil {
+					return err
+				}
+				result.CardTags += len(links)
+				links = links[:0]
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := repo.CreateCardTags(links); err != nil {
+			return err
+		}
+		result.CardTags += len(links)
+
+		// 复习日志
+		var logs []*model.ReviewLog
+		err = readJSONLines(files, backupReviewLogsFile, func(dec *json.Decoder) error {
+			var l model.BackupReviewLog
+			if err := dec.Decode(&l); err != nil {
+				return err
+			}
+			cardID, ok := cardIDs[l.CardID]
+			if !ok {
+				result.Skipped++
+				return nil
+			}
+			logs = append(logs, &model.ReviewLog{
+				CardID:        cardID,
+				UserID:        userID,
+				ReviewTime:    l.ReviewTime,
+				Performance:   l.Performance,
+				Duration:      l.Duration,
+				ElapsedDays:   l.ElapsedDays,
+				ScheduledDays: l.ScheduledDays,
+				NewCard:       l.NewCard,
+			})
+			if len(logs) >= backupBatchSize {
+				if err := repo.CreateReviewLogs(logs); err != nil {
+					return err
+				}
+				result.ReviewLogs += len(logs)
+				logs = logs[:0]
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := repo.CreateReviewLogs(logs); err != nil {
+			return err
+		}
+		result.ReviewLogs += len(logs)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 恢复资料中与账户身份无关的字段
+	var profile model.BackupProfile
+	if err := readJSONFile(files, backupProfileFile, &profile); err == nil {
+		updates := map[string]interface{}{}
+		if profile.PhotoURL != "" {
+			updates["photo_url"] = profile.PhotoURL
+		}
+		if profile.Timezone != "" {
+			updates["timezone"] = profile.Timezone
+		}
+		if len(updates) > 0 {
+			if err := s.userRepo.UpdateFields(userID, updates); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// 恢复卡组，父卡组先于子卡组创建；归档中的默认卡组并入账户已有的默认卡组
+func restoreDecks(repo *repository.BackupRepository, files map[string]*zip.File, userID uint) (map[uint]uint, error) {
+	var decks []model.BackupDeck
+	err := readJSONLines(files, backupDecksFile, func(dec *json.Decoder) error {
+		var d model.BackupDeck
+		if err := dec.Decode(&d); err != nil {
+			return err
+		}
+		decks = append(decks, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs := make(map[uint]uint)
+	for len(decks) > 0 {
+		var pending []model.BackupDeck
+		for _, d := range decks {
+			var parentID *uint
+			if d.ParentID != nil {
+				id, ok := deckIDs[*d.ParentID]
+				if !ok {
+					pending = append(pending, d)
+					continue
+				}
+				parentID = &id
+			}
+			if d.IsDefault {
+				if existing, err := repo.FindDefaultDeck(userID); err == nil {
+					deckIDs[d.ID] = existing.ID
+					continue
+				}
+			}
+			deck := &model.Deck{UserID: userID, Name: d.Name, ParentID: parentID, IsDefault: d.IsDefault}
+			deck.CreatedAt = d.CreatedAt
+			if err := repo.CreateDeck(deck); err != nil {
+				return nil, err
+			}
+			deckIDs[d.ID] = deck.ID
+		}
+		// 父卡组缺失或存在循环引用时，剩余卡组挂到顶级
+		if len(pending) == len(decks) {
+			for i := range pending {
+				pending[i].ParentID = nil
+			}
+		}
+		decks = pending
+	}
+	return deckIDs, nil
+}
+
+// 读取单个JSON文件
+func readJSONFile(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("备份文件缺少 %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %v", name, err)
+	}
+	return nil
+}
+
+// 逐条读取JSON Lines文件，文件不存在时视为空
+func readJSONLines(files map[string]*zip.File, name string, fn func(dec *json.Decoder) error) error {
+	f, ok := files[name]
+	if !ok {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := json.NewDecoder(bufio.NewReader(rc))
+	for dec.More() {
+		if err := fn(dec); err != nil {
+			return fmt.Errorf("处理 %s 失败: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// 批量操作的限制
+const (
+	maxBulkCards  = 5000 // 一次最多操作的卡片数
+	bulkChunkSize = 200  // 每个事务处理的卡片数
+)
+
+// 批量操作：卡片由ID列表或搜索语句指定，分批在事务中执行，返回每张卡片的结果
+func (s *LearningCardsService) BulkUpdate(userID uint, req *model.BulkCardsRequest) (*model.BulkCardsResult, error) {
+	if err := s.checkBulkRequest(userID, req); err != nil {
+		return nil, err
+	}
+	ids, err := s.bulkTargetIDs(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.BulkCardsResult{Action: req.Action, Total: len(ids), Results: make([]*model.BulkItemResult, 0, len(ids))}
+	now := time.Now()
+	for start := 0; start < len(ids); start += bulkChunkSize {
+		end := start + bulkChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		result.Results = append(result.Results, s.bulkChunk(userID, req, ids[start:end], now)...)
+	}
+	for _, item := range result.Results {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// 校验操作参数
+func (s *LearningCardsService) checkBulkRequest(userID uint, req *model.BulkCardsRequest) error {
+	if (len(req.CardIDs) == 0) == (req.Query == "") {
+		return errors.New("card_ids 和 query 必须且只能指定一个")
+	}
+
+	switch req.Action {
+	case model.BulkAddTags, model.BulkRemoveTags:
+		if len(req.TagIDs) == 0 {
+			return errors.New("标签不能为空")
+		}
+		req.TagIDs = uniqueIDs(req.TagIDs)
+		tags, err := s.repo.ExistingTags(userID, req.TagIDs)
+		if err != nil {
+			return err
+		}
+		if len(tags) != len(req.TagIDs) {
+			return errors.New("标签不存在")
+		}
+	case model.BulkSetType:
+		if !req.CardType.IsValid() {
+			return errors.New("无效的卡片类型")
+		}
+	case model.BulkMoveDeck:
+		// 借用 resolveDeck 校验目标卡组，为空时使用默认卡组
+		target := &model.LearningCard{UserID: userID, DeckID: req.DeckID}
+		if err := s.resolveDeck(target); err != nil {
+			return err
+		}
+		req.DeckID = target.DeckID
+		if req.DeckID == 0 {
+			return errors.New("卡组不存在")
+		}
+	case model.BulkReschedule:
+		if req.DueFrom == nil {
+			return errors.New("复习时间范围不能为空")
+		}
+		if req.DueTo == nil {
+			req.DueTo = req.DueFrom
+		}
+		if req.DueTo.Before(*req.DueFrom) {
+			return errors.New("复习时间范围的结束不能早于开始")
+		}
+	case model.BulkForget, model.BulkSuspend, model.BulkUnsuspend, model.BulkDelete:
+	default:
+		return fmt.Errorf("不支持的批量操作 %s", req.Action)
+	}
+	return nil
+}
+
+// 获取要操作的卡片ID
+func (s *LearningCardsService) bulkTargetIDs(userID uint, req *model.BulkCardsRequest) ([]uint, error) {
+	if req.Query != "" {
+		ids, err := s.repo.FindIDsBySearch(userID, &model.CardFilter{Query: req.Query}, maxBulkCards+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > maxBulkCards {
+			return nil, fmt.Errorf("匹配的卡片超过 %d 张，请缩小搜索范围", maxBulkCards)
+		}
+		return ids, nil
+	}
+
+	ids := uniqueIDs(req.CardIDs)
+	if len(ids) > maxBulkCards {
+		return nil, fmt.Errorf("一次最多操作 %d 张卡片", maxBulkCards)
+	}
+	return ids, nil
+}
+
+// 在一个事务中处理一批卡片
+func (s *LearningCardsService) bulkChunk(userID uint, req *model.BulkCardsRequest, ids []uint, now time.Time) []*model.BulkItemResult {
+	results := make([]*model.BulkItemResult, len(ids))
+	for i, id := range ids {
+		results[i] = &model.BulkItemResult{ID: id}
+	}
+
+	cards, err := s.repo.FindBulkTargets(ids)
+	if err != nil {
+		return failAll(results, err)
+	}
+	byID := make(map[uint]*model.LearningCard, len(cards))
+	for _, card := range cards {
+		byID[card.ID] = card
+	}
+
+	var valid []*model.LearningCard
+	for _, item := range results {
+		card, ok := byID[item.ID]
+		switch {
+		case !ok:
+			item.Error = "卡片不存在"
+		case card.UserID != userID:
+			item.Error = "无权限操作此卡片"
+		case req.Action == model.BulkReschedule && card.ReviewCount == 0:
+			item.Error = "新卡片不能安排复习时间"
+		default:
+			valid = append(valid, card)
+		}
+	}
+	if len(valid) == 0 {
+		return results
+	}
+	validIDs := make([]uint, len(valid))
+	for i, card := range valid {
+		validIDs[i] = card.ID
+	}
+
+	// 修改标签和类型属于内容修改，需要记录修订
+	recordRevisions := s.revisionsService != nil &&
+		(req.Action == model.BulkAddTags || req.Action == model.BulkRemoveTags || req.Action == model.BulkSetType)
+
+	// 修订与卡片的修改在同一事务中写入
+	err = s.repo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		var before []*model.LearningCard
+		if recordRevisions {
+			var err error
+			if before, err = repo.FindByIDs(validIDs); err != nil {
+				return err
+			}
+		}
+		if err := applyBulk(repo, req, valid, validIDs, now); err != nil {
+			return err
+		}
+		if recordRevisions {
+			return s.revisionsService.RecordUpdates(repo, before, userID)
+		}
+		return nil
+	})
+	if s.redis != nil {
+		for _, id := range validIDs {
+			s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, id))
+		}
+	}
+
+	for _, item := range results {
+		if item.Error != "" {
+			continue
+		}
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Success = true
+		}
+	}
+	return results
+}
+
+// 执行批量操作
+func applyBulk(repo *repository.LearningCardsRepository, req *model.BulkCardsRequest, cards []*model.LearningCard, ids []uint, now time.Time) error {
+	switch req.Action {
+	case model.BulkAddTags:
+		return repo.AddTags(ids, req.TagIDs)
+	case model.BulkRemoveTags:
+		return repo.RemoveTags(ids, req.TagIDs)
+	case model.BulkSetType:
+		return repo.UpdateColumns(ids, map[string]interface{}{"card_type": req.CardType})
+	case model.BulkMoveDeck:
+		return repo.MoveToDeck(ids, req.DeckID)
+	case model.BulkForget:
+		if req.ArchiveLogs {
+			if err := repo.ArchiveReviewLogs(ids); err != nil {
+				return err
+			}
+		}
+		var reset model.LearningCard
+		initReviewSchedule(&reset, now)
+		return repo.UpdateColumns(ids, map[string]interface{}{
+			"review_count":   reset.ReviewCount,
+			"learning_step":  reset.LearningStep,
+			"interval":       reset.Interval,
+			"ease_factor":    reset.EaseFactor,
+			"difficulty":     reset.Difficulty,
+			"next_review":    reset.NextReview,
+			"last_review_at": reset.LastReviewAt,
+		})
+	case model.BulkReschedule:
+		span := req.DueTo.Sub(*req.DueFrom)
+		for _, card := range cards {
+			due := *req.DueFrom
+			if span > 0 {
+				due = due.Add(time.Duration(rand.Int63n(int64(span) + 1)))
+			}
+			// 间隔按距离下次复习的天数计算，至少1天
+			interval := int(math.Ceil(due.Sub(now).Hours() / 24))
+			if interval < 1 {
+				interval = 1
+			}
+			if err := repo.UpdateColumns([]uint{card.ID}, map[string]interface{}{
+				"next_review":   due,
+				"interval":      interval,
+				"learning_step": 0,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case model.BulkSuspend, model.BulkUnsuspend:
+		return repo.UpdateColumns(ids, map[string]interface{}{"suspended": req.Action == model.BulkSuspend})
+	case model.BulkDelete:
+		return repo.SoftDeleteByIDs(ids)
+	}
+	return fmt.Errorf("不支持的批量操作 %s", req.Action)
+}
+
+// 将错误记录到所有卡片的结果中
+func failAll(results []*model.BulkItemResult, err error) []*model.BulkItemResult {
+	for _, item := range results {
+		if item.Error == "" {
+			item.Error = err.Error()
+		}
+	}
+	return results
+}
+
+// 去掉重复的ID，保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"ReMindful/internal/config"
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/textdiff"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type CardRevisionsService struct {
+	repo      *repository.CardRevisionsRepository
+	cardsRepo *repository.LearningCardsRepository
+	userRepo  *repository.UserRepository
+	redis     *redis.Client
+	retention config.RevisionConfig
+}
+
+func NewCardRevisionsService(repo *repository.CardRevisionsRepository, cardsRepo *repository.LearningCardsRepository, userRepo *repository.UserRepository, redis *redis.Client, retention config.RevisionConfig) *CardRevisionsService {
+	return &CardRevisionsService{
+		repo:      repo,
+		cardsRepo: cardsRepo,
+		userRepo:  userRepo,
+		redis:     redis,
+		retention: retention,
+	}
+}
+
+// 记录卡片的一次修改：内容没有变化时不记录，卡片第一次修改时先保存修改前的原始版本。
+// repo 为修改卡片时使用的仓库，修订与卡片的修改在同一事务中写入
+func (s *CardRevisionsService) RecordChange(repo *repository.LearningCardsRepository, before, after *model.LearningCard, editorID uint, action string, restoredFrom *uint) error {
+	return s.recordChange(repo.Revisions(), before, after, editorID, action, restoredFrom)
+}
+
+// RecordUpdates 为一批编辑过的卡片记录修订：before 为修改前读取的卡片，修改后的内容在同一事务中重新读取
+func (s *CardRevisionsService) RecordUpdates(repo *repository.LearningCardsRepository, before []*model.LearningCard, editorID uint) error {
+	if len(before) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(before))
+	for i, card := range before {
+		ids[i] = card.ID
+	}
+	after, err := repo.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	afterByID := make(map[uint]*model.LearningCard, len(after))
+	for _, card := range after {
+		afterByID[card.ID] = card
+	}
+	for _, card := range before {
+		if updated, ok := afterByID[card.ID]; ok {
+			if err := s.RecordChange(repo, card, updated, editorID, model.RevisionUpdate, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 使用指定的修订仓库记录修改，repo 可以绑定到调用方的事务。
+// 本次创建的原始版本和新修订不参与同一次清理，原始版本的时间是修改前的更新时间，可能已经超出保留天数
+func (s *CardRevisionsService) recordChange(repo *repository.CardRevisionsRepository, before, after *model.LearningCard, editorID uint, action string, restoredFrom *uint) error {
+	changed := changedFields(before, after)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	var created []uint
+	if _, err := repo.FindLatest(after.ID); errors.Is(err, gorm.ErrRecordNotFound) {
+		baseline := snapshot(before)
+		baseline.EditorID = before.UserID
+		baseline.Action = model.RevisionBaseline
+		baseline.CreatedAt = before.UpdatedAt
+		if err := repo.Create(baseline); err != nil {
+			return err
+		}
+		created = append(created, baseline.ID)
+	} else if err != nil {
+		return err
+	}
+
+	revision := snapshot(after)
+	revision.EditorID = editorID
+	revision.Action = action
+	revision.ChangedFields = changed
+	revision.RestoredFrom = restoredFrom
+	if err := repo.Create(revision); err != nil {
+		return err
+	}
+	created = append(created, revision.ID)
+	return s.prune(repo, after, created)
+}
+
+// 按用户等级的保留策略清理旧修订，keep 中的修订不清理
+func (s *CardRevisionsService) prune(repo *repository.CardRevisionsRepository, card *model.LearningCard, keep []uint) error {
+	policy := s.retention.Free
+	if user, err := s.userRepo.FindByID(card.UserID); err == nil && user.IsPremium {
+		policy = s.retention.Premium
+	}
+	var since time.Time
+	if policy.MaxDays > 0 {
+		since = time.Now().AddDate(0, 0, -policy.MaxDays)
+	}
+	return repo.Prune(card.ID, policy.MaxCount, since, keep...)
+}
+
+// 获取卡片的修订列表，最新的在前
+func (s *CardRevisionsService) GetRevisions(cardID uint) ([]*model.CardRevision, error) {
+	return s.repo.FindByCardID(cardID)
+}
+
+// 获取卡片的指定修订
+func (s *CardRevisionsService) GetRevision(cardID, revisionID uint) (*model.CardRevision, error) {
+	revision, err := s.repo.FindByID(revisionID)
+	if err != nil {
+		return nil, errors.New("修订不存在")
+	}
+	if revision.CardID != cardID {
+		return nil, errors.New("修订不属于此卡片")
+	}
+	return revision, nil
+}
+
+// 获取卡片最新的修订，没有修订时返回 nil
+func (s *CardRevisionsService) GetLatestRevision(cardID uint) (*model.CardRevision, error) {
+	revision, err := s.repo.FindLatest(cardID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return revision, err
+}
+
+// 比较两个修订
+func (s *CardRevisionsService) Diff(from, to *model.CardRevision) *model.RevisionDiff {
+	diff := &model.RevisionDiff{
+		From:        from.ID,
+		To:          to.ID,
+		Title:       textdiff.Runes(from.Title, to.Title),
+		Content:     textdiff.Lines(from.Content, to.Content),
+		TagsAdded:   []string{},
+		TagsRemoved: []string{},
+	}
+	if from.CardType != to.CardType {
+		diff.CardType = &model.FieldChange{From: string(from.CardType), To: string(to.CardType)}
+	}
+
+	fromTags := make(map[uint]string, len(from.Tags))
+	for _, tag := range from.Tags {
+		fromTags[tag.ID] = tag.Name
+	}
+	toTags := make(map[uint]bool, len(to.Tags))
+	for _, tag := range to.Tags {
+		toTags[tag.ID] = true
+		if _, ok := fromTags[tag.ID]; !ok {
+			diff.TagsAdded = append(diff.TagsAdded, tag.Name)
+		}
+	}
+	for _, tag := range from.Tags {
+		if !toTags[tag.ID] {
+			diff.TagsRemoved = append(diff.TagsRemoved, tag.Name)
+		}
+	}
+	return diff
+}
+
+// 将卡片恢复到指定修订的内容，已删除的标签会被跳过，恢复本身也记录为一次修订。
+// 内容、标签和修订记录在同一事务中写入，提交后再清除卡片缓存
+func (s *CardRevisionsService) Restore(card *model.LearningCard, revision *model.CardRevision, editorID uint) (*model.LearningCard, error) {
+	tagIDs := make([]uint, len(revision.Tags))
+	for i, tag := range revision.Tags {
+		tagIDs[i] = tag.ID
+	}
+
+	var after *model.LearningCard
+	err := s.cardsRepo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		before, err := loadCard(repo, card.ID)
+		if err != nil {
+			return err
+		}
+		tags, err := repo.ExistingTags(card.UserID, tagIDs)
+		if err != nil {
+			return err
+		}
+
+		restored := *before
+		restored.Title = revision.Title
+		restored.Content = revision.Content
+		restored.CardType = revision.CardType
+		restored.UpdateFingerprint()
+		if err := repo.UpdateContent(&restored); err != nil {
+			return err
+		}
+		if err := repo.ReplaceTags(&restored, tags); err != nil {
+			return err
+		}
+
+		after, err = loadCard(repo, card.ID)
+		if err != nil {
+			return err
+		}
+		return s.RecordChange(repo, before, after, editorID, model.RevisionRestore, &revision.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.redis != nil {
+		s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, card.ID))
+	}
+	return after, nil
+}
+
+// 从数据库加载卡片（带标签）
+func loadCard(repo *repository.LearningCardsRepository, id uint) (*model.LearningCard, error) {
+	cards, err := repo.FindByIDs([]uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, errors.New("卡片不存在")
+	}
+	return cards[0], nil
+}
+
+// 由卡片生成修订快照
+func snapshot(card *model.LearningCard) *model.CardRevision {
+	revision := &model.CardRevision{
+		CardID:   card.ID,
+		Title:    card.Title,
+		Content:  card.Content,
+		CardType: card.CardType,
+		Tags:     make([]model.RevisionTag, 0, len(card.Tags)),
+	}
+	for _, tag := range card.Tags {
+		revision.Tags = append(revision.Tags, model.RevisionTag{ID: tag.ID, Name: tag.Name})
+	}
+	sort.Slice(revision.Tags, func(i, j int) bool {
+		return revision.Tags[i].ID < revision.Tags[j].ID
+	})
+	return revision
+}
+
+// 比较修改前后卡片内容的字段
+func changedFields(before, after *model.LearningCard) []string {
+	var changed []string
+	if before.Title != after.Title {
+		changed = append(changed, "title")
+	}
+	if before.Content != after.Content {
+		changed = append(changed, "content")
+	}
+	if before.CardType != after.CardType {
+		changed = append(changed, "card_type")
+	}
+
+	beforeTags := snapshot(before).Tags
+	afterTags := snapshot(after).Tags
+	sameTags := len(beforeTags) == len(afterTags)
+	for i := 0; sameTags && i < len(beforeTags); i++ {
+		sameTags = beforeTags[i].ID == afterTags[i].ID
+	}
+	if !sameTags {
+		changed = append(changed, "tags")
+	}
+	return changed
+}
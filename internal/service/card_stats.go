@@ -0,0 +1,110 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/pkg/algorithm"
+	"errors"
+	"time"
+)
+
+// 读取卡片复习日志时每页的条数
+const cardStatsPageSize = 500
+
+// GetCardStats 统计单张卡片：复习次数、遗忘次数、平均耗时、间隔、预测的回忆概率以及按时间顺序的复习时间线
+func (s *LearningCardsService) GetCardStats(card *model.LearningCard) (*model.CardStats, error) {
+	if s.reviewLogsRepo == nil {
+		return nil, errors.New("不支持复习统计")
+	}
+
+	// 分页读取卡片的全部复习日志（按复习时间倒序）
+	var logs []*model.ReviewLog
+	for page := 1; ; page++ {
+		batch, total, err := s.reviewLogsRepo.FindByUserIDWithFilters(card.UserID, page, cardStatsPageSize, nil, nil, &card.ID)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, batch...)
+		if len(batch) < cardStatsPageSize || int64(len(logs)) >= total {
+			break
+		}
+	}
+
+	stats := &model.CardStats{
+		CardID:    card.ID,
+		CreatedAt: card.CreatedAt,
+		Timeline:  make([]*model.CardReviewEvent, 0, len(logs)),
+	}
+
+	prevTime := card.CreatedAt
+	for i := len(logs) - 1; i >= 0; i-- {
+		log := logs[i]
+		event := &model.CardReviewEvent{
+			ReviewLogID: log.ID,
+			ReviewTime:  log.ReviewTime,
+			Performance: log.Performance,
+			Duration:    log.Duration,
+			Kind:        log.Kind(),
+			ElapsedDays: log.ReviewTime.Sub(prevTime).Hours() / 24,
+			SessionID:   log.SessionID,
+		}
+		switch {
+		case log.NewCard:
+			zero := 0.0
+			event.IntervalBefore = &zero
+		case log.ScheduledDays > 0:
+			before := log.ScheduledDays
+			event.IntervalBefore = &before
+		}
+		// 上一次复习后安排的间隔就是这次复习前的计划间隔
+		if n := len(stats.Timeline); n > 0 {
+			setIntervalAfter(stats.Timeline[n-1], event.IntervalBefore)
+		}
+		stats.Timeline = append(stats.Timeline, event)
+		prevTime = log.ReviewTime
+
+		stats.TotalReviews++
+		stats.TotalSeconds += log.Duration
+		if log.Performance < model.PassPerformance && !log.NewCard {
+			stats.Lapses++
+		}
+	}
+
+	if stats.TotalReviews > 0 {
+		first := stats.Timeline[0].ReviewTime
+		stats.FirstSeen = &first
+		stats.AvgSeconds = float64(stats.TotalSeconds) / float64(stats.TotalReviews)
+		stats.PreviousInterval = stats.Timeline[len(stats.Timeline)-1].IntervalBefore
+	}
+
+	if card.ReviewCount > 0 {
+		interval := card.NextReview.Sub(card.LastReviewAt)
+		stats.CurrentInterval = interval.Hours() / 24
+		lastReview, nextReview := card.LastReviewAt, card.NextReview
+		stats.LastReview = &lastReview
+		stats.NextReview = &nextReview
+
+		now := algorithm.PredictRetention(card.LastReviewAt, card.NextReview, time.Now())
+		due := algorithm.Retrievability(interval, interval)
+		stats.RetrievabilityNow = &now
+		stats.RetrievabilityDue = &due
+
+		if n := len(stats.Timeline); n > 0 {
+			current := stats.CurrentInterval
+			setIntervalAfter(stats.Timeline[n-1], &current)
+		}
+	}
+
+	return stats, nil
+}
+
+// 设置复习后安排的间隔，前后间隔都已知时计算变化
+func setIntervalAfter(event *model.CardReviewEvent, after *float64) {
+	if after == nil {
+		return
+	}
+	event.IntervalAfter = after
+	if event.IntervalBefore != nil {
+		change := *after - *event.IntervalBefore
+		event.IntervalChange = &change
+	}
+}
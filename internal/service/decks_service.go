@@ -0,0 +1,458 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/cardquery"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type DecksService struct {
+	repo      *repository.DecksRepository
+	cardsRepo *repository.LearningCardsRepository
+	redis     *redis.Client
+}
+
+func NewDecksService(repo *repository.DecksRepository, cardsRepo *repository.LearningCardsRepository, redis *redis.Client) *DecksService {
+	return &DecksService{
+		repo:      repo,
+		cardsRepo: cardsRepo,
+		redis:     redis,
+	}
+}
+
+// 筛选卡组默认最多抽取的卡片数
+const defaultFilterLimit = 100
+
+// 获取用户的卡组树，每个卡组的数量包含其全部子卡组
+func (s *DecksService) GetDeckTree(userID uint) ([]*model.Deck, error) {
+	if _, err := s.repo.FindOrCreateDefault(userID); err != nil {
+		return nil, err
+	}
+	decks, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountCardsByDeck(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.Deck, len(decks))
+	for _, deck := range decks {
+		deck.Counts = &model.DeckCounts{}
+		byID[deck.ID] = deck
+	}
+	for _, c := range counts {
+		if deck, ok := byID[c.DeckID]; ok {
+			deck.Counts.Total, deck.Counts.New, deck.Counts.Due = c.Total, c.NewCount, c.DueCount
+		}
+	}
+
+	var roots []*model.Deck
+	for _, deck := range decks {
+		if parent, ok := byID[parentOf(deck)]; ok {
+			parent.Children = append(parent.Children, deck)
+		} else {
+			roots = append(roots, deck)
+		}
+	}
+	for _, root := range roots {
+		rollUpCounts(root)
+	}
+	return roots, nil
+}
+
+func parentOf(deck *model.Deck) uint {
+	if deck.ParentID == nil {
+		return 0
+	}
+	return *deck.ParentID
+}
+
+// 将子卡组的数量累加到父卡组
+func rollUpCounts(deck *model.Deck) {
+	for _, child := range deck.Children {
+		rollUpCounts(child)
+		deck.Counts.Total += child.Counts.Total
+		deck.Counts.New += child.Counts.New
+		deck.Counts.Due += child.Counts.Due
+	}
+}
+
+// 根据ID获取卡组
+func (s *DecksService) GetDeckByID(id uint) (*model.Deck, error) {
+	return s.repo.FindByID(id)
+}
+
+// 获取用户的默认卡组
+func (s *DecksService) GetDefaultDeck(userID uint) (*model.Deck, error) {
+	return s.repo.FindOrCreateDefault(userID)
+}
+
+// 创建卡组
+func (s *DecksService) CreateDeck(deck *model.Deck) error {
+	if deck.UserID == 0 {
+		return errors.New("用户ID不能为0")
+	}
+	if deck.Name == "" {
+		return errors.New("卡组名称不能为空")
+	}
+	if deck.ParentID != nil {
+		if err := s.checkParentDeck(deck.UserID, *deck.ParentID); err != nil {
+			return err
+		}
+	}
+	if err := s.checkPresetOwner(deck.UserID, deck.PresetID); err != nil {
+		return err
+	}
+	if err := s.checkSiblingName(deck); err != nil {
+		return err
+	}
+	return s.repo.Create(deck)
+}
+
+// 更新卡组名称和预设
+func (s *DecksService) UpdateDeck(deck *model.Deck) error {
+	if deck.Name == "" {
+		return errors.New("卡组名称不能为空")
+	}
+	if err := s.checkPresetOwner(deck.UserID, deck.PresetID); err != nil {
+		return err
+	}
+	if err := s.checkSiblingName(deck); err != nil {
+		return err
+	}
+	return s.repo.Update(deck)
+}
+
+// 移动卡组（连同子卡组）到新的父卡组下，parentID 为空表示移动到顶级
+func (s *DecksService) MoveDeck(deck *model.Deck, parentID *uint) error {
+	if parentID != nil {
+		if *parentID == deck.ID {
+			return errors.New("不能将卡组移动到自身下")
+		}
+		if err := s.checkParentDeck(deck.UserID, *parentID); err != nil {
+			return err
+		}
+		subtree, err := s.SubtreeIDs(deck.UserID, deck.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range subtree {
+			if id == *parentID {
+				return errors.New("不能将卡组移动到其子卡组下")
+			}
+		}
+	}
+	deck.ParentID = parentID
+	if err := s.checkSiblingName(deck); err != nil {
+		return err
+	}
+	return s.repo.Update(deck)
+}
+
+// 删除卡组：卡片移入父卡组（顶级卡组则移入默认卡组），子卡组上移一级
+func (s *DecksService) DeleteDeck(deck *model.Deck) error {
+	if deck.IsDefault {
+		return errors.New("不能删除默认卡组")
+	}
+	if deck.IsFiltered {
+		if err := s.EmptyFilteredDeck(deck); err != nil {
+			return err
+		}
+	}
+	moveTo := parentOf(deck)
+	if moveTo == 0 {
+		def, err := s.repo.FindOrCreateDefault(deck.UserID)
+		if err != nil {
+			return err
+		}
+		moveTo = def.ID
+	}
+	return s.repo.Delete(deck, moveTo)
+}
+
+// 获取卡组及其全部子卡组的ID
+func (s *DecksService) SubtreeIDs(userID, deckID uint) ([]uint, error) {
+	return s.repo.FindSubtreeIDs(userID, deckID)
+}
+
+// 获取卡组使用的调度选项
+func (s *DecksService) GetDeckPreset(deckID uint) (*model.DeckPreset, error) {
+	return s.repo.FindPresetForDeck(deckID)
+}
+
+// 获取卡组（含子卡组）今天的学习队列，按卡组预设的每日上限截取
+func (s *DecksService) GetStudyQueue(deck *model.Deck) ([]*model.LearningCard, error) {
+	// 筛选卡组不受每日上限限制，直接返回其中的全部卡片
+	if deck.IsFiltered {
+		order := ""
+		if deck.FilterQuery != nil {
+			order = deck.FilterQuery.Order
+		}
+		return s.cardsRepo.FindByFilteredDeck(deck.ID, order, deck.FilterLimit)
+	}
+
+	preset, err := s.repo.FindPresetForDeck(deck.ID)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.SubtreeIDs(deck.UserID, deck.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	reviews, newCards, err := s.repo.CountStudiedSince(ids, today)
+	if err != nil {
+		return nil, err
+	}
+
+	newLimit := preset.NewCardsPerDay - int(newCards)
+	reviewLimit := preset.MaxReviewsPerDay - int(reviews-newCards)
+	if newLimit < 0 {
+		newLimit = 0
+	}
+	if reviewLimit < 0 {
+		reviewLimit = 0
+	}
+	return s.repo.FindStudyCards(ids, now, reviewLimit, newLimit)
+}
+
+func (s *DecksService) checkDeckOwner(userID, deckID uint) error {
+	deck, err := s.repo.FindByID(deckID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("卡组不存在")
+		}
+		return err
+	}
+	if deck.UserID != userID {
+		return errors.New("无权限使用此卡组")
+	}
+	return nil
+}
+
+// 检查父卡组归属，筛选卡组不能包含子卡组
+func (s *DecksService) checkParentDeck(userID, parentID uint) error {
+	if err := s.checkDeckOwner(userID, parentID); err != nil {
+		return err
+	}
+	parent, err := s.repo.FindByID(parentID)
+	if err != nil {
+		return err
+	}
+	if parent.IsFiltered {
+		return errors.New("筛选卡组不能包含子卡组")
+	}
+	return nil
+}
+
+func (s *DecksService) checkPresetOwner(userID uint, presetID *uint) error {
+	if presetID == nil {
+		return nil
+	}
+	preset, err := s.repo.FindPresetByID(*presetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("调度选项预设不存在")
+		}
+		return err
+	}
+	if preset.UserID != userID {
+		return errors.New("无权限使用此调度选项预设")
+	}
+	return nil
+}
+
+// 检查同一父卡组下是否已有同名卡组
+func (s *DecksService) checkSiblingName(deck *model.Deck) error {
+	existing, err := s.repo.FindSibling(deck.UserID, deck.ParentID, deck.Name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != deck.ID {
+		return errors.New("同级卡组名称已存在")
+	}
+	return nil
+}
+
+// 创建筛选卡组并按筛选条件抽取卡片，返回抽取的卡片数
+func (s *DecksService) CreateFilteredDeck(deck *model.Deck) (int64, error) {
+	if deck.UserID == 0 {
+		return 0, errors.New("用户ID不能为0")
+	}
+	deck.IsFiltered = true
+	deck.ParentID = nil
+	if err := s.checkFilter(deck); err != nil {
+		return 0, err
+	}
+	if err := s.checkSiblingName(deck); err != nil {
+		return 0, err
+	}
+	if err := s.repo.Create(deck); err != nil {
+		return 0, err
+	}
+	return s.RebuildFilteredDeck(deck)
+}
+
+// 更新筛选卡组的筛选条件并重建
+func (s *DecksService) UpdateFilteredDeck(deck *model.Deck) (int64, error) {
+	if err := s.checkFilter(deck); err != nil {
+		return 0, err
+	}
+	if err := s.checkSiblingName(deck); err != nil {
+		return 0, err
+	}
+	if err := s.repo.UpdateFilter(deck); err != nil {
+		return 0, err
+	}
+	return s.RebuildFilteredDeck(deck)
+}
+
+// 重建筛选卡组：先送回已有卡片，再按筛选条件重新抽取，返回抽取的卡片数
+func (s *DecksService) RebuildFilteredDeck(deck *model.Deck) (int64, error) {
+	if err := s.EmptyFilteredDeck(deck); err != nil {
+		return 0, err
+	}
+
+	filter := *deck.FilterQuery
+	if len(filter.DeckIDs) > 0 {
+		// 展开为包含子卡组的卡组ID
+		var ids []uint
+		for _, id := range filter.DeckIDs {
+			subtree, err := s.repo.FindSubtreeIDs(deck.UserID, id)
+			if err != nil {
+				return 0, err
+			}
+			ids = append(ids, subtree...)
+		}
+		filter.DeckIDs = ids
+	}
+
+	cardIDs, err := s.cardsRepo.FindIDsByFilter(deck.UserID, &filter, time.Now(), deck.FilterLimit)
+	if err != nil {
+		return 0, err
+	}
+	pulled, err := s.repo.PullCards(deck.ID, cardIDs)
+	if err != nil {
+		return 0, err
+	}
+	s.clearCardsCache(cardIDs)
+	return pulled, nil
+}
+
+// 清空筛选卡组，卡片回到原卡组并恢复原有排程
+func (s *DecksService) EmptyFilteredDeck(deck *model.Deck) error {
+	if !deck.IsFiltered {
+		return errors.New("只有筛选卡组可以清空")
+	}
+	cardIDs, err := s.repo.FindCardIDs(deck.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ReturnCards(deck.ID, nil); err != nil {
+		return err
+	}
+	s.clearCardsCache(cardIDs)
+	return nil
+}
+
+// 校验筛选条件并补全默认值
+func (s *DecksService) checkFilter(deck *model.Deck) error {
+	if deck.Name == "" {
+		return errors.New("卡组名称不能为空")
+	}
+	if deck.FilterQuery == nil {
+		deck.FilterQuery = &model.CardFilter{}
+	}
+	filter := deck.FilterQuery
+	if _, err := cardquery.Parse(filter.Query); err != nil {
+		return err
+	}
+	for _, cardType := range filter.CardTypes {
+		if !cardType.IsValid() {
+			return errors.New("无效的卡片类型")
+		}
+	}
+	switch filter.Order {
+	case "", model.FilterOrderDue, model.FilterOrderRandom, model.FilterOrderAdded, model.FilterOrderDifficulty:
+	default:
+		return errors.New("无效的排序方式")
+	}
+	if filter.FailedWithinDays < 0 || filter.ReviewedWithinDays < 0 ||
+		(filter.DueWithinDays != nil && *filter.DueWithinDays < 0) {
+		return errors.New("天数不能为负数")
+	}
+	for _, id := range filter.DeckIDs {
+		if err := s.checkDeckOwner(deck.UserID, id); err != nil {
+			return err
+		}
+	}
+	if deck.FilterLimit <= 0 {
+		deck.FilterLimit = defaultFilterLimit
+	}
+	return nil
+}
+
+// 卡片所在卡组变化后清除缓存
+func (s *DecksService) clearCardsCache(cardIDs []uint) {
+	if s.redis == nil {
+		return
+	}
+	for _, id := range cardIDs {
+		key := fmt.Sprintf("%s%d", cardCacheKeyPrefix, id)
+		s.redis.Del(context.Background(), key)
+	}
+}
+
+// 创建调度选项预设
+func (s *DecksService) CreatePreset(preset *model.DeckPreset) error {
+	if preset.UserID == 0 {
+		return errors.New("用户ID不能为0")
+	}
+	if _, err := model.ParseLearningSteps(preset.LearningSteps); err != nil {
+		return err
+	}
+	if preset.MaxInterval == 0 {
+		preset.MaxInterval = model.DefaultDeckPreset.MaxInterval
+	}
+	return s.repo.CreatePreset(preset)
+}
+
+// 根据ID获取调度选项预设
+func (s *DecksService) GetPresetByID(id uint) (*model.DeckPreset, error) {
+	return s.repo.FindPresetByID(id)
+}
+
+// 根据用户ID获取调度选项预设列表
+func (s *DecksService) GetPresetsByUserID(userID uint) ([]*model.DeckPreset, error) {
+	return s.repo.FindPresetsByUserID(userID)
+}
+
+// 更新调度选项预设
+func (s *DecksService) UpdatePreset(preset *model.DeckPreset) error {
+	if _, err := model.ParseLearningSteps(preset.LearningSteps); err != nil {
+		return err
+	}
+	if preset.MaxInterval == 0 {
+		preset.MaxInterval = model.DefaultDeckPreset.MaxInterval
+	}
+	return s.repo.UpdatePreset(preset)
+}
+
+// 删除调度选项预设，使用该预设的卡组在同一事务中改用默认预设
+func (s *DecksService) DeletePreset(id uint) error {
+	return s.repo.DeletePreset(id)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/dedup"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// 默认的近似重复相似度阈值
+const DefaultDuplicateThreshold = 0.8
+
+// 加载用户全部卡片的重复检测信息，缺失的（如尚未回填的旧数据）在内存中补算
+func (s *LearningCardsService) loadFingerprints(userID uint) ([]*model.LearningCard, error) {
+	cards, err := s.repo.FindFingerprints(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, card := range cards {
+		if card.ContentHash == "" || dedup.Decode(card.MinHash) == nil {
+			card.UpdateFingerprint()
+		}
+	}
+	return cards, nil
+}
+
+// 检查新卡片在用户已有卡片中的完全重复和近似重复：
+// 完全重复按内容哈希索引查找，近似重复只比较与签名有相同分段哈希的候选卡片
+func (s *LearningCardsService) CheckDuplicates(card *model.LearningCard) (exact, similar []*model.DuplicateCandidate, err error) {
+	card.UpdateFingerprint()
+
+	same, err := s.repo.FindByContentHash(card.UserID, card.ContentHash, card.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, existing := range same {
+		exact = append(exact, &model.DuplicateCandidate{ID: existing.ID, Title: existing.Title, Similarity: 1})
+	}
+
+	sig := dedup.Decode(card.MinHash)
+	candidates, err := s.repo.FindBandCandidates(card.UserID, dedup.BandHashes(sig), card.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, existing := range candidates {
+		if existing.ContentHash == card.ContentHash {
+			continue
+		}
+		if sim := dedup.Similarity(sig, dedup.Decode(existing.MinHash)); sim >= DefaultDuplicateThreshold {
+			similar = append(similar, &model.DuplicateCandidate{ID: existing.ID, Title: existing.Title, Similarity: sim})
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	return exact, similar, nil
+}
+
+// 找出用户卡片中的重复聚类：先按内容哈希找完全重复，再按 MinHash 找近似重复
+func (s *LearningCardsService) GetDuplicateClusters(userID uint, threshold float64) ([]*model.DuplicateCluster, error) {
+	cards, err := s.loadFingerprints(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.LearningCard, len(cards))
+	byHash := make(map[string][]*model.LearningCard)
+	items := make([]dedup.Item, 0, len(cards))
+	for _, card := range cards {
+		byID[card.ID] = card
+		byHash[card.ContentHash] = append(byHash[card.ContentHash], card)
+		items = append(items, dedup.Item{ID: card.ID, Signature: dedup.Decode(card.MinHash)})
+	}
+
+	var clusters []*model.DuplicateCluster
+	for _, group := range byHash {
+		if len(group) < 2 {
+			continue
+		}
+		cluster := &model.DuplicateCluster{Kind: model.DuplicateExact}
+		for _, card := range group {
+			cluster.Cards = append(cluster.Cards, &model.DuplicateCandidate{ID: card.ID, Title: card.Title, Similarity: 1})
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	for _, members := range dedup.Cluster(items, threshold) {
+		// 成员全部完全相同的聚类已在上面列出
+		hash := byID[members[0].ID].ContentHash
+		allExact := true
+		for _, member := range members {
+			if byID[member.ID].ContentHash != hash {
+				allExact = false
+				break
+			}
+		}
+		if allExact {
+			continue
+		}
+		cluster := &model.DuplicateCluster{Kind: model.DuplicateNear}
+		for _, member := range members {
+			cluster.Cards = append(cluster.Cards, &model.DuplicateCandidate{
+				ID:         member.ID,
+				Title:      byID[member.ID].Title,
+				Similarity: member.Similarity,
+			})
+		}
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Kind != clusters[j].Kind {
+			return clusters[i].Kind == model.DuplicateExact
+		}
+		return clusters[i].Cards[0].ID < clusters[j].Cards[0].ID
+	})
+	return clusters, nil
+}
+
+// 合并卡片：保留复习记录最丰富的卡片（或指定的卡片），合并全部标签，其余卡片的复习日志转移到保留的卡片后删除
+func (s *LearningCardsService) MergeCards(userID uint, cardIDs []uint, keepID uint) (*model.LearningCard, error) {
+	seen := make(map[uint]bool, len(cardIDs))
+	var ids []uint
+	for _, id := range cardIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < 2 {
+		return nil, errors.New("至少需要两张不同的卡片")
+	}
+	if keepID != 0 && !seen[keepID] {
+		return nil, errors.New("保留的卡片必须在合并列表中")
+	}
+
+	cards, err := s.repo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) != len(ids) {
+		return nil, errors.New("卡片不存在")
+	}
+	for _, card := range cards {
+		if card.UserID != userID {
+			return nil, errors.New("无权限操作此卡片")
+		}
+	}
+
+	logCounts, err := s.repo.CountReviewLogsByCard(ids)
+	if err != nil {
+		return nil, err
+	}
+	keep := cards[0]
+	for _, card := range cards[1:] {
+		if (keepID != 0 && card.ID == keepID) || (keepID == 0 && richerHistory(card, keep, logCounts)) {
+			keep = card
+		}
+	}
+
+	// 合并标签
+	var tags []model.Tag
+	tagSeen := make(map[uint]bool)
+	var removeIDs []uint
+	for _, card := range cards {
+		for _, tag := range card.Tags {
+			if !tagSeen[tag.ID] {
+				tagSeen[tag.ID] = true
+				tags = append(tags, tag)
+			}
+		}
+		if card.ID != keep.ID {
+			removeIDs = append(removeIDs, card.ID)
+		}
+	}
+
+	// 合并标签属于内容修改，保留卡片的修订与合并在同一事务中写入
+	before := *keep
+	err = s.repo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		if err := repo.MergeCards(keep, removeIDs, tags); err != nil {
+			return err
+		}
+		if s.revisionsService != nil {
+			return s.revisionsService.RecordUpdates(repo, []*model.LearningCard{&before}, userID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.redis != nil {
+		for _, id := range ids {
+			s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, id))
+		}
+	}
+	keep.Tags = tags
+	return keep, nil
+}
+
+// a 的复习记录是否比 b 更丰富：复习日志更多，其次复习次数更多，再次创建更早
+func richerHistory(a, b *model.LearningCard, logCounts map[uint]int64) bool {
+	if logCounts[a.ID] != logCounts[b.ID] {
+		return logCounts[a.ID] > logCounts[b.ID]
+	}
+	if a.ReviewCount != b.ReviewCount {
+		return a.ReviewCount > b.ReviewCount
+	}
+	return a.ID < b.ID
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 到期预测的天数
+const (
+	DefaultForecastDays = 30
+	MaxForecastDays     = 365
+)
+
+// 估算复习用时：取最近 forecastHistoryDays 天的平均复习耗时，没有复习记录时使用 defaultReviewSeconds
+const (
+	forecastHistoryDays  = 90
+	defaultReviewSeconds = 10.0
+)
+
+// GetDueForecast 预测未来 days 天每天到期的卡片数、累计待复习数和预计用时，groupBy 可按标签或卡组分组
+func (s *ReviewLogsService) GetDueForecast(userID uint, days int, groupBy string) (*model.DueForecast, error) {
+	if days <= 0 || days > MaxForecastDays {
+		return nil, errors.New("预测天数必须在1到365之间")
+	}
+	names := make(map[uint]string)
+	switch groupBy {
+	case "":
+	case model.ForecastGroupTag:
+		if s.tagsRepo == nil {
+			return nil, errors.New("不支持按标签分组")
+		}
+		tags, err := s.tagsRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			names[tag.ID] = tag.Name
+		}
+	case model.ForecastGroupDeck:
+		if s.decksRepo == nil {
+			return nil, errors.New("不支持按卡组分组")
+		}
+		decks, err := s.decksRepo.FindByUserID(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, deck := range decks {
+			names[deck.ID] = deck.Name
+		}
+	default:
+		return nil, errors.New("不支持的分组方式")
+	}
+
+	now := time.Now()
+	loc := userLocation(s.userRepo, userID)
+	today := startOfDay(now, loc)
+	// 各天零点按用户时区分别计算，夏令时切换的日子不是 24 小时
+	bounds := make([]time.Time, days+1)
+	for i := range bounds {
+		bounds[i] = today.AddDate(0, 0, i)
+	}
+
+	counts, err := s.repo.CountDueByDay(userID, bounds, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	avgSeconds, err := s.averageReviewSeconds(userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, days)
+	for i := range dates {
+		dates[i] = bounds[i].Format("2006-01-02")
+	}
+
+	type series struct {
+		overdue int64
+		due     []int64
+	}
+	newSeries := func() *series { return &series{due: make([]int64, days)} }
+	total := newSeries()
+	groups := make(map[uint]*series)
+	// 今天之前到期的计入已过期
+	add := func(g *series, count repository.DueCount) {
+		if count.Day < 0 {
+			g.overdue += count.Count
+		} else if count.Day < days {
+			g.due[count.Day] += count.Count
+		}
+	}
+	if groupBy != "" {
+		for _, count := range counts {
+			g, ok := groups[count.GroupID]
+			if !ok {
+				g = newSeries()
+				groups[count.GroupID] = g
+			}
+			add(g, count)
+		}
+	}
+	// 按标签分组时同一张卡片可能计入多个分组，总数单独统计
+	if groupBy == model.ForecastGroupTag {
+		if counts, err = s.repo.CountDueByDay(userID, bounds, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, count := range counts {
+		add(total, count)
+	}
+
+	toDays := func(g *series) []*model.DueForecastDay {
+		result := make([]*model.DueForecastDay, days)
+		backlog := g.overdue
+		for i, due := range g.due {
+			backlog += due
+			result[i] = &model.DueForecastDay{
+				Date:           dates[i],
+				Due:            due,
+				Backlog:        backlog,
+				Minutes:        float64(due) * avgSeconds / 60,
+				BacklogMinutes: float64(backlog) * avgSeconds / 60,
+			}
+		}
+		return result
+	}
+
+	forecast := &model.DueForecast{
+		Days:             days,
+		Timezone:         loc.String(),
+		AvgReviewSeconds: avgSeconds,
+		Overdue:          total.overdue,
+		Forecast:         toDays(total),
+		GroupBy:          groupBy,
+	}
+	if groupBy != "" {
+		forecast.Groups = make([]*model.DueForecastGroup, 0, len(groups))
+		for id, g := range groups {
+			name := names[id]
+			if id == 0 && groupBy == model.ForecastGroupTag {
+				name = "无标签"
+			}
+			forecast.Groups = append(forecast.Groups, &model.DueForecastGroup{
+				ID:      id,
+				Name:    name,
+				Overdue: g.overdue,
+				Days:    toDays(g),
+			})
+		}
+		sort.Slice(forecast.Groups, func(i, j int) bool {
+			return forecast.Groups[i].Name < forecast.Groups[j].Name
+		})
+	}
+	return forecast, nil
+}
+
+// 最近一段时间内每次复习的平均耗时（秒）
+func (s *ReviewLogsService) averageReviewSeconds(userID uint, now time.Time) (float64, error) {
+	since := now.AddDate(0, 0, -forecastHistoryDays)
+	reviews, err := s.repo.CountReviewsByTimeRange(userID, since, now)
+	if err != nil {
+		return 0, err
+	}
+	if reviews == 0 {
+		return defaultReviewSeconds, nil
+	}
+	seconds, err := s.repo.GetTotalDuration(userID, since, now)
+	if err != nil {
+		return 0, err
+	}
+	if seconds == 0 {
+		return defaultReviewSeconds, nil
+	}
+	return float64(seconds) / float64(reviews), nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 没有设置每日目标时使用的默认目标
+const (
+	defaultGoalType   = model.GoalTypeCards
+	defaultGoalTarget = 20
+)
+
+// 冻结天数规则：连续完成目标每满 freezeEarnDays 天获得一天冻结，最多保留 maxStreakFreezes 天
+const (
+	freezeEarnDays   = 7
+	maxStreakFreezes = 2
+)
+
+const goalDateLayout = "2006-01-02"
+
+type GoalsService struct {
+	repo           *repository.GoalsRepository
+	reviewLogsRepo *repository.ReviewLogsRepository
+	userRepo       *repository.UserRepository
+}
+
+func NewGoalsService(repo *repository.GoalsRepository, reviewLogsRepo *repository.ReviewLogsRepository, userRepo *repository.UserRepository) *GoalsService {
+	return &GoalsService{
+		repo:           repo,
+		reviewLogsRepo: reviewLogsRepo,
+		userRepo:       userRepo,
+	}
+}
+
+// 获取用户的每日目标，没有设置时返回未保存的默认目标
+func (s *GoalsService) getGoal(userID uint) (*model.StudyGoal, error) {
+	goal, err := s.repo.FindGoal(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &model.StudyGoal{UserID: userID, GoalType: defaultGoalType, Target: defaultGoalTarget}, nil
+	}
+	return goal, err
+}
+
+// GetGoalStatus 获取每日目标、今天的进度和连续天数
+func (s *GoalsService) GetGoalStatus(userID uint) (*model.GoalStatus, error) {
+	goal, err := s.getGoal(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.goalStatus(goal)
+}
+
+// UpdateGoal 设置每日目标，连续天数不受影响
+func (s *GoalsService) UpdateGoal(userID uint, req *model.UpdateGoalRequest) (*model.GoalStatus, error) {
+	goal, err := s.getGoal(userID)
+	if err != nil {
+		return nil, err
+	}
+	goal.GoalType = req.GoalType
+	goal.Target = req.Target
+	if err := s.repo.SaveGoal(goal); err != nil {
+		return nil, err
+	}
+	return s.goalStatus(goal)
+}
+
+func (s *GoalsService) goalStatus(goal *model.StudyGoal) (*model.GoalStatus, error) {
+	loc := userLocation(s.userRepo, goal.UserID)
+	today := startOfDay(time.Now(), loc)
+
+	progress, err := s.progress(goal, today)
+	if err != nil {
+		return nil, err
+	}
+	reviewStreak, err := s.reviewLogsRepo.GetReviewStreak(goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	streak := &model.StreakStatus{
+		Current:      goal.CurrentStreak,
+		Longest:      goal.LongestStreak,
+		LastGoalDate: goal.LastGoalDate,
+		Freezes:      goal.Freezes,
+		FreezesUsed:  goal.FreezesUsed,
+		ReviewStreak: reviewStreak,
+	}
+	// 今天尚未结束，只有今天之前漏掉的天数超过冻结天数时连续才中断
+	if missed := missedDays(goal, today, loc); missed > goal.Freezes {
+		streak.Current = 0
+	} else if missed > 0 {
+		streak.PendingDays = missed
+	}
+
+	return &model.GoalStatus{Progress: progress, Streak: streak}, nil
+}
+
+// 计算 day 这一天的目标完成情况
+func (s *GoalsService) progress(goal *model.StudyGoal, day time.Time) (*model.GoalProgress, error) {
+	end := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	progress := &model.GoalProgress{
+		Date:     day.Format(goalDateLayout),
+		GoalType: goal.GoalType,
+		Target:   goal.Target,
+	}
+
+	if goal.GoalType == model.GoalTypeMinutes {
+		seconds, err := s.reviewLogsRepo.GetTotalDuration(goal.UserID, day, end)
+		if err != nil {
+			return nil, err
+		}
+		progress.Done = seconds / 60
+	} else {
+		count, err := s.reviewLogsRepo.CountReviewsByTimeRange(goal.UserID, day, end)
+		if err != nil {
+			return nil, err
+		}
+		progress.Done = int(count)
+	}
+
+	progress.Completed = progress.Done >= goal.Target
+	progress.Percent = math.Min(float64(progress.Done)/float64(goal.Target), 1)
+	return progress, nil
+}
+
+// 上次完成目标之后、today 之前漏掉的天数，从未完成过目标时为 0
+func missedDays(goal *model.StudyGoal, today time.Time, loc *time.Location) int {
+	if goal.LastGoalDate == "" {
+		return 0
+	}
+	last, err := time.ParseInLocation(goalDateLayout, goal.LastGoalDate, loc)
+	if err != nil {
+		return 0
+	}
+	// 按日历天数计算，四舍五入以免夏令时切换造成误差
+	days := int(math.Round(today.Sub(last).Hours()/24)) - 1
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// 今天完成目标时更新连续天数：漏掉的天数不超过冻结天数时消耗冻结天数保持连续，否则重新开始
+func completeGoal(goal *model.StudyGoal, today time.Time, loc *time.Location) {
+	missed := missedDays(goal, today, loc)
+	switch {
+	case goal.LastGoalDate == "":
+		goal.CurrentStreak = 1
+	case missed <= goal.Freezes:
+		goal.Freezes -= missed
+		goal.FreezesUsed += missed
+		goal.CurrentStreak++
+	default:
+		goal.CurrentStreak = 1
+	}
+
+	if goal.CurrentStreak%freezeEarnDays == 0 && goal.Freezes < maxStreakFreezes {
+		goal.Freezes++
+	}
+	if goal.CurrentStreak > goal.LongestStreak {
+		goal.LongestStreak = goal.CurrentStreak
+	}
+	goal.LastGoalDate = today.Format(goalDateLayout)
+}
+
+// HandleReview 在用户完成一次复习后调用：检查今天的目标是否刚刚完成并更新连续天数，
+// 然后把复习事件（以及完成目标事件）交给成就规则检查，返回新解锁的成就
+func (s *GoalsService) HandleReview(userID uint) ([]*model.UserAchievement, error) {
+	goal, err := s.getGoal(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := userLocation(s.userRepo, userID)
+	today := startOfDay(time.Now(), loc)
+	events := []string{EventReview}
+
+	if goal.LastGoalDate != today.Format(goalDateLayout) {
+		progress, err := s.progress(goal, today)
+		if err != nil {
+			return nil, err
+		}
+		if progress.Completed {
+			completeGoal(goal, today, loc)
+			if err := s.repo.SaveGoal(goal); err != nil {
+				return nil, err
+			}
+			events = append(events, EventGoalMet)
+		}
+	}
+
+	return s.evaluateAchievements(goal, events)
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/pkg/markdown"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Markdown导入的限制
+const (
+	maxMarkdownFiles    = 10000   // 单次导入的最大文件数
+	maxMarkdownFileSize = 5 << 20 // 单个文件的最大字节数
+)
+
+// 问题修改过的卡片与已导入卡片的最低相似度（问题和答案的平均值）
+const editedCardSimilarity = 0.5
+
+// 待写入的Markdown卡片
+type markdownCard struct {
+	file string
+	card markdown.Card
+	tags []string
+}
+
+// ImportMarkdown 导入zip打包的Markdown笔记库，按来源锚点更新已导入过的卡片
+func (s *ImportService) ImportMarkdown(userID uint, r io.ReaderAt, size int64, opts *model.MarkdownImportOptions) (*model.ImportResult, error) {
+	if userID == 0 {
+		return nil, errors.New("用户ID不能为0")
+	}
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("无效的zip文件")
+	}
+
+	deckID, err := s.importDeckID(userID, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &markdownImport{
+		service: s,
+		userID:  userID,
+		deckID:  deckID,
+		opts:    opts,
+		result:  &model.ImportResult{DryRun: opts.DryRun, Errors: []model.ImportRowError{}},
+		tags:    s.newTagResolver(userID, opts.DryRun),
+	}
+
+	files := 0
+	for _, f := range zr.File {
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(name), ".md") || isHiddenPath(name) {
+			continue
+		}
+		files++
+		if files > maxMarkdownFiles {
+			return nil, fmt.Errorf("文件数量不能超过%d个", maxMarkdownFiles)
+		}
+		if f.UncompressedSize64 > maxMarkdownFileSize {
+			appendImportError(imp.result, model.ImportRowError{File: name, Message: "文件过大"})
+			continue
+		}
+
+		data, err := readZipFile(f)
+		if err != nil {
+			appendImportError(imp.result, model.ImportRowError{File: name, Message: err.Error()})
+			continue
+		}
+		if err := imp.addNote(markdown.Parse(name, data)); err != nil {
+			return nil, err
+		}
+	}
+	if err := imp.flush(); err != nil {
+		return nil, err
+	}
+
+	return imp.result, nil
+}
+
+// 以 . 开头的目录或文件（如 .obsidian、.trash）不导入
+func isHiddenPath(name string) bool {
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") && part != "." {
+			return true
+		}
+	}
+	return false
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	// 不信任zip头中的大小，读取时再次限制
+	data, err := io.ReadAll(io.LimitReader(rc, maxMarkdownFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxMarkdownFileSize {
+		return nil, errors.New("文件过大")
+	}
+	return data, nil
+}
+
+// 一次Markdown导入过程的状态
+type markdownImport struct {
+	service *ImportService
+	userID  uint
+	deckID  uint
+	opts    *model.MarkdownImportOptions
+	result  *model.ImportResult
+	tags    *tagResolver
+	batch   []markdownCard
+}
+
+// 将文件中的卡片加入批次，文件夹路径和文件级标签附加到每张卡片。
+// 同一文件的卡片总是在同一批次中处理，批次只在文件之间写入
+func (imp *markdownImport) addNote(note *markdown.Note) error {
+	var fileTags []string
+	if dir := path.Dir(note.Path); imp.opts.FolderTags && dir != "." {
+		fileTags = append(fileTags, folderTag(dir))
+	}
+	fileTags = append(fileTags, hierarchicalTags(note.Tags)...)
+
+	for _, card := range note.Cards {
+		imp.result.TotalRows++
+		tags := append(append([]string{}, fileTags...), hierarchicalTags(card.Tags)...)
+		if name := longTagName(tags); name != "" {
+			appendImportError(imp.result, model.ImportRowError{File: note.Path, Row: card.Line, Field: "tags", Message: fmt.Sprintf("标签名称过长: %s", name)})
+			continue
+		}
+		imp.batch = append(imp.batch, markdownCard{file: note.Path, card: card, tags: uniqueNames(tags)})
+	}
+	if len(imp.batch) >= importBatchSize {
+		return imp.flush()
+	}
+	return nil
+}
+
+// 文件夹路径作为层级标签，过长时只保留最后一级
+func folderTag(dir string) string {
+	tag := strings.ReplaceAll(dir, "/", model.TagSeparator)
+	if utf8.RuneCountInString(tag) <= model.MaxTagNameLength {
+		return tag
+	}
+	return path.Base(dir)
+}
+
+// 笔记中的嵌套标签（如 #lang/go）转换为层级标签，去掉空的层级
+func hierarchicalTags(names []string) []string {
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		var parts []string
+		for _, part := range strings.Split(name, "/") {
+			if part = strings.TrimSpace(part); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			tags = append(tags, strings.Join(parts, model.TagSeparator))
+		}
+	}
+	return tags
+}
+
+func longTagName(names []string) string {
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > model.MaxTagNameLength {
+			return name
+		}
+	}
+	return ""
+}
+
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// 按来源锚点查找已导入的卡片，存在则更新，否则创建；回收站中的卡片跳过，不会重复创建
+func (imp *markdownImport) flush() error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+	defer func() { imp.batch = imp.batch[:0] }()
+
+	matched, err := imp.matchExisting()
+	if err != nil {
+		return err
+	}
+
+	var toCreate, toUpdate []*model.LearningCard
+	now := time.Now()
+	for i, item := range imp.batch {
+		card, ok := matched[i]
+		if ok && card.DeletedAt.Valid {
+			imp.result.Skipped++
+			continue
+		}
+
+		tags, err := imp.tags.resolve(item.tags)
+		if err != nil {
+			return err
+		}
+		title := truncateRunes(item.card.Front, maxTitleLength)
+		cardType := model.CardType(item.card.Kind)
+
+		if ok {
+			card.Title = title
+			card.Content = item.card.Back
+			card.CardType = cardType
+			card.SourceAnchor = item.card.Anchor
+			card.Tags = tags
+			toUpdate = append(toUpdate, card)
+			imp.result.Updated++
+			continue
+		}
+
+		card = &model.LearningCard{
+			UserID:       imp.userID,
+			DeckID:       imp.deckID,
+			Title:        title,
+			Content:      item.card.Back,
+			CardType:     cardType,
+			SourceAnchor: item.card.Anchor,
+			Tags:         tags,
+		}
+		initReviewSchedule(card, now)
+		toCreate = append(toCreate, card)
+		imp.result.Created++
+	}
+
+	if imp.opts.DryRun {
+		return nil
+	}
+	return imp.service.saveBatch(toCreate, toUpdate)
+}
+
+// 为批次中的卡片找到已导入的卡片（包括回收站中的），返回批次下标到卡片的映射。
+// 先按锚点精确匹配；同一文件中剩下的卡片再按内容相似度匹配，这样修改过问题的卡片也能找回
+func (imp *markdownImport) matchExisting() (map[int]*model.LearningCard, error) {
+	byFile := make(map[string][]int) // 锚点前缀 -> 批次下标
+	var prefixes []string
+	for i, item := range imp.batch {
+		prefix := markdown.AnchorPrefix(item.file)
+		if _, ok := byFile[prefix]; !ok {
+			prefixes = append(prefixes, prefix)
+		}
+		byFile[prefix] = append(byFile[prefix], i)
+	}
+	cards, err := imp.service.cardsRepo.FindBySourcePrefixes(imp.userID, prefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	byAnchor := make(map[string]*model.LearningCard, len(cards))
+	for _, card := range cards {
+		byAnchor[card.SourceAnchor] = card
+	}
+
+	matched := make(map[int]*model.LearningCard)
+	claimed := make(map[uint]bool)
+	for i, item := range imp.batch {
+		if card, ok := byAnchor[item.card.Anchor]; ok {
+			matched[i] = card
+			claimed[card.ID] = true
+		}
+	}
+
+	for _, prefix := range prefixes {
+		var pending []int
+		var cardsLeft []markdown.Card
+		for _, i := range byFile[prefix] {
+			if _, ok := matched[i]; !ok {
+				pending = append(pending, i)
+				cardsLeft = append(cardsLeft, imp.batch[i].card)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+		var candidates []*model.LearningCard
+		var imported []markdown.Imported
+		for _, card := range cards {
+			if !claimed[card.ID] && fileOfAnchor(card.SourceAnchor, prefixes) == prefix {
+				candidates = append(candidates, card)
+				imported = append(imported, markdown.Imported{Front: card.Title, Back: card.Content})
+			}
+		}
+		for i, j := range markdown.MatchEdited(cardsLeft, imported, editedCardSimilarity) {
+			matched[pending[i]] = candidates[j]
+			claimed[candidates[j].ID] = true
+		}
+	}
+	return matched, nil
+}
+
+// 锚点所属文件的前缀，多个前缀匹配时取最长的
+func fileOfAnchor(anchor string, prefixes []string) string {
+	best := ""
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(anchor, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return best
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,574 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+type ImportService struct {
+	cardsRepo *repository.LearningCardsRepository
+	tagsRepo  *repository.TagsRepository
+	decksRepo *repository.DecksRepository
+	redis     *redis.Client
+
+	revisionsService *CardRevisionsService
+}
+
+func NewImportService(cardsRepo *repository.LearningCardsRepository, tagsRepo *repository.TagsRepository, redis *redis.Client) *ImportService {
+	return &ImportService{
+		cardsRepo: cardsRepo,
+		tagsRepo:  tagsRepo,
+		redis:     redis,
+	}
+}
+
+// SetRevisionsService 设置修订历史服务，设置后导入时更新的卡片会记录修订
+func (s *ImportService) SetRevisionsService(revisionsService *CardRevisionsService) {
+	s.revisionsService = revisionsService
+}
+
+// SetDecksRepository 设置卡组仓库，导入的卡片放入用户的默认卡组
+func (s *ImportService) SetDecksRepository(decksRepo *repository.DecksRepository) {
+	s.decksRepo = decksRepo
+}
+
+// 导入的卡片所属的卡组，试运行时不创建默认卡组
+func (s *ImportService) importDeckID(userID uint, dryRun bool) (uint, error) {
+	if s.decksRepo == nil || dryRun {
+		return 0, nil
+	}
+	deck, err := s.decksRepo.FindOrCreateDefault(userID)
+	if err != nil {
+		return 0, err
+	}
+	return deck.ID, nil
+}
+
+// 导入相关的常量
+const (
+	importBatchSize    = 500  // 每批写入的行数
+	maxImportErrors    = 1000 // 最多返回的错误明细条数
+	maxTitleLength     = 255  // 标题最大长度
+	defaultTagSplitter = ";"  // 默认标签分隔符
+)
+
+// 可映射的卡片字段
+var importFields = []string{"title", "content", "card_type", "tags"}
+
+// 待导入的一行数据
+type importRow struct {
+	line     int
+	title    string
+	content  string
+	cardType model.CardType
+	tags     []string
+	hasType  bool // 卡片类型列有值，为 false 时 cardType 是默认类型
+	hasTags  bool // 映射了标签列，为 false 时不修改已有卡片的标签
+}
+
+// ImportCSV 以流式方式导入CSV/TSV文件，按批次校验并写入
+func (s *ImportService) ImportCSV(userID uint, r io.Reader, opts *model.CSVImportOptions) (*model.ImportResult, error) {
+	if userID == 0 {
+		return nil, errors.New("用户ID不能为0")
+	}
+	if err := normalizeCSVOptions(opts); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(skipBOM(r))
+	reader.Comma = opts.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	result := &model.ImportResult{DryRun: opts.DryRun, Errors: []model.ImportRowError{}}
+
+	first, err := reader.Read()
+	if err == io.EOF {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	hasHeader := detectHeader(first, opts)
+	columns, err := resolveColumns(first, hasHeader, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	deckID, err := s.importDeckID(userID, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &csvImport{
+		service:   s,
+		userID:    userID,
+		deckID:    deckID,
+		opts:      opts,
+		result:    result,
+		tags:      s.newTagResolver(userID, opts.DryRun),
+		titleKeys: newTitleFolder(),
+		created:   make(map[string]*model.LearningCard),
+		updated:   make(map[uint]bool),
+	}
+
+	line := 1
+	if !hasHeader {
+		imp.addRecord(line, first, columns)
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			imp.addError(line, "", fmt.Sprintf("解析失败: %v", err))
+			continue
+		}
+		imp.addRecord(line, record, columns)
+		if len(imp.batch) >= importBatchSize {
+			if err := imp.flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := imp.flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// 校验并补全导入选项
+func normalizeCSVOptions(opts *model.CSVImportOptions) error {
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.Delimiter == '"' || opts.Delimiter == '\r' || opts.Delimiter == '\n' {
+		return errors.New("无效的分隔符")
+	}
+	if opts.TagSeparator == "" {
+		opts.TagSeparator = defaultTagSplitter
+	}
+	if opts.DefaultCardType == "" {
+		opts.DefaultCardType = model.BasicCard
+	}
+	if !opts.DefaultCardType.IsValid() {
+		return errors.New("无效的默认卡片类型")
+	}
+	switch opts.DuplicatePolicy {
+	case "":
+		opts.DuplicatePolicy = model.DuplicateSkip
+	case model.DuplicateSkip, model.DuplicateUpdate, model.DuplicateCreate:
+	default:
+		return errors.New("无效的重复处理策略")
+	}
+	for field := range opts.Mapping {
+		if !isImportField(field) {
+			return fmt.Errorf("未知的映射字段: %s", field)
+		}
+	}
+	return nil
+}
+
+// 跳过UTF-8 BOM（Excel导出的CSV常带BOM）
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(3); err == nil && b[0] == 0xEF && b[1] == 0xBB && b[2] == 0xBF {
+		br.Discard(3)
+	}
+	return br
+}
+
+func isImportField(field string) bool {
+	for _, f := range importFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// 自动检测首行是否为表头：首行任一单元格与映射列名或字段名相同即视为表头
+func detectHeader(first []string, opts *model.CSVImportOptions) bool {
+	if opts.HasHeader != nil {
+		return *opts.HasHeader
+	}
+	names := make(map[string]bool)
+	for _, field := range importFields {
+		names[field] = true
+	}
+	for _, col := range opts.Mapping {
+		if _, err := strconv.Atoi(col); err != nil {
+			names[strings.ToLower(strings.TrimSpace(col))] = true
+		}
+	}
+	for _, cell := range first {
+		if names[strings.ToLower(strings.TrimSpace(cell))] {
+			return true
+		}
+	}
+	return false
+}
+
+// 将字段映射解析为列序号，未映射的字段为-1
+func resolveColumns(first []string, hasHeader bool, mapping map[string]string) (map[string]int, error) {
+	columns := make(map[string]int)
+	for _, field := range importFields {
+		columns[field] = -1
+	}
+
+	// 未提供映射时：有表头按字段名匹配，无表头按默认顺序
+	if len(mapping) == 0 {
+		for i, field := range importFields {
+			if !hasHeader {
+				columns[field] = i
+				continue
+			}
+			for j, cell := range first {
+				if strings.EqualFold(strings.TrimSpace(cell), field) {
+					columns[field] = j
+					break
+				}
+			}
+		}
+	} else {
+		for field, col := range mapping {
+			if idx, err := strconv.Atoi(col); err == nil {
+				if idx < 0 {
+					return nil, fmt.Errorf("字段 %s 的列序号无效", field)
+				}
+				columns[field] = idx
+				continue
+			}
+			if !hasHeader {
+				return nil, fmt.Errorf("文件没有表头，字段 %s 必须映射到列序号", field)
+			}
+			found := false
+			for j, cell := range first {
+				if strings.EqualFold(strings.TrimSpace(cell), strings.TrimSpace(col)) {
+					columns[field] = j
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("表头中找不到列: %s", col)
+			}
+		}
+	}
+
+	if columns["title"] < 0 || columns["content"] < 0 {
+		return nil, errors.New("必须映射 title 和 content 列")
+	}
+	return columns, nil
+}
+
+// 一次导入过程的状态
+type csvImport struct {
+	service   *ImportService
+	userID    uint
+	deckID    uint
+	opts      *model.CSVImportOptions
+	result    *model.ImportResult
+	batch     []importRow
+	tags      *tagResolver
+	titleKeys *titleFolder
+	created   map[string]*model.LearningCard // 本次导入新建的卡片，键为折叠后的标题，用于文件内去重
+	updated   map[uint]bool                  // 本次导入已计入更新数的卡片ID
+}
+
+// 标题查重的键：按数据库的排序规则（utf8mb4_unicode_ci）折叠标题，忽略大小写、重音、全半角和末尾空格，
+// 与 FindByTitles 的 IN 查询判断相等的方式一致
+type titleFolder struct {
+	collator *collate.Collator
+	buf      collate.Buffer
+}
+
+func newTitleFolder() *titleFolder {
+	return &titleFolder{
+		collator: collate.New(language.Und, collate.IgnoreCase, collate.IgnoreDiacritics, collate.IgnoreWidth),
+	}
+}
+
+func (f *titleFolder) key(title string) string {
+	key := string(f.collator.KeyFromString(&f.buf, strings.TrimRight(title, " ")))
+	f.buf.Reset()
+	return key
+}
+
+func (imp *csvImport) addError(line int, field, message string) {
+	appendImportError(imp.result, model.ImportRowError{Row: line, Field: field, Message: message})
+}
+
+// 记录导入错误，超出上限的错误只计数不返回明细
+func appendImportError(result *model.ImportResult, e model.ImportRowError) {
+	result.Failed++
+	if len(result.Errors) >= maxImportErrors {
+		result.ErrorsTruncated = true
+		return
+	}
+	result.Errors = append(result.Errors, e)
+}
+
+// 校验一行记录并加入当前批次
+func (imp *csvImport) addRecord(line int, record []string, columns map[string]int) {
+	imp.result.TotalRows++
+
+	cell := func(field string) string {
+		idx := columns[field]
+		if idx < 0 || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	row := importRow{
+		line:     line,
+		title:    cell("title"),
+		content:  cell("content"),
+		cardType: model.CardType(strings.ToLower(cell("card_type"))),
+		hasTags:  columns["tags"] >= 0,
+	}
+	row.hasType = row.cardType != ""
+
+	if row.title == "" {
+		imp.addError(line, "title", "标题不能为空")
+		return
+	}
+	if len([]rune(row.title)) > maxTitleLength {
+		imp.addError(line, "title", fmt.Sprintf("标题长度不能超过%d个字符", maxTitleLength))
+		return
+	}
+	if row.content == "" {
+		imp.addError(line, "content", "内容不能为空")
+		return
+	}
+	if row.cardType == "" {
+		row.cardType = imp.opts.DefaultCardType
+	}
+	if !row.cardType.IsValid() {
+		imp.addError(line, "card_type", fmt.Sprintf("无效的卡片类型: %s", row.cardType))
+		return
+	}
+	for _, name := range strings.Split(cell("tags"), imp.opts.TagSeparator) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if len([]rune(name)) > 50 {
+			imp.addError(line, "tags", fmt.Sprintf("标签名称过长: %s", name))
+			return
+		}
+		row.tags = append(row.tags, name)
+	}
+
+	imp.batch = append(imp.batch, row)
+}
+
+// 处理当前批次：查重、解析标签并写入数据库
+func (imp *csvImport) flush() error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+	defer func() { imp.batch = imp.batch[:0] }()
+
+	existing := make(map[string]*model.LearningCard) // 已有的卡片，键为折叠后的标题
+	if imp.opts.DuplicatePolicy != model.DuplicateCreate {
+		titles := make([]string, 0, len(imp.batch))
+		for _, row := range imp.batch {
+			titles = append(titles, row.title)
+		}
+		cards, err := imp.service.cardsRepo.FindByTitles(imp.userID, titles)
+		if err != nil {
+			return err
+		}
+		for _, card := range cards {
+			existing[imp.titleKeys.key(card.Title)] = card
+		}
+	}
+
+	var toCreate, toUpdate []*model.LearningCard
+	updating := make(map[uint]bool) // 本批次待更新的卡片ID
+	now := time.Now()
+	for _, row := range imp.batch {
+		key := imp.titleKeys.key(row.title)
+
+		if imp.opts.DuplicatePolicy != model.DuplicateCreate {
+			// 文件内重复的标题：跳过时保留第一行；更新时合并到本次导入新建的卡片，只计一次新建
+			if card, ok := imp.created[key]; ok {
+				if imp.opts.DuplicatePolicy == model.DuplicateSkip {
+					imp.result.Skipped++
+					continue
+				}
+				if err := imp.applyRow(card, row); err != nil {
+					return err
+				}
+				if card.ID != 0 && !updating[card.ID] {
+					// 卡片在之前的批次中已经写入
+					updating[card.ID] = true
+					toUpdate = append(toUpdate, card)
+				}
+				continue
+			}
+
+			if card, ok := existing[key]; ok {
+				if imp.opts.DuplicatePolicy == model.DuplicateSkip {
+					imp.result.Skipped++
+					continue
+				}
+				if err := imp.applyRow(card, row); err != nil {
+					return err
+				}
+				if !updating[card.ID] {
+					updating[card.ID] = true
+					toUpdate = append(toUpdate, card)
+				}
+				if !imp.updated[card.ID] {
+					imp.updated[card.ID] = true
+					imp.result.Updated++
+				}
+				continue
+			}
+		}
+
+		tags, err := imp.tags.resolve(row.tags)
+		if err != nil {
+			return err
+		}
+		card := &model.LearningCard{
+			UserID:   imp.userID,
+			DeckID:   imp.deckID,
+			Title:    row.title,
+			Content:  row.content,
+			CardType: row.cardType,
+			Tags:     tags,
+		}
+		initReviewSchedule(card, now)
+		toCreate = append(toCreate, card)
+		if imp.opts.DuplicatePolicy != model.DuplicateCreate {
+			imp.created[key] = card
+		}
+		imp.result.Created++
+	}
+
+	if imp.opts.DryRun {
+		return nil
+	}
+	return imp.service.saveBatch(toCreate, toUpdate)
+}
+
+// 用重复标题的行更新卡片：内容总是更新，卡片类型和标签只在该行提供时更新，
+// 没有映射标签列时 Tags 保持 nil，写入时不会清空卡片已有的标签
+func (imp *csvImport) applyRow(card *model.LearningCard, row importRow) error {
+	card.Content = row.content
+	if row.hasType {
+		card.CardType = row.cardType
+	}
+	if row.hasTags {
+		tags, err := imp.tags.resolve(row.tags)
+		if err != nil {
+			return err
+		}
+		card.Tags = tags
+	}
+	return nil
+}
+
+// 按名称解析标签，同一次导入中缓存已解析的标签
+type tagResolver struct {
+	service *ImportService
+	userID  uint
+	dryRun  bool
+	cache   map[string]*model.Tag
+}
+
+func (s *ImportService) newTagResolver(userID uint, dryRun bool) *tagResolver {
+	return &tagResolver{
+		service: s,
+		userID:  userID,
+		dryRun:  dryRun,
+		cache:   make(map[string]*model.Tag),
+	}
+}
+
+// 将标签名称解析为标签，不存在的标签自动创建（试运行时不创建）
+func (t *tagResolver) resolve(names []string) ([]model.Tag, error) {
+	tags := make([]model.Tag, 0, len(names))
+	for _, name := range names {
+		if tag, ok := t.cache[name]; ok {
+			tags = append(tags, *tag)
+			continue
+		}
+		var tag *model.Tag
+		var err error
+		if t.dryRun {
+			tag = &model.Tag{Name: name, UserID: t.userID}
+		} else {
+			tag, err = t.service.tagsRepo.FirstOrCreateByName(t.userID, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		t.cache[name] = tag
+		tags = append(tags, *tag)
+	}
+	return tags, nil
+}
+
+// 写入一批导入的卡片并清除被更新卡片的缓存
+func (s *ImportService) saveBatch(toCreate, toUpdate []*model.LearningCard) error {
+	for _, card := range toCreate {
+		card.UpdateFingerprint()
+	}
+	for _, card := range toUpdate {
+		card.UpdateFingerprint()
+	}
+	// 更新已有卡片属于内容修改，修订与导入在同一事务中写入
+	err := s.cardsRepo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		var before []*model.LearningCard
+		if s.revisionsService != nil && len(toUpdate) > 0 {
+			ids := make([]uint, len(toUpdate))
+			for i, card := range toUpdate {
+				ids[i] = card.ID
+			}
+			var err error
+			if before, err = repo.FindByIDs(ids); err != nil {
+				return err
+			}
+		}
+		if err := repo.ImportBatch(toCreate, toUpdate); err != nil {
+			return err
+		}
+		if len(before) > 0 {
+			return s.revisionsService.RecordUpdates(repo, before, toUpdate[0].UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if s.redis != nil {
+		for _, card := range toUpdate {
+			key := fmt.Sprintf("%s%d", cardCacheKeyPrefix, card.ID)
+			s.redis.Del(context.Background(), key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 学习时间分析的统计窗口（天）
+const (
+	DefaultInsightsDays = 90
+	MaxInsightsDays     = 365
+)
+
+// 识别学习时段：相邻复习间隔超过该分钟数时视为新的一次学习
+const (
+	DefaultSessionGapMinutes = 30
+	MaxSessionGapMinutes     = 240
+)
+
+const (
+	minSlotReviews = 20 // 参与评选最佳时段所需的最少复习次数
+	recentSessions = 50 // 返回的最近学习时段数
+)
+
+// GetStudyInsights 分析最近 days 天的复习日志：各小时和星期几的复习量与通过率、各卡片类型的平均耗时，
+// 并按 gapMinutes 分钟的间隔把复习划分为学习时段
+func (s *ReviewLogsService) GetStudyInsights(userID uint, days, gapMinutes int) (*model.StudyInsights, error) {
+	if days <= 0 || days > MaxInsightsDays {
+		return nil, errors.New("统计天数必须在1到365之间")
+	}
+	if gapMinutes <= 0 || gapMinutes > MaxSessionGapMinutes {
+		return nil, errors.New("学习间隔必须在1到240分钟之间")
+	}
+
+	loc := userLocation(s.userRepo, userID)
+	since := time.Now().AddDate(0, 0, -days)
+	logs, err := s.repo.FindInsightLogs(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	insights := &model.StudyInsights{
+		Days:     days,
+		Since:    since,
+		Timezone: loc.String(),
+		Hourly:   make([]*model.TimeSlotStats, 24),
+		Weekday:  make([]*model.TimeSlotStats, 7),
+		Sessions: &model.SessionInsights{GapMinutes: gapMinutes},
+	}
+	for i := range insights.Hourly {
+		insights.Hourly[i] = &model.TimeSlotStats{Slot: i}
+	}
+	for i := range insights.Weekday {
+		insights.Weekday[i] = &model.TimeSlotStats{Slot: i}
+	}
+
+	timing := make(map[model.CardType]*struct{ reviews, seconds int })
+	gap := time.Duration(gapMinutes) * time.Minute
+	var sessions []*model.DetectedSession
+	var current *model.DetectedSession
+
+	for _, log := range logs {
+		passed := log.Performance >= model.PassPerformance
+		local := log.ReviewTime.In(loc)
+		insights.Hourly[local.Hour()].Add(passed, log.Duration)
+		insights.Weekday[int(local.Weekday())].Add(passed, log.Duration)
+
+		t, ok := timing[log.CardType]
+		if !ok {
+			t = &struct{ reviews, seconds int }{}
+			timing[log.CardType] = t
+		}
+		t.reviews++
+		t.seconds += log.Duration
+
+		// 复习时间记录在作答时，本次复习开始于作答前 Duration 秒
+		start := log.ReviewTime.Add(-time.Duration(log.Duration) * time.Second)
+		if current == nil || start.Sub(current.End) > gap {
+			current = &model.DetectedSession{Start: start}
+			sessions = append(sessions, current)
+		}
+		current.End = log.ReviewTime
+		current.Reviews++
+		current.StudySeconds += log.Duration
+		if passed {
+			current.Passed++
+		}
+	}
+
+	insights.BestHour = bestSlot(insights.Hourly)
+	insights.BestWeekday = bestSlot(insights.Weekday)
+
+	insights.ByCardType = make([]*model.CardTypeTiming, 0, len(timing))
+	for cardType, t := range timing {
+		insights.ByCardType = append(insights.ByCardType, &model.CardTypeTiming{
+			CardType:   cardType,
+			Reviews:    t.reviews,
+			AvgSeconds: float64(t.seconds) / float64(t.reviews),
+		})
+	}
+	sort.Slice(insights.ByCardType, func(i, j int) bool {
+		return insights.ByCardType[i].CardType < insights.ByCardType[j].CardType
+	})
+
+	summary := insights.Sessions
+	summary.Count = len(sessions)
+	var totalMinutes float64
+	var totalReviews, totalPassed int
+	for _, session := range sessions {
+		session.LengthMinutes = session.End.Sub(session.Start).Minutes()
+		session.Accuracy = float64(session.Passed) / float64(session.Reviews)
+		totalMinutes += session.LengthMinutes
+		totalReviews += session.Reviews
+		totalPassed += session.Passed
+	}
+	if len(sessions) > 0 {
+		summary.AvgLengthMinutes = totalMinutes / float64(len(sessions))
+		summary.AvgReviews = float64(totalReviews) / float64(len(sessions))
+		summary.AvgAccuracy = float64(totalPassed) / float64(totalReviews)
+	}
+	summary.Recent = make([]*model.DetectedSession, 0, recentSessions)
+	for i := len(sessions) - 1; i >= 0 && len(summary.Recent) < recentSessions; i-- {
+		summary.Recent = append(summary.Recent, sessions[i])
+	}
+
+	return insights, nil
+}
+
+// 复习次数足够的时段中通过率最高的一个，没有符合条件的时段时返回 nil
+func bestSlot(slots []*model.TimeSlotStats) *int {
+	var best *model.TimeSlotStats
+	for _, slot := range slots {
+		if slot.Reviews < minSlotReviews {
+			continue
+		}
+		if best == nil || *slot.PassRate > *best.PassRate {
+			best = slot
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return &best.Slot
+}
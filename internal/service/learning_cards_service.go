@@ -14,9 +14,15 @@ import (
 )
 
 type LearningCardsService struct {
-	repo           *repository.LearningCardsRepository
-	reviewLogsRepo *repository.ReviewLogsRepository
-	redis          *redis.Client
+	repo             *repository.LearningCardsRepository
+	reviewLogsRepo   *repository.ReviewLogsRepository
+	decksRepo        *repository.DecksRepository
+	tagsRepo         *repository.TagsRepository
+	sessionsRepo     *repository.StudySessionsRepository
+	userRepo         *repository.UserRepository
+	goalsService     *GoalsService
+	revisionsService *CardRevisionsService
+	redis            *redis.Client
 }
 
 func NewLearningCardsService(repo *repository.LearningCardsRepository, redis *redis.Client) *LearningCardsService {
@@ -31,6 +37,36 @@ func (s *LearningCardsService) SetReviewLogsRepository(reviewLogsRepo *repositor
 	s.reviewLogsRepo = reviewLogsRepo
 }
 
+// 设置卡组仓库
+func (s *LearningCardsService) SetDecksRepository(decksRepo *repository.DecksRepository) {
+	s.decksRepo = decksRepo
+}
+
+// 设置标签仓库，设置后可按名称为卡片添加标签
+func (s *LearningCardsService) SetTagsRepository(tagsRepo *repository.TagsRepository) {
+	s.tagsRepo = tagsRepo
+}
+
+// 设置学习会话仓库，设置后复习可以关联到学习会话
+func (s *LearningCardsService) SetStudySessionsRepository(sessionsRepo *repository.StudySessionsRepository) {
+	s.sessionsRepo = sessionsRepo
+}
+
+// 设置用户仓库，设置后分散积压卡片按用户的时区划分日期
+func (s *LearningCardsService) SetUserRepository(userRepo *repository.UserRepository) {
+	s.userRepo = userRepo
+}
+
+// 设置每日目标服务，设置后每次复习都会更新目标进度、连续天数并检查成就
+func (s *LearningCardsService) SetGoalsService(goalsService *GoalsService) {
+	s.goalsService = goalsService
+}
+
+// 设置修订历史服务，设置后每次编辑卡片都会记录修订
+func (s *LearningCardsService) SetRevisionsService(revisionsService *CardRevisionsService) {
+	s.revisionsService = revisionsService
+}
+
 // 缓存相关的常量
 const (
 	cardCacheKeyPrefix  = "learning_card:"
@@ -44,16 +80,109 @@ func (s *LearningCardsService) CreateLearningCard(card *model.LearningCard) erro
 		return errors.New("用户ID不能为0")
 	}
 
+	// 校验所属卡组
+	if err := s.resolveDeck(card); err != nil {
+		return err
+	}
+
 	// 设置初始复习时间
-	now := time.Now()
+	initReviewSchedule(card, time.Now())
+	card.UpdateFingerprint()
+
+	// 创建学习卡片
+	return s.repo.Create(card)
+}
+
+// ResolveTags 将标签ID和名称解析为用户的标签：ID 必须属于该用户，名称不存在时自动创建。
+// 两者都为 nil 时返回 nil，表示不修改标签
+func (s *LearningCardsService) ResolveTags(userID uint, tagIDs []uint, names []string) ([]model.Tag, error) {
+	if tagIDs == nil && names == nil {
+		return nil, nil
+	}
+	tags := make([]model.Tag, 0, len(tagIDs)+len(names))
+	seen := make(map[uint]bool)
+
+	ids := uniqueIDs(tagIDs)
+	existing, err := s.repo.ExistingTags(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) != len(ids) {
+		return nil, errors.New("标签不存在或无权限使用")
+	}
+	for _, tag := range existing {
+		seen[tag.ID] = true
+		tags = append(tags, tag)
+	}
+
+	if len(names) > 0 && s.tagsRepo == nil {
+		return nil, errors.New("不支持按名称添加标签")
+	}
+	for _, raw := range names {
+		name, err := model.NormalizeTagName(raw)
+		if err != nil {
+			return nil, err
+		}
+		tag, err := s.tagsRepo.FirstOrCreateByName(userID, name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+// 校验卡片所属卡组，未指定时放入用户的默认卡组
+func (s *LearningCardsService) resolveDeck(card *model.LearningCard) error {
+	if s.decksRepo == nil {
+		return nil
+	}
+	if card.DeckID == 0 {
+		deck, err := s.decksRepo.FindOrCreateDefault(card.UserID)
+		if err != nil {
+			return err
+		}
+		card.DeckID = deck.ID
+		return nil
+	}
+	deck, err := s.decksRepo.FindByID(card.DeckID)
+	if err != nil {
+		return errors.New("卡组不存在")
+	}
+	if deck.UserID != card.UserID {
+		return errors.New("无权限使用此卡组")
+	}
+	if card.OriginalDeckID == 0 {
+		if deck.IsFiltered {
+			return errors.New("不能直接将卡片放入筛选卡组")
+		}
+		return nil
+	}
+
+	// 位于筛选卡组中的卡片，同时校验其原卡组
+	original, err := s.decksRepo.FindByID(card.OriginalDeckID)
+	if err != nil {
+		return errors.New("卡组不存在")
+	}
+	if original.UserID != card.UserID {
+		return errors.New("无权限使用此卡组")
+	}
+	if original.IsFiltered {
+		return errors.New("不能直接将卡片放入筛选卡组")
+	}
+	return nil
+}
+
+// 初始化新卡片的复习参数
+func initReviewSchedule(card *model.LearningCard, now time.Time) {
 	card.LastReviewAt = now
 	card.NextReview = now.Add(24 * time.Hour) // 默认24小时后复习
 	card.ReviewCount = 0
 	card.EaseFactor = 2.5 // 默认简易因子
 	card.Difficulty = 0.3 // 默认难度系数
-
-	// 创建学习卡片
-	return s.repo.Create(card)
 }
 
 // 根据ID查找学习卡片
@@ -112,13 +241,57 @@ func (s *LearningCardsService) GetCardsToReview(userID uint) ([]*model.LearningC
 	return s.repo.FindByReviewTimeRange(userID, now.Add(-24*time.Hour), now)
 }
 
-// 更新卡片复习状态
-func (s *LearningCardsService) UpdateCardReviewStatus(card *model.LearningCard, quality int, duration time.Duration, isHard bool) error {
+// CheckStudySession 检查学习会话属于该用户且仍在进行中
+func (s *LearningCardsService) CheckStudySession(userID, sessionID uint) error {
+	if s.sessionsRepo == nil {
+		return errors.New("不支持学习会话")
+	}
+	session, err := s.sessionsRepo.FindByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("学习会话不存在")
+	}
+	if session.Status != model.SessionActive {
+		return errors.New("学习会话已结束")
+	}
+	return nil
+}
+
+// 更新卡片复习状态，sessionID 不为 0 时复习记入该学习会话
+func (s *LearningCardsService) UpdateCardReviewStatus(card *model.LearningCard, quality int, duration time.Duration, isHard bool, sessionID uint) error {
+	if err := s.applyReview(card, quality, duration, isHard, sessionID); err != nil {
+		return err
+	}
+
+	// 复习完成后更新每日目标和成就（忽略错误，不影响主流程）
+	if s.goalsService != nil {
+		s.goalsService.HandleReview(card.UserID)
+	}
+	return nil
+}
+
+// 记录复习并按调度算法更新卡片
+func (s *LearningCardsService) applyReview(card *model.LearningCard, quality int, duration time.Duration, isHard bool, sessionID uint) error {
 	// 计算复习质量
 	if quality < 0 {
 		quality = algorithm.GetReviewQuality(duration, quality > 2, isHard)
 	}
 
+	// 位于筛选卡组中的卡片
+	var filtered *model.Deck
+	if s.decksRepo != nil && card.OriginalDeckID != 0 {
+		if deck, err := s.decksRepo.FindByID(card.DeckID); err == nil && deck.IsFiltered {
+			filtered = deck
+		}
+	}
+	if filtered != nil && !filtered.Reschedule {
+		// 冲刺模式：只记录复习，不改变长期排程
+		s.createReviewLog(card, quality, duration, sessionID)
+		return s.finishFilteredReview(card, quality)
+	}
+
+	// 新卡片和学习中的卡片按学习步长安排
+	isNew, learning := card.ReviewCount == 0, card.LearningStep > 0
+
 	// 应用 SM-2 算法
 	params := algorithm.CalculateNextReview(&algorithm.SM2Parameters{
 		ReviewCount: card.ReviewCount,
@@ -127,30 +300,40 @@ func (s *LearningCardsService) UpdateCardReviewStatus(card *model.LearningCard,
 		NextReview:  card.NextReview,
 	}, quality)
 
+	// 创建复习日志（记录复习前的间隔）
+	s.createReviewLog(card, quality, duration, sessionID)
+
 	// 更新卡片
 	card.ReviewCount = params.ReviewCount
 	card.Difficulty = params.Difficulty
 	card.LastReviewAt = params.LastReview
 	card.NextReview = params.NextReview
 
-	// 创建复习日志
-	if s.reviewLogsRepo != nil {
-		reviewLog := &model.ReviewLog{
-			CardID:      card.ID,
-			UserID:      card.UserID,
-			ReviewTime:  time.Now(),
-			Performance: quality,
-			Duration:    int(duration.Seconds()),
+	// 应用卡组的调度选项：按学习步长学习和重新学习，间隔不超过最大间隔
+	// 筛选卡组中的卡片使用原卡组的调度选项
+	homeDeckID := card.DeckID
+	if filtered != nil {
+		homeDeckID = card.OriginalDeckID
+	}
+	if s.decksRepo != nil && homeDeckID != 0 {
+		if preset, err := s.decksRepo.FindPresetForDeck(homeDeckID); err == nil {
+			applyDeckPreset(card, preset, quality, isNew)
 		}
-
-		// 保存复习日志（忽略错误，不影响主流程）
-		s.reviewLogsRepo.Create(reviewLog)
 	}
 
 	// 更新数据库
 	if err := s.repo.UpdateLearningCard(card); err != nil {
 		return err
 	}
+	// Updates 会跳过零值，离开学习阶段时单独清零学习步长
+	if learning && card.LearningStep == 0 {
+		if err := s.repo.UpdateColumns([]uint{card.ID}, map[string]interface{}{"learning_step": 0}); err != nil {
+			return err
+		}
+	}
+	if filtered != nil {
+		return s.finishFilteredReview(card, quality)
+	}
 
 	// 更新缓存
 	if s.redis != nil {
@@ -160,6 +343,77 @@ func (s *LearningCardsService) UpdateCardReviewStatus(card *model.LearningCard,
 	return nil
 }
 
+// 保存复习日志（忽略错误，不影响主流程）
+func (s *LearningCardsService) createReviewLog(card *model.LearningCard, quality int, duration time.Duration, sessionID uint) {
+	if s.reviewLogsRepo == nil {
+		return
+	}
+	now := time.Now()
+	reviewLog := &model.ReviewLog{
+		CardID:      card.ID,
+		UserID:      card.UserID,
+		ReviewTime:  now,
+		Performance: quality,
+		Duration:    int(duration.Seconds()),
+		NewCard:     card.ReviewCount == 0,
+	}
+	if !card.LastReviewAt.IsZero() {
+		reviewLog.ElapsedDays = now.Sub(card.LastReviewAt).Hours() / 24
+		reviewLog.ScheduledDays = card.NextReview.Sub(card.LastReviewAt).Hours() / 24
+	}
+	if sessionID != 0 {
+		reviewLog.SessionID = &sessionID
+	}
+	if err := s.reviewLogsRepo.Create(reviewLog); err != nil {
+		return
+	}
+	if sessionID != 0 && s.sessionsRepo != nil {
+		s.sessionsRepo.RecordReview(sessionID, reviewLog.Kind(), quality >= model.PassPerformance, reviewLog.Duration, now)
+	}
+}
+
+// 筛选卡组中的卡片答对后回到原卡组，答错则留在筛选卡组中再练一遍
+func (s *LearningCardsService) finishFilteredReview(card *model.LearningCard, quality int) error {
+	if quality >= 3 {
+		if err := s.decksRepo.ReturnCards(card.DeckID, []uint{card.ID}); err != nil {
+			return err
+		}
+		card.DeckID, card.OriginalDeckID = card.OriginalDeckID, 0
+	}
+	if s.redis != nil {
+		s.setCardToCache(card)
+	}
+	return nil
+}
+
+// 按卡组调度选项调整下次复习时间：答错时回到第一个学习步长重新学习，
+// 新卡片和学习中的卡片答对时进入下一个步长，走完全部步长后毕业，按 SM-2 的间隔复习
+func applyDeckPreset(card *model.LearningCard, preset *model.DeckPreset, quality int, isNew bool) {
+	steps, err := model.ParseLearningSteps(preset.LearningSteps)
+	switch {
+	case err != nil || len(steps) == 0:
+		card.LearningStep = 0
+	case quality < model.PassPerformance:
+		card.LearningStep = 1
+		card.NextReview = card.LastReviewAt.Add(steps[0])
+	case isNew || card.LearningStep > 0:
+		// 新卡片第一次答对视为完成了第一个步长
+		step := max(card.LearningStep, 1)
+		if step < len(steps) {
+			card.NextReview = card.LastReviewAt.Add(steps[step])
+			card.LearningStep = step + 1
+		} else {
+			card.LearningStep = 0
+		}
+	}
+	if preset.MaxInterval > 0 {
+		maxNext := card.LastReviewAt.AddDate(0, 0, preset.MaxInterval)
+		if card.NextReview.After(maxNext) {
+			card.NextReview = maxNext
+		}
+	}
+}
+
 // 根据用户ID查找学习卡片
 func (s *LearningCardsService) GetLearningCardsByUserID(userID uint) ([]*model.LearningCard, error) {
 	return s.repo.FindByUserID(userID)
@@ -167,13 +421,48 @@ func (s *LearningCardsService) GetLearningCardsByUserID(userID uint) ([]*model.L
 
 // 更新学习卡片
 func (s *LearningCardsService) UpdateLearningCard(card *model.LearningCard) error {
-	// 清除缓存
+	if card.DeckID != 0 {
+		if err := s.resolveDeck(card); err != nil {
+			return err
+		}
+	}
+
+	card.UpdateFingerprint()
+
+	// 卡片、标签和修订在同一事务中写入
+	err := s.repo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		// 记录修改前的内容，用于生成修订
+		var before []*model.LearningCard
+		if s.revisionsService != nil {
+			var err error
+			if before, err = repo.FindByIDs([]uint{card.ID}); err != nil {
+				return err
+			}
+		}
+
+		if err := repo.UpdateLearningCard(card); err != nil {
+			return err
+		}
+		if card.Tags != nil {
+			if err := repo.ReplaceTags(card, card.Tags); err != nil {
+				return err
+			}
+		}
+		if s.revisionsService != nil {
+			return s.revisionsService.RecordUpdates(repo, before, card.UserID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 提交后清除缓存
 	if s.redis != nil {
 		key := fmt.Sprintf("%s%d", cardCacheKeyPrefix, card.ID)
 		s.redis.Del(context.Background(), key)
 	}
-
-	return s.repo.UpdateLearningCard(card)
+	return nil
 }
 
 // 根据标签过滤查询
@@ -181,6 +470,34 @@ func (s *LearningCardsService) GetLearningCardsByTag(userID uint, tagID uint) ([
 	return s.repo.FindByTag(userID, tagID)
 }
 
+// 根据卡组查询（包含子卡组，分页）
+func (s *LearningCardsService) GetLearningCardsByDeck(userID uint, deckID uint, page, pageSize int) ([]*model.LearningCard, int64, error) {
+	if s.decksRepo == nil {
+		return nil, 0, errors.New("卡组功能未启用")
+	}
+	deckIDs, err := s.decksRepo.FindSubtreeIDs(userID, deckID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindByDeckIDsWithPagination(userID, deckIDs, page, pageSize)
+}
+
+// 按筛选条件和搜索语句分页查询，卡组条件包含子卡组
+func (s *LearningCardsService) SearchLearningCards(userID uint, filter *model.CardFilter, sort string, page, pageSize int) ([]*model.LearningCard, int64, error) {
+	if len(filter.DeckIDs) > 0 && s.decksRepo != nil {
+		var deckIDs []uint
+		for _, id := range filter.DeckIDs {
+			subtree, err := s.decksRepo.FindSubtreeIDs(userID, id)
+			if err != nil {
+				return nil, 0, err
+			}
+			deckIDs = append(deckIDs, subtree...)
+		}
+		filter.DeckIDs = deckIDs
+	}
+	return s.repo.Search(userID, filter, sort, page, pageSize)
+}
+
 // 根据难度范围查询
 func (s *LearningCardsService) GetLearningCardsByDifficultyRange(userID uint, min, max float64) ([]*model.LearningCard, error) {
 	return s.repo.FindByDifficultyRange(userID, min, max)
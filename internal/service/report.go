@@ -0,0 +1,140 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"errors"
+	"time"
+)
+
+const (
+	DefaultReportDays = 7   // 未指定日期时报告最近一周
+	MaxReportDays     = 366 // 报告最多覆盖的天数
+	reportHardCards   = 20  // 报告中列出的最难卡片数
+)
+
+// GetStudyReport 生成 startDate 到 endDate（均包含，用户时区的日期，与学习预测和目标一致）的学习报告：
+// 汇总和每日明细读取每日统计（同样按用户时区划分日期），
+// 标签保持率和最难的卡片读取该期间的复习日志。
+// startDate 和 endDate 为 YYYY-MM-DD，endDate 为空时是今天，startDate 为空时是截至 endDate 的最近 7 天
+func (s *ReviewLogsService) GetStudyReport(userID uint, startDate, endDate string) (*model.StudyReport, error) {
+	loc := userLocation(s.userRepo, userID)
+	end := startOfDay(time.Now(), loc)
+	if endDate != "" {
+		parsed, err := time.ParseInLocation(model.DailyStatDateLayout, endDate, loc)
+		if err != nil {
+			return nil, errors.New("无效的结束日期")
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, 1-DefaultReportDays)
+	if startDate != "" {
+		parsed, err := time.ParseInLocation(model.DailyStatDateLayout, startDate, loc)
+		if err != nil {
+			return nil, errors.New("无效的开始日期")
+		}
+		start = parsed
+	}
+	if end.Before(start) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+	if !start.AddDate(0, 0, MaxReportDays).After(end) {
+		return nil, errors.New("报告最多覆盖366天")
+	}
+	// 时间范围包含结束日期当天
+	until := end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+
+	report := &model.StudyReport{
+		StartDate:   start.Format(model.DailyStatDateLayout),
+		EndDate:     end.Format(model.DailyStatDateLayout),
+		GeneratedAt: time.Now(),
+		Totals:      &model.ReportTotals{},
+	}
+
+	stats, err := s.repo.FindDailyStats(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]*model.DailyStat, len(stats))
+	for _, stat := range stats {
+		byDate[stat.Date] = stat
+	}
+
+	totals := report.Totals
+	var duration, performanceSum int
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(model.DailyStatDateLayout)
+		item := &model.ReportDay{Date: date}
+		if stat, ok := byDate[date]; ok {
+			item.Reviews = stat.Reviews
+			item.Passed = stat.Passed
+			item.StudyMinutes = float64(stat.Duration) / 60
+			item.NewCards = stat.NewCards
+			item.LearnCards = stat.LearnCards
+			item.ReviewCards = stat.ReviewCards
+			rate := float64(stat.Passed) / float64(stat.Reviews)
+			item.PassRate = &rate
+
+			totals.Reviews += stat.Reviews
+			totals.Passed += stat.Passed
+			totals.NewCards += stat.NewCards
+			totals.LearnCards += stat.LearnCards
+			totals.ReviewCards += stat.ReviewCards
+			totals.ActiveDays++
+			duration += stat.Duration
+			performanceSum += stat.PerformanceSum
+		}
+		report.Days = append(report.Days, item)
+	}
+	totals.StudyMinutes = float64(duration) / 60
+	if totals.Reviews > 0 {
+		rate := float64(totals.Passed) / float64(totals.Reviews)
+		totals.PassRate = &rate
+		totals.AvgPerformance = float64(performanceSum) / float64(totals.Reviews)
+	}
+
+	if report.Tags, err = s.reportTagRetention(userID, start, until); err != nil {
+		return nil, err
+	}
+	if report.HardestCards, err = s.repo.FindHardestCards(userID, start, until, reportHardCards); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// 报告期间各标签的保持率，与保持率分析一样只统计学习阶段之后的复习
+func (s *ReviewLogsService) reportTagRetention(userID uint, start, until time.Time) ([]*model.RetentionBucket, error) {
+	tagNames, cardTags, err := s.cardTagIndex(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cardTags) == 0 {
+		return []*model.RetentionBucket{}, nil
+	}
+
+	logs, err := s.repo.FindRetentionLogs(userID, start, until)
+	if err != nil {
+		return nil, err
+	}
+
+	byTag := make(map[uint]*model.RetentionBucket)
+	var prev *repository.RetentionLog
+	for i := range logs {
+		log := &logs[i]
+		_, _, ok := retentionInterval(log, prev)
+		prev = log
+		if !ok {
+			continue
+		}
+		passed := log.Performance >= model.PassPerformance
+		for _, tagID := range cardTags[log.CardID] {
+			bucket, ok := byTag[tagID]
+			if !ok {
+				bucket = &model.RetentionBucket{Key: tagNames[tagID], TagID: tagID}
+				byTag[tagID] = bucket
+			}
+			bucket.Add(passed)
+		}
+	}
+	return sortedTagBuckets(byTag), nil
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WriteReportCSV 以 CSV 输出学习报告，汇总、每日明细、标签保持率和最难的卡片依次作为独立的段落，段落之间空一行。
+// 开头写入 UTF-8 BOM，便于 Excel 正确识别中文
+func WriteReportCSV(w io.Writer, report *model.StudyReport) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	t := report.Totals
+	rows := [][]string{
+		{"学习报告", report.StartDate, report.EndDate},
+		{},
+		{"汇总"},
+		{"复习次数", "通过次数", "通过率", "学习分钟", "平均评分", "新卡片", "学习中", "复习", "学习天数"},
+		{itoa(t.Reviews), itoa(t.Passed), rate(t.PassRate), ftoa(t.StudyMinutes), ftoa(t.AvgPerformance),
+			itoa(t.NewCards), itoa(t.LearnCards), itoa(t.ReviewCards), itoa(t.ActiveDays)},
+		{},
+		{"每日明细"},
+		{"日期", "复习次数", "通过次数", "通过率", "学习分钟", "新卡片", "学习中", "复习"},
+	}
+	for _, d := range report.Days {
+		rows = append(rows, []string{d.Date, itoa(d.Reviews), itoa(d.Passed), rate(d.PassRate), ftoa(d.StudyMinutes),
+			itoa(d.NewCards), itoa(d.LearnCards), itoa(d.ReviewCards)})
+	}
+	rows = append(rows, []string{}, []string{"标签保持率"}, []string{"标签", "复习次数", "通过次数", "通过率"})
+	for _, tag := range report.Tags {
+		rows = append(rows, []string{csvText(tag.Key), itoa(tag.Reviews), itoa(tag.Passed), rate(tag.PassRate)})
+	}
+	rows = append(rows, []string{}, []string{"最难的卡片"}, []string{"卡片ID", "标题", "复习次数", "答错次数", "平均评分"})
+	for _, card := range report.HardestCards {
+		rows = append(rows, []string{strconv.FormatUint(uint64(card.CardID), 10), csvText(card.Title), itoa(card.Reviews),
+			itoa(card.Failures), ftoa(card.AvgPerformance)})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// 用户输入的文本以 = + - @ 等开头时加上单引号前缀，防止 Excel 把单元格当作公式执行
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func itoa(n int) string {
+	return strconv.Itoa(n)
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// 通过率格式化为百分比，没有复习时为空
+func rate(r *float64) string {
+	if r == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*r*100, 'f', 1, 64) + "%"
+}
+
+// 每日复习图表的尺寸
+const (
+	chartWidth   = 720.0
+	chartHeight  = 240.0
+	chartLeft    = 40.0
+	chartRight   = 40.0
+	chartTop     = 16.0
+	chartBottom  = 28.0
+	chartXLabels = 10 // 横轴最多显示的日期标签数
+	tagChartMax  = 15 // 标签图表最多显示的标签数
+	tagBarHeight = 22.0
+	tagLabelW    = 180.0
+)
+
+// 图表中的一个柱形
+type chartBar struct {
+	X, Y, W, H float64
+	Label      string // 横轴标签，为空时不显示
+	Title      string // 鼠标悬停提示
+}
+
+// 标签保持率图表中的一行
+type tagBar struct {
+	Y, W  float64 // 行的纵坐标和条形的宽度
+	Name  string
+	Label string
+}
+
+// HTML 报告模板使用的数据，图表的坐标在服务端计算好
+type reportView struct {
+	*model.StudyReport
+	Width, Height   float64
+	PlotLeft        float64
+	PlotRight       float64
+	PlotTop         float64
+	PlotBottom      float64
+	MaxReviews      int
+	Bars            []chartBar
+	PassLine        string // 通过率折线的点
+	TagWidth        float64
+	TagHeight       float64
+	TagLabelW       float64
+	TagBars         []tagBar
+	TagPlotW        float64
+	TagsTruncated   int
+	HasDailyReviews bool
+}
+
+// WriteReportHTML 以独立的 HTML 页面输出学习报告，图表为服务端生成的内联 SVG，不依赖外部资源
+func WriteReportHTML(w io.Writer, report *model.StudyReport) error {
+	view := &reportView{
+		StudyReport: report,
+		Width:       chartWidth,
+		Height:      chartHeight,
+		PlotLeft:    chartLeft,
+		PlotRight:   chartWidth - chartRight,
+		PlotTop:     chartTop,
+		PlotBottom:  chartHeight - chartBottom,
+		TagWidth:    chartWidth,
+		TagLabelW:   tagLabelW,
+		TagPlotW:    chartWidth - tagLabelW - 60,
+	}
+
+	// 每日复习次数柱形图和通过率折线
+	for _, d := range report.Days {
+		if d.Reviews > view.MaxReviews {
+			view.MaxReviews = d.Reviews
+		}
+	}
+	view.HasDailyReviews = view.MaxReviews > 0
+	plotW := view.PlotRight - view.PlotLeft
+	plotH := view.PlotBottom - view.PlotTop
+	slot := plotW / float64(len(report.Days))
+	labelEvery := int(math.Ceil(float64(len(report.Days)) / chartXLabels))
+	var points []string
+	for i, d := range report.Days {
+		h := 0.0
+		if view.MaxReviews > 0 {
+			h = float64(d.Reviews) / float64(view.MaxReviews) * plotH
+		}
+		bar := chartBar{
+			X:     view.PlotLeft + float64(i)*slot + slot*0.15,
+			Y:     view.PlotBottom - h,
+			W:     slot * 0.7,
+			H:     h,
+			Title: fmt.Sprintf("%s：复习 %d 次，通过率 %s", d.Date, d.Reviews, orDash(rate(d.PassRate))),
+		}
+		if i%labelEvery == 0 {
+			bar.Label = d.Date[5:]
+		}
+		view.Bars = append(view.Bars, bar)
+		if d.PassRate != nil {
+			x := view.PlotLeft + (float64(i)+0.5)*slot
+			y := view.PlotBottom - *d.PassRate*plotH
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+		}
+	}
+	view.PassLine = strings.Join(points, " ")
+
+	// 标签保持率条形图，通过率低的在前
+	tags := report.Tags
+	if len(tags) > tagChartMax {
+		view.TagsTruncated = len(tags) - tagChartMax
+		tags = tags[:tagChartMax]
+	}
+	for i, tag := range tags {
+		view.TagBars = append(view.TagBars, tagBar{
+			Y:     float64(i) * tagBarHeight,
+			W:     *tag.PassRate * view.TagPlotW,
+			Name:  tag.Key,
+			Label: fmt.Sprintf("%s（%d 次）", rate(tag.PassRate), tag.Reviews),
+		})
+	}
+	view.TagHeight = float64(len(tags)) * tagBarHeight
+
+	return reportTemplate.Execute(w, view)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"rate": func(r *float64) string { return orDash(rate(r)) },
+	"num":  func(f float64) string { return strconv.FormatFloat(f, 'f', 1, 64) },
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>学习报告 {{.StartDate}} 至 {{.EndDate}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; color: #222; max-width: 780px; margin: 24px auto; padding: 0 16px; }
+h1 { font-size: 22px; margin-bottom: 4px; }
+h2 { font-size: 17px; margin-top: 32px; border-bottom: 1px solid #ddd; padding-bottom: 4px; }
+.muted { color: #888; font-size: 13px; }
+.cards { display: flex; flex-wrap: wrap; gap: 12px; }
+.card { border: 1px solid #e3e3e3; border-radius: 6px; padding: 10px 14px; min-width: 120px; }
+.card b { display: block; font-size: 20px; }
+table { border-collapse: collapse; width: 100%; font-size: 13px; }
+th, td { border-bottom: 1px solid #eee; padding: 4px 6px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+svg text { font-size: 11px; fill: #666; }
+</style>
+</head>
+<body>
+<h1>学习报告</h1>
+<div class="muted">{{.StartDate}} 至 {{.EndDate}}，生成于 {{.GeneratedAt.Format "2006-01-02 15:04"}}</div>
+
+<h2>汇总</h2>
+<div class="cards">
+<div class="card">复习次数<b>{{.Totals.Reviews}}</b></div>
+<div class="card">通过率<b>{{rate .Totals.PassRate}}</b></div>
+<div class="card">学习时长<b>{{num .Totals.StudyMinutes}} 分钟</b></div>
+<div class="card">学习天数<b>{{.Totals.ActiveDays}} / {{len .Days}}</b></div>
+<div class="card">新卡片 / 学习中 / 复习<b>{{.Totals.NewCards}} / {{.Totals.LearnCards}} / {{.Totals.ReviewCards}}</b></div>
+</div>
+
+<h2>每日复习</h2>
+{{if .HasDailyReviews}}
+<svg width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+<line x1="{{.PlotLeft}}" y1="{{.PlotBottom}}" x2="{{.PlotRight}}" y2="{{.PlotBottom}}" stroke="#bbb"/>
+<text x="{{.PlotLeft}}" y="{{.PlotTop}}" text-anchor="end" dx="-4">{{.MaxReviews}}</text>
+<text x="{{.PlotLeft}}" y="{{.PlotBottom}}" text-anchor="end" dx="-4">0</text>
+<text x="{{.PlotRight}}" y="{{.PlotTop}}" dx="4">100%</text>
+<text x="{{.PlotRight}}" y="{{.PlotBottom}}" dx="4">0%</text>
+{{range .Bars}}<rect x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" width="{{printf "%.1f" .W}}" height="{{printf "%.1f" .H}}" fill="#6a9fd8"><title>{{.Title}}</title></rect>
+{{if .Label}}<text x="{{printf "%.1f" .X}}" y="{{$.Height}}" dy="-10">{{.Label}}</text>
+{{end}}{{end}}
+{{if .PassLine}}<polyline points="{{.PassLine}}" fill="none" stroke="#e07b39" stroke-width="2"/>{{end}}
+</svg>
+<div class="muted">柱形为复习次数，折线为通过率</div>
+{{else}}
+<p class="muted">这段时间没有复习记录</p>
+{{end}}
+
+<table>
+<tr><th>日期</th><th>复习</th><th>通过</th><th>通过率</th><th>分钟</th><th>新卡片</th><th>学习中</th><th>复习</th></tr>
+{{range .Days}}<tr><td>{{.Date}}</td><td>{{.Reviews}}</td><td>{{.Passed}}</td><td>{{rate .PassRate}}</td><td>{{num .StudyMinutes}}</td><td>{{.NewCards}}</td><td>{{.LearnCards}}</td><td>{{.ReviewCards}}</td></tr>
+{{end}}</table>
+
+<h2>标签保持率</h2>
+{{if .TagBars}}
+<svg width="{{.TagWidth}}" height="{{.TagHeight}}" viewBox="0 0 {{.TagWidth}} {{.TagHeight}}" xmlns="http://www.w3.org/2000/svg">
+{{range .TagBars}}<text x="0" y="{{.Y}}" dy="15">{{.Name}}</text>
+<rect x="{{$.TagLabelW}}" y="{{.Y}}" transform="translate(0 3)" width="{{printf "%.1f" .W}}" height="16" fill="#7cb87c"/>
+<text x="{{$.TagLabelW}}" y="{{.Y}}" dx="{{printf "%.1f" .W}}" dy="15">&#160;{{.Label}}</text>
+{{end}}</svg>
+{{if .TagsTruncated}}<div class="muted">另有 {{.TagsTruncated}} 个标签未显示</div>{{end}}
+{{else}}
+<p class="muted">没有可统计的带标签复习</p>
+{{end}}
+
+<h2>最难的卡片</h2>
+{{if .HardestCards}}
+<table>
+<tr><th>卡片</th><th>复习</th><th>答错</th><th>平均评分</th></tr>
+{{range .HardestCards}}<tr><td>{{.Title}}</td><td>{{.Reviews}}</td><td>{{.Failures}}</td><td>{{num .AvgPerformance}}</td></tr>
+{{end}}</table>
+{{else}}
+<p class="muted">这段时间没有答错的卡片</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/algorithm"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 重置卡片为新卡片，archiveLogs 为 true 时归档其复习日志
+func (s *LearningCardsService) ForgetCard(card *model.LearningCard, archiveLogs bool) (*model.LearningCard, error) {
+	return s.bulkOne(card, &model.BulkCardsRequest{
+		Action:      model.BulkForget,
+		ArchiveLogs: archiveLogs,
+	})
+}
+
+// 设置卡片的下次复习时间，指定 dueTo 时在范围内随机选择
+func (s *LearningCardsService) RescheduleCard(card *model.LearningCard, dueFrom time.Time, dueTo *time.Time) (*model.LearningCard, error) {
+	return s.bulkOne(card, &model.BulkCardsRequest{
+		Action:  model.BulkReschedule,
+		DueFrom: &dueFrom,
+		DueTo:   dueTo,
+	})
+}
+
+// 对单张卡片执行批量操作并返回更新后的卡片
+func (s *LearningCardsService) bulkOne(card *model.LearningCard, req *model.BulkCardsRequest) (*model.LearningCard, error) {
+	req.CardIDs = []uint{card.ID}
+	result, err := s.BulkUpdate(card.UserID, req)
+	if err != nil {
+		return nil, err
+	}
+	if item := result.Results[0]; !item.Success {
+		return nil, errors.New(item.Error)
+	}
+	cards, err := s.repo.FindByIDs([]uint{card.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) == 0 {
+		return nil, errors.New("卡片不存在")
+	}
+	return cards[0], nil
+}
+
+// 将已到期的积压卡片分散到接下来的若干天：按预计记忆保持率从低到高排序，
+// 保持率最低（最容易遗忘）的留在今天，其余依次均匀推迟到之后各天（用户时区）的零点
+func (s *LearningCardsService) PostponeBacklog(userID uint, req *model.PostponeBacklogRequest) (*model.PostponeBacklogResult, error) {
+	var deckIDs []uint
+	if req.DeckID != 0 {
+		if s.decksRepo == nil {
+			return nil, errors.New("卡组功能未启用")
+		}
+		deck, err := s.decksRepo.FindByID(req.DeckID)
+		if err != nil || deck.UserID != userID {
+			return nil, errors.New("卡组不存在")
+		}
+		if deckIDs, err = s.decksRepo.FindSubtreeIDs(userID, req.DeckID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	cards, err := s.repo.FindOverdue(userID, deckIDs, now)
+	if err != nil {
+		return nil, err
+	}
+
+	retention := make(map[uint]float64, len(cards))
+	for _, card := range cards {
+		retention[card.ID] = algorithm.PredictRetention(card.LastReviewAt, card.NextReview, now)
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		return retention[cards[i].ID] < retention[cards[j].ID]
+	})
+
+	// 推迟的卡片在当天零点到期，与到期预测一样按用户时区划分日期
+	today := startOfDay(now, userLocation(s.userRepo, userID))
+
+	// 第 i 张卡片安排在第 i*days/n 天
+	byDay := make([][]uint, req.Days)
+	for i, card := range cards {
+		day := i * req.Days / len(cards)
+		byDay[day] = append(byDay[day], card.ID)
+	}
+
+	err = s.repo.Transaction(func(repo *repository.LearningCardsRepository) error {
+		for day := 1; day < req.Days; day++ {
+			if err := repo.UpdateColumns(byDay[day], map[string]interface{}{
+				"next_review": today.AddDate(0, 0, day),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.redis != nil {
+		for _, ids := range byDay[1:] {
+			for _, id := range ids {
+				s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, id))
+			}
+		}
+	}
+
+	result := &model.PostponeBacklogResult{Total: len(cards), Days: make([]*model.PostponeDay, req.Days)}
+	for day := range byDay {
+		result.Days[day] = &model.PostponeDay{
+			Date:  today.AddDate(0, 0, day).Format("2006-01-02"),
+			Count: len(byDay[day]),
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/algorithm"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// 保持率分析的统计窗口（天）
+const (
+	DefaultRetentionDays = 90
+	MaxRetentionDays     = 3650
+)
+
+// 遗忘曲线按距上次复习的天数分段的边界
+var forgettingCurveEdges = []float64{0, 1, 2, 3, 5, 7, 10, 14, 21, 30, 45, 60, 90, 180, 365}
+
+// 校准按预测回忆概率划分的区间数
+const calibrationBins = 10
+
+// GetRetentionStats 根据最近 days 天的复习日志分析记忆保持率：按新近/成熟卡片、卡片类型和标签统计通过率，
+// 计算经验遗忘曲线，并对比调度器预测的回忆概率与实际结果
+func (s *ReviewLogsService) GetRetentionStats(userID uint, days int) (*model.RetentionStats, error) {
+	if days <= 0 || days > MaxRetentionDays {
+		return nil, errors.New("统计天数必须在1到3650之间")
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	logs, err := s.repo.FindRetentionLogs(userID, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	tagNames, cardTags, err := s.cardTagIndex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.RetentionStats{
+		Days:    days,
+		Since:   since,
+		Overall: &model.RetentionBucket{Key: "overall"},
+		Young:   &model.RetentionBucket{Key: "young"},
+		Mature:  &model.RetentionBucket{Key: "mature"},
+	}
+	byType := make(map[model.CardType]*model.RetentionBucket)
+	byTag := make(map[uint]*model.RetentionBucket)
+
+	curve := make([]struct {
+		reviews, passed int
+		elapsed         float64
+	}, len(forgettingCurveEdges))
+	bins := make([]struct {
+		reviews, passed int
+		predicted       float64
+	}, calibrationBins)
+	var calibrated, calibratedPassed int
+	var predictedSum, brierSum float64
+
+	var prev *repository.RetentionLog
+	for i := range logs {
+		log := &logs[i]
+		elapsed, scheduled, ok := retentionInterval(log, prev)
+		prev = log
+		if !ok {
+			continue
+		}
+		passed := log.Performance >= model.PassPerformance
+
+		stats.Overall.Add(passed)
+
+		// 旧日志没有记录计划间隔，用实际间隔近似
+		interval := scheduled
+		if interval == 0 {
+			interval = elapsed
+		}
+		if interval >= model.MatureIntervalDays {
+			stats.Mature.Add(passed)
+		} else {
+			stats.Young.Add(passed)
+		}
+
+		bucket, ok := byType[log.CardType]
+		if !ok {
+			bucket = &model.RetentionBucket{Key: string(log.CardType)}
+			byType[log.CardType] = bucket
+		}
+		bucket.Add(passed)
+
+		for _, tagID := range cardTags[log.CardID] {
+			bucket, ok := byTag[tagID]
+			if !ok {
+				bucket = &model.RetentionBucket{Key: tagNames[tagID], TagID: tagID}
+				byTag[tagID] = bucket
+			}
+			bucket.Add(passed)
+		}
+
+		point := sort.Search(len(forgettingCurveEdges), func(i int) bool {
+			return forgettingCurveEdges[i] > elapsed
+		}) - 1
+		curve[point].reviews++
+		curve[point].elapsed += elapsed
+		if passed {
+			curve[point].passed++
+		}
+
+		if scheduled > 0 {
+			predicted := algorithm.Retrievability(daysToDuration(elapsed), daysToDuration(scheduled))
+			outcome := 0.0
+			if passed {
+				outcome = 1
+				calibratedPassed++
+			}
+			calibrated++
+			predictedSum += predicted
+			brierSum += (predicted - outcome) * (predicted - outcome)
+
+			bin := int(predicted * calibrationBins)
+			if bin >= calibrationBins {
+				bin = calibrationBins - 1
+			}
+			bins[bin].reviews++
+			bins[bin].predicted += predicted
+			if passed {
+				bins[bin].passed++
+			}
+		}
+	}
+
+	stats.ByCardType = make([]*model.RetentionBucket, 0, len(byType))
+	for _, bucket := range byType {
+		stats.ByCardType = append(stats.ByCardType, bucket)
+	}
+	sort.Slice(stats.ByCardType, func(i, j int) bool {
+		return stats.ByCardType[i].Key < stats.ByCardType[j].Key
+	})
+
+	stats.ByTag = sortedTagBuckets(byTag)
+
+	stats.ForgettingCurve = make([]*model.ForgettingCurvePoint, 0, len(curve))
+	for i, c := range curve {
+		if c.reviews == 0 {
+			continue
+		}
+		point := &model.ForgettingCurvePoint{
+			MinDays:        forgettingCurveEdges[i],
+			AvgElapsedDays: c.elapsed / float64(c.reviews),
+			Reviews:        c.reviews,
+			RecallRate:     float64(c.passed) / float64(c.reviews),
+		}
+		if i+1 < len(forgettingCurveEdges) {
+			point.MaxDays = forgettingCurveEdges[i+1]
+		}
+		stats.ForgettingCurve = append(stats.ForgettingCurve, point)
+	}
+
+	stats.Calibration = &model.RetentionCalibration{Reviews: calibrated, Bins: make([]*model.CalibrationBin, 0, calibrationBins)}
+	if calibrated > 0 {
+		stats.Calibration.AvgPredicted = predictedSum / float64(calibrated)
+		stats.Calibration.ActualRecall = float64(calibratedPassed) / float64(calibrated)
+		stats.Calibration.BrierScore = brierSum / float64(calibrated)
+		for i, b := range bins {
+			if b.reviews == 0 {
+				continue
+			}
+			bin := &model.CalibrationBin{
+				MinPredicted: float64(i) / calibrationBins,
+				MaxPredicted: float64(i+1) / calibrationBins,
+				Reviews:      b.reviews,
+				AvgPredicted: b.predicted / float64(b.reviews),
+				ActualRecall: float64(b.passed) / float64(b.reviews),
+			}
+			stats.Calibration.CalibrationError += float64(b.reviews) / float64(calibrated) * math.Abs(bin.AvgPredicted-bin.ActualRecall)
+			stats.Calibration.Bins = append(stats.Calibration.Bins, bin)
+		}
+	}
+
+	return stats, nil
+}
+
+// 每张卡片所属的标签（包含上级标签）以及标签路径，没有设置标签仓库时为空
+func (s *ReviewLogsService) cardTagIndex(userID uint) (map[uint]string, map[uint][]uint, error) {
+	tagNames := make(map[uint]string)
+	cardTags := make(map[uint][]uint)
+	if s.tagsRepo == nil {
+		return tagNames, cardTags, nil
+	}
+	tags, err := s.tagsRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	links, err := s.tagsRepo.FindCardTagLinks(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, tag := range tags {
+		tagNames[tag.ID] = tag.Name
+	}
+	for tagID, cards := range tagSubtreeCards(tags, links) {
+		for cardID := range cards {
+			cardTags[cardID] = append(cardTags[cardID], tagID)
+		}
+	}
+	return tagNames, cardTags, nil
+}
+
+// 按标签的通过率排序，通过率低的在前
+func sortedTagBuckets(byTag map[uint]*model.RetentionBucket) []*model.RetentionBucket {
+	buckets := make([]*model.RetentionBucket, 0, len(byTag))
+	for _, bucket := range byTag {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		a, b := buckets[i], buckets[j]
+		if *a.PassRate != *b.PassRate {
+			return *a.PassRate < *b.PassRate
+		}
+		return a.Key < b.Key
+	})
+	return buckets
+}
+
+// 复习前距上次复习的天数和计划间隔天数；学习阶段的复习和无法确定间隔的旧日志返回 false。
+// 旧日志没有记录间隔，使用同一卡片上一条日志的时间推算，计划间隔返回 0
+func retentionInterval(log, prev *repository.RetentionLog) (elapsed, scheduled float64, ok bool) {
+	if log.ScheduledDays > 0 {
+		if log.NewCard {
+			return 0, 0, false
+		}
+		return math.Max(log.ElapsedDays, 0), log.ScheduledDays, true
+	}
+	if prev == nil || prev.CardID != log.CardID {
+		return 0, 0, false
+	}
+	return math.Max(log.ReviewTime.Sub(prev.ReviewTime).Hours()/24, 0), 0, true
+}
+
+// 将天数转换为时长
+func daysToDuration(days float64) time.Duration {
+	return time.Duration(days * float64(24*time.Hour))
+}
@@ -7,7 +7,10 @@ import (
 )
 
 type ReviewLogsService struct {
-	repo *repository.ReviewLogsRepository
+	repo      *repository.ReviewLogsRepository
+	tagsRepo  *repository.TagsRepository
+	decksRepo *repository.DecksRepository
+	userRepo  *repository.UserRepository
 }
 
 func NewReviewLogsService(repo *repository.ReviewLogsRepository) *ReviewLogsService {
@@ -16,6 +19,21 @@ func NewReviewLogsService(repo *repository.ReviewLogsRepository) *ReviewLogsServ
 	}
 }
 
+// 设置标签仓库，设置后保持率分析包含按标签的统计
+func (s *ReviewLogsService) SetTagsRepository(tagsRepo *repository.TagsRepository) {
+	s.tagsRepo = tagsRepo
+}
+
+// 设置卡组仓库，设置后到期预测可按卡组分组
+func (s *ReviewLogsService) SetDecksRepository(decksRepo *repository.DecksRepository) {
+	s.decksRepo = decksRepo
+}
+
+// 设置用户仓库，设置后按用户的时区划分日期
+func (s *ReviewLogsService) SetUserRepository(userRepo *repository.UserRepository) {
+	s.userRepo = userRepo
+}
+
 // 创建复习日志
 func (s *ReviewLogsService) CreateReviewLog(log *model.ReviewLog) error {
 	return s.repo.Create(log)
@@ -44,8 +62,8 @@ func (s *ReviewLogsService) GetReviewStats(userID uint, period string) (map[stri
 		startTime = now.AddDate(0, 0, -7) // 默认一周
 	}
 
-	// 获取基础统计
-	totalReviews, err := s.repo.CountReviewsByTimeRange(userID, startTime, now)
+	// 获取基础统计（按天汇总，包含开始日期当天的全部复习）
+	totalReviews, totalDuration, err := s.repo.GetDailyTotals(userID, startTime, now)
 	if err != nil {
 		return nil, err
 	}
@@ -55,11 +73,6 @@ func (s *ReviewLogsService) GetReviewStats(userID uint, period string) (map[stri
 		return nil, err
 	}
 
-	totalDuration, err := s.repo.GetTotalDuration(userID, startTime, now)
-	if err != nil {
-		return nil, err
-	}
-
 	// 获取每日复习数据
 	dailyStats, err := s.repo.GetDailyReviewStats(userID, startTime, now)
 	if err != nil {
@@ -129,8 +142,9 @@ func (s *ReviewLogsService) GetLearningProgress(userID uint) (map[string]interfa
 
 // 获取复习热力图数据
 func (s *ReviewLogsService) GetReviewHeatmap(userID uint, year int) (map[string]int, error) {
-	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC)
+	loc := userLocation(s.userRepo, userID)
+	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	endDate := time.Date(year, 12, 31, 0, 0, 0, 0, loc)
 
 	return s.repo.GetReviewHeatmapData(userID, startDate, endDate)
 }
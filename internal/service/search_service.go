@@ -0,0 +1,144 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/fulltext"
+	"errors"
+	"sync"
+	"time"
+)
+
+// 搜索相关的常量
+const (
+	snippetLength    = 120 // 内容片段的最大字数
+	maxCachedIndexes = 100 // 内存中最多保留的用户索引数
+)
+
+// SearchService 卡片全文搜索：MySQL 使用 ngram 全文索引，其他数据库使用内存倒排索引
+type SearchService struct {
+	cardsRepo   *repository.LearningCardsRepository
+	useFullText bool
+
+	mu      sync.Mutex
+	indexes map[uint]*userIndex
+}
+
+// 用户的内存索引及建立时的卡片数和最后修改时间
+type userIndex struct {
+	index     *fulltext.Index
+	total     int64
+	updatedAt time.Time
+}
+
+func NewSearchService(cardsRepo *repository.LearningCardsRepository) *SearchService {
+	return &SearchService{
+		cardsRepo:   cardsRepo,
+		useFullText: cardsRepo.SupportsFullText(),
+		indexes:     make(map[uint]*userIndex),
+	}
+}
+
+// 搜索卡片标题和内容，按相关度排序并返回高亮片段
+func (s *SearchService) SearchCards(userID uint, query string, page, pageSize int) ([]*model.CardSearchHit, int64, error) {
+	terms := fulltext.Terms(query)
+	if len(terms) == 0 {
+		return nil, 0, errors.New("搜索内容不能为空")
+	}
+
+	var scores []repository.CardScore
+	var total int64
+	var err error
+	if s.useFullText {
+		scores, total, err = s.cardsRepo.FullTextSearch(userID, terms, page, pageSize)
+	} else {
+		scores, total, err = s.searchIndex(userID, query, page, pageSize)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(scores))
+	for i, score := range scores {
+		ids[i] = score.ID
+	}
+	cards, err := s.cardsRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[uint]*model.LearningCard, len(cards))
+	for _, card := range cards {
+		byID[card.ID] = card
+	}
+
+	hits := make([]*model.CardSearchHit, 0, len(scores))
+	for _, score := range scores {
+		card, ok := byID[score.ID]
+		if !ok {
+			continue
+		}
+		hits = append(hits, &model.CardSearchHit{
+			Card:           card,
+			Score:          score.Score,
+			TitleSnippet:   fulltext.Snippet(card.Title, terms, 0),
+			ContentSnippet: fulltext.Snippet(card.Content, terms, snippetLength),
+		})
+	}
+	return hits, total, nil
+}
+
+// 使用内存索引搜索，卡片有变化时重建用户的索引。
+// 索引建好后不再修改，锁只保护 indexes 的读写，读取卡片和建立索引在锁外进行，不阻塞其他用户的搜索
+func (s *SearchService) searchIndex(userID uint, query string, page, pageSize int) ([]repository.CardScore, int64, error) {
+	total, updatedAt, err := s.cardsRepo.SearchFingerprint(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.mu.Lock()
+	cached, ok := s.indexes[userID]
+	s.mu.Unlock()
+
+	if !ok || cached.total != total || !cached.updatedAt.Equal(updatedAt) {
+		cards, err := s.cardsRepo.FindSearchDocuments(userID)
+		if err != nil {
+			return nil, 0, err
+		}
+		index := fulltext.NewIndex()
+		for _, card := range cards {
+			index.Add(fulltext.Document{ID: card.ID, Title: card.Title, Content: card.Content})
+		}
+		cached = &userIndex{index: index, total: total, updatedAt: updatedAt}
+		s.storeIndex(userID, cached)
+	}
+
+	hits := cached.index.Search(query)
+	start := (page - 1) * pageSize
+	if start > len(hits) {
+		start = len(hits)
+	}
+	end := start + pageSize
+	if end > len(hits) {
+		end = len(hits)
+	}
+	scores := make([]repository.CardScore, 0, end-start)
+	for _, hit := range hits[start:end] {
+		scores = append(scores, repository.CardScore{ID: hit.ID, Score: hit.Score})
+	}
+	return scores, int64(len(hits)), nil
+}
+
+// 保存用户重建的索引；并发重建时不覆盖已经保存的更新的索引
+func (s *SearchService) storeIndex(userID uint, index *userIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.indexes[userID]
+	if ok && current.updatedAt.After(index.updatedAt) {
+		return
+	}
+	if !ok && len(s.indexes) >= maxCachedIndexes {
+		s.indexes = make(map[uint]*userIndex)
+	}
+	s.indexes[userID] = index
+}
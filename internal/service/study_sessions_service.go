@@ -0,0 +1,127 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"ReMindful/pkg/cardquery"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 进行中的会话超过该时长没有心跳或复习时视为已放弃
+const sessionIdleTimeout = 30 * time.Minute
+
+type StudySessionsService struct {
+	repo         *repository.StudySessionsRepository
+	decksService *DecksService
+}
+
+func NewStudySessionsService(repo *repository.StudySessionsRepository, decksService *DecksService) *StudySessionsService {
+	return &StudySessionsService{
+		repo:         repo,
+		decksService: decksService,
+	}
+}
+
+// 开始学习会话，未指定计划卡片数时使用卡组学习队列的长度；同时结束用户长时间没有活动的会话
+func (s *StudySessionsService) StartSession(userID uint, req *model.StartSessionRequest) (*model.StudySession, error) {
+	now := time.Now()
+	if err := s.repo.AbandonIdle(userID, now.Add(-sessionIdleTimeout)); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(req.FilterQuery)
+	if query != "" {
+		if _, err := cardquery.Parse(query); err != nil {
+			return nil, err
+		}
+	}
+
+	session := &model.StudySession{
+		UserID:       userID,
+		DeckID:       req.DeckID,
+		FilterQuery:  query,
+		Device:       strings.TrimSpace(req.Device),
+		Status:       model.SessionActive,
+		StartedAt:    now,
+		LastActiveAt: now,
+		PlannedCards: req.PlannedCards,
+	}
+	if req.DeckID != 0 {
+		deck, err := s.decksService.GetDeckByID(req.DeckID)
+		if err != nil {
+			return nil, errors.New("卡组不存在")
+		}
+		if deck.UserID != userID {
+			return nil, errors.New("无权限使用此卡组")
+		}
+		if session.PlannedCards == 0 {
+			cards, err := s.decksService.GetStudyQueue(deck)
+			if err != nil {
+				return nil, err
+			}
+			session.PlannedCards = len(cards)
+		}
+	}
+
+	if err := s.repo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// 根据ID获取学习会话
+func (s *StudySessionsService) GetSession(id uint) (*model.StudySession, error) {
+	return s.repo.FindByID(id)
+}
+
+// 分页获取用户的学习会话
+func (s *StudySessionsService) GetSessions(userID uint, page, pageSize int) ([]*model.StudySession, int64, error) {
+	if err := s.repo.AbandonIdle(userID, time.Now().Add(-sessionIdleTimeout)); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.FindByUserID(userID, page, pageSize)
+}
+
+// 会话心跳，保持会话为进行中
+func (s *StudySessionsService) Heartbeat(session *model.StudySession) error {
+	if session.Status != model.SessionActive {
+		return errors.New("学习会话已结束")
+	}
+	session.LastActiveAt = time.Now()
+	return s.repo.Touch(session.ID, session.LastActiveAt)
+}
+
+// 结束学习会话并返回汇总
+func (s *StudySessionsService) FinishSession(session *model.StudySession) (*model.StudySessionSummary, error) {
+	if session.Status != model.SessionActive {
+		return nil, errors.New("学习会话已结束")
+	}
+	now := time.Now()
+	session.Status = model.SessionFinished
+	session.EndedAt = &now
+	if err := s.repo.Finish(session); err != nil {
+		return nil, err
+	}
+	return s.Summarize(session), nil
+}
+
+// 计算学习会话的汇总
+func (s *StudySessionsService) Summarize(session *model.StudySession) *model.StudySessionSummary {
+	summary := &model.StudySessionSummary{StudySession: session}
+	if remaining := session.PlannedCards - session.CompletedCards; remaining > 0 {
+		summary.Remaining = remaining
+	}
+	if session.CompletedCards > 0 {
+		accuracy := float64(session.PassedCards) / float64(session.CompletedCards)
+		summary.Accuracy = &accuracy
+		summary.AvgSecondsPerCard = float64(session.StudySeconds) / float64(session.CompletedCards)
+	}
+	end := session.LastActiveAt
+	if session.EndedAt != nil {
+		end = *session.EndedAt
+	}
+	summary.ElapsedMinutes = end.Sub(session.StartedAt).Minutes()
+	return summary
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"errors"
+	"sort"
+	"time"
+)
+
+// 标签统计的复习窗口（天）
+const (
+	DefaultTagStatsDays = 30
+	MaxTagStatsDays     = 365
+)
+
+// GetTagStats 统计用户每个标签的学习情况：卡片状态取当前值，复习次数、保持率和学习时长取最近 days 天的复习日志。
+// 上级标签的统计包含其全部下级标签的卡片，同一张卡片只计算一次
+func (s *TagsService) GetTagStats(userID uint, days int, sortBy string) (*model.TagStatsResult, error) {
+	if days <= 0 || days > MaxTagStatsDays {
+		return nil, errors.New("统计天数必须在1到365之间")
+	}
+	less, err := tagStatsLess(sortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days)
+	endOfToday := startOfDay(now, userLocation(s.userRepo, userID)).AddDate(0, 0, 1)
+
+	tags, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	direct, err := s.repo.GetTagUsageStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.FindCardTagLinks(userID)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := s.repo.FindTaggedCards(userID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.repo.SumReviewsByCard(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	retention, err := s.cardRetention(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	subtreeCards := tagSubtreeCards(tags, links)
+
+	cardsByID := make(map[uint]*model.LearningCard, len(cards))
+	for _, card := range cards {
+		cardsByID[card.ID] = card
+	}
+	summaryByCard := make(map[uint]repository.CardReviewSummary, len(summaries))
+	for _, summary := range summaries {
+		summaryByCard[summary.CardID] = summary
+	}
+
+	result := &model.TagStatsResult{Days: days, Since: since, Tags: make([]*model.TagStats, 0, len(tags))}
+	for _, tag := range tags {
+		stats := &model.TagStats{
+			TagID:           tag.ID,
+			Name:            tag.Name,
+			ColorCode:       tag.ColorCode,
+			DirectCardCount: direct[tag.ID],
+		}
+		var reviewed, retained, passed int
+		var easeSum, difficultySum float64
+		for cardID := range subtreeCards[tag.ID] {
+			card, ok := cardsByID[cardID]
+			if !ok {
+				continue
+			}
+			stats.CardCount++
+			if !card.Suspended && card.NextReview.Before(endOfToday) {
+				stats.DueToday++
+			}
+			if card.ReviewCount == 0 || card.LastReviewAt.IsZero() {
+				stats.NewCards++
+			} else {
+				reviewed++
+				easeSum += card.EaseFactor
+				difficultySum += card.Difficulty
+				if card.NextReview.Sub(card.LastReviewAt) >= model.MatureIntervalDays*24*time.Hour {
+					stats.MatureCards++
+				} else {
+					stats.YoungCards++
+				}
+			}
+			if summary, ok := summaryByCard[cardID]; ok {
+				stats.Reviews += summary.Reviews
+				stats.StudySeconds += summary.Duration
+			}
+			if counts, ok := retention[cardID]; ok {
+				retained += counts.reviews
+				passed += counts.passed
+			}
+		}
+		if reviewed > 0 {
+			stats.AvgEaseFactor = easeSum / float64(reviewed)
+			stats.AvgDifficulty = difficultySum / float64(reviewed)
+		}
+		if retained > 0 {
+			rate := float64(passed) / float64(retained)
+			stats.RetentionRate = &rate
+		}
+		result.Tags = append(result.Tags, stats)
+	}
+
+	sort.SliceStable(result.Tags, func(i, j int) bool {
+		return less(result.Tags[i], result.Tags[j])
+	})
+	return result, nil
+}
+
+// 卡片在统计窗口内计入保持率的复习次数和其中及格的次数
+type retentionCounts struct {
+	reviews int
+	passed  int
+}
+
+// 按卡片统计 since 之后的保持率，与保持率分析一样只统计学习阶段之后的复习
+func (s *TagsService) cardRetention(userID uint, since time.Time) (map[uint]retentionCounts, error) {
+	counts := make(map[uint]retentionCounts)
+	if s.reviewLogsRepo == nil {
+		return counts, nil
+	}
+	logs, err := s.reviewLogsRepo.FindRetentionLogs(userID, since, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	var prev *repository.RetentionLog
+	for i := range logs {
+		log := &logs[i]
+		_, _, ok := retentionInterval(log, prev)
+		prev = log
+		if !ok {
+			continue
+		}
+		c := counts[log.CardID]
+		c.reviews++
+		if log.Performance >= model.PassPerformance {
+			c.passed++
+		}
+		counts[log.CardID] = c
+	}
+	return counts, nil
+}
+
+// 计算每个标签及其全部下级标签包含的卡片
+func tagSubtreeCards(tags []*model.Tag, links []model.CardTag) map[uint]map[uint]bool {
+	tagsByID := make(map[uint]*model.Tag, len(tags))
+	tagsByName := make(map[string]*model.Tag, len(tags))
+	for _, tag := range tags {
+		tagsByID[tag.ID] = tag
+		tagsByName[tag.Name] = tag
+	}
+
+	subtreeCards := make(map[uint]map[uint]bool, len(tags))
+	addCard := func(tagID, cardID uint) {
+		if subtreeCards[tagID] == nil {
+			subtreeCards[tagID] = make(map[uint]bool)
+		}
+		subtreeCards[tagID][cardID] = true
+	}
+	for _, link := range links {
+		tag, ok := tagsByID[link.TagID]
+		if !ok {
+			continue
+		}
+		addCard(tag.ID, link.LearningCardID)
+		for _, ancestor := range model.TagAncestors(tag.Name) {
+			if parent, ok := tagsByName[ancestor]; ok {
+				addCard(parent.ID, link.LearningCardID)
+			}
+		}
+	}
+	return subtreeCards
+}
+
+// 标签统计的排序比较函数，排序值相同时按标签路径排序
+func tagStatsLess(sortBy string) (func(a, b *model.TagStats) bool, error) {
+	switch sortBy {
+	case "", model.TagStatsSortRetention:
+		// 没有复习记录的标签排在最后
+		return func(a, b *model.TagStats) bool {
+			switch {
+			case a.RetentionRate == nil || b.RetentionRate == nil:
+				if (a.RetentionRate == nil) != (b.RetentionRate == nil) {
+					return b.RetentionRate == nil
+				}
+			case *a.RetentionRate != *b.RetentionRate:
+				return *a.RetentionRate < *b.RetentionRate
+			}
+			return a.Name < b.Name
+		}, nil
+	case model.TagStatsSortCards:
+		return func(a, b *model.TagStats) bool {
+			if a.CardCount != b.CardCount {
+				return a.CardCount > b.CardCount
+			}
+			return a.Name < b.Name
+		}, nil
+	case model.TagStatsSortDue:
+		return func(a, b *model.TagStats) bool {
+			if a.DueToday != b.DueToday {
+				return a.DueToday > b.DueToday
+			}
+			return a.Name < b.Name
+		}, nil
+	case model.TagStatsSortName:
+		return func(a, b *model.TagStats) bool {
+			return a.Name < b.Name
+		}, nil
+	}
+	return nil, errors.New("不支持的排序方式")
+}
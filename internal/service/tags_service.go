@@ -3,32 +3,61 @@ package service
 import (
 	"ReMindful/internal/model"
 	"ReMindful/internal/repository"
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/redis/go-redis/v9"
 )
 
 type TagsService struct {
-	repo *repository.TagsRepository
+	repo           *repository.TagsRepository
+	redis          *redis.Client
+	reviewLogsRepo *repository.ReviewLogsRepository
+	userRepo       *repository.UserRepository
 }
 
-func NewTagsService(repo *repository.TagsRepository) *TagsService {
+func NewTagsService(repo *repository.TagsRepository, redis *redis.Client) *TagsService {
 	return &TagsService{
-		repo: repo,
+		repo:  repo,
+		redis: redis,
 	}
 }
 
+// 设置复习日志仓库，设置后标签统计包含记忆保持率
+func (s *TagsService) SetReviewLogsRepository(reviewLogsRepo *repository.ReviewLogsRepository) {
+	s.reviewLogsRepo = reviewLogsRepo
+}
+
+// 设置用户仓库，设置后按用户的时区计算今日到期数
+func (s *TagsService) SetUserRepository(userRepo *repository.UserRepository) {
+	s.userRepo = userRepo
+}
+
 // 创建标签
 func (s *TagsService) CreateTag(tag *model.Tag) error {
 	// 校验参数
 	if tag.UserID == 0 {
 		return errors.New("用户ID不能为0")
 	}
-	if tag.Name == "" {
-		return errors.New("标签名称不能为空")
+	name, err := model.NormalizeTagName(tag.Name)
+	if err != nil {
+		return err
 	}
+	tag.Name = name
 
-	// 检查同一用户下是否已存在相同名称的标签
-	existingTag, err := s.repo.FindByNameAndUserID(tag.Name, tag.UserID)
-	if err == nil && existingTag != nil {
+	// 检查同一用户下是否已存在相同名称的标签（包括回收站中的）
+	existing, err := s.repo.FindByNames(tag.UserID, []string{tag.Name})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		if existing[0].DeletedAt.Valid {
+			return errors.New("回收站中已有同名标签，请先恢复或彻底删除")
+		}
 		return errors.New("标签名称已存在")
 	}
 
@@ -45,24 +74,199 @@ func (s *TagsService) GetTagsByUserID(userID uint) ([]*model.Tag, error) {
 	return s.repo.FindByUserID(userID)
 }
 
-// 更新标签
+// 更新标签，名称改变时下级标签的路径随之修改
 func (s *TagsService) UpdateTag(tag *model.Tag) error {
-	if tag.Name == "" {
-		return errors.New("标签名称不能为空")
+	name, err := model.NormalizeTagName(tag.Name)
+	if err != nil {
+		return err
 	}
+	tag.Name = name
 
-	// 检查同一用户下是否已存在相同名称的标签（排除当前标签）
-	existingTag, err := s.repo.FindByNameAndUserID(tag.Name, tag.UserID)
-	if err == nil && existingTag != nil && existingTag.ID != tag.ID {
-		return errors.New("标签名称已存在")
+	current, err := s.repo.FindByID(tag.ID)
+	if err != nil {
+		return errors.New("标签不存在")
+	}
+	if current.Name == tag.Name {
+		return s.repo.Update(tag)
+	}
+	return s.rename(tag, current.Name)
+}
+
+// 移动标签及其下级标签到新的上级标签下，parentID 为 0 时移动到顶级
+func (s *TagsService) MoveTag(tag *model.Tag, parentID uint) error {
+	name := model.TagLeaf(tag.Name)
+	if parentID != 0 {
+		parent, err := s.repo.FindByID(parentID)
+		if err != nil || parent.UserID != tag.UserID {
+			return errors.New("上级标签不存在")
+		}
+		name = parent.Name + model.TagSeparator + name
+	}
+	if name == tag.Name {
+		return nil
+	}
+	if _, err := model.NormalizeTagName(name); err != nil {
+		return err
+	}
+
+	oldName := tag.Name
+	tag.Name = name
+	return s.rename(tag, oldName)
+}
+
+// 重命名标签子树：检查新路径不与其他标签冲突后在事务中修改
+func (s *TagsService) rename(tag *model.Tag, oldName string) error {
+	if model.IsTagDescendant(tag.Name, oldName) {
+		return errors.New("不能将标签移动到自己的下级标签下")
+	}
+
+	subtree, err := s.repo.FindSubtree(tag.UserID, oldName)
+	if err != nil {
+		return err
+	}
+	inSubtree := make(map[uint]bool, len(subtree))
+	tagIDs := make([]uint, len(subtree))
+	names := make([]string, len(subtree))
+	for i, t := range subtree {
+		inSubtree[t.ID] = true
+		tagIDs[i] = t.ID
+		names[i] = tag.Name + strings.TrimPrefix(t.Name, oldName)
+		if utf8.RuneCountInString(names[i]) > model.MaxTagNameLength {
+			return errors.New("标签名称过长")
+		}
+	}
+	conflicts, err := s.repo.FindByNames(tag.UserID, names)
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		if !inSubtree[conflict.ID] {
+			return fmt.Errorf("标签名称已存在: %s", conflict.Name)
+		}
+	}
+
+	if err := s.repo.Rename(tag, oldName); err != nil {
+		return err
+	}
+	return s.clearCardsCache(tagIDs)
+}
+
+// 合并标签：source 及其下级标签并入 target，卡片关联转移到 target 下对应的标签
+func (s *TagsService) MergeTag(source, target *model.Tag) error {
+	if source.UserID != target.UserID {
+		return errors.New("无权限操作此标签")
+	}
+	if source.ID == target.ID || model.IsTagDescendant(target.Name, source.Name) {
+		return errors.New("不能将标签合并到自己或自己的下级标签")
+	}
+
+	subtree, err := s.repo.FindSubtree(source.UserID, source.Name)
+	if err != nil {
+		return err
+	}
+	tagIDs := make([]uint, len(subtree))
+	for i, t := range subtree {
+		tagIDs[i] = t.ID
+		if utf8.RuneCountInString(target.Name+strings.TrimPrefix(t.Name, source.Name)) > model.MaxTagNameLength {
+			return errors.New("标签名称过长")
+		}
+	}
+	// 先取得受影响的卡片，合并后原标签的关联已不存在
+	cardIDs, err := s.repo.FindCardIDsByTags(tagIDs)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Merge(source, target); err != nil {
+		return err
+	}
+	s.clearCards(cardIDs)
+	return nil
+}
+
+// 获取用户的标签树，上级标签已删除时以 id 为 0 的节点代替
+func (s *TagsService) GetTagTree(userID uint) ([]*model.TagNode, error) {
+	tags, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.GetTagUsageStats(userID)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	nodes := make(map[string]*model.TagNode, len(tags))
+	var roots []*model.TagNode
+	// 取得路径对应的节点，不存在时创建并挂到上级节点下
+	var nodeFor func(path string) *model.TagNode
+	nodeFor = func(path string) *model.TagNode {
+		if node, ok := nodes[path]; ok {
+			return node
+		}
+		node := &model.TagNode{Name: model.TagLeaf(path), Path: path, Children: []*model.TagNode{}}
+		nodes[path] = node
+		if parent := model.TagParent(path); parent != "" {
+			p := nodeFor(parent)
+			p.Children = append(p.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+		return node
+	}
+	for _, tag := range tags {
+		node := nodeFor(tag.Name)
+		node.ID = tag.ID
+		node.ColorCode = tag.ColorCode
+		node.CardCount = counts[tag.ID]
 	}
+	return roots, nil
+}
 
-	return s.repo.Update(tag)
+// 清除使用这些标签的卡片缓存
+func (s *TagsService) clearCardsCache(tagIDs []uint) error {
+	if s.redis == nil {
+		return nil
+	}
+	cardIDs, err := s.repo.FindCardIDsByTags(tagIDs)
+	if err != nil {
+		return err
+	}
+	s.clearCards(cardIDs)
+	return nil
 }
 
-// 删除标签
-func (s *TagsService) DeleteTag(id uint) error {
-	return s.repo.Delete(id)
+// 清除卡片缓存
+func (s *TagsService) clearCards(cardIDs []uint) {
+	if s.redis == nil {
+		return
+	}
+	for _, id := range cardIDs {
+		s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, id))
+	}
+}
+
+// 删除标签（移入回收站）
+func (s *TagsService) DeleteTag(tag *model.Tag) error {
+	// 下级标签一起移入回收站
+	subtree, err := s.repo.FindSubtree(tag.UserID, tag.Name)
+	if err != nil {
+		return err
+	}
+	var ids []uint
+	for _, t := range subtree {
+		if !t.DeletedAt.Valid {
+			ids = append(ids, t.ID)
+		}
+	}
+	if err := s.repo.Delete(ids); err != nil {
+		return err
+	}
+
+	// 清除使用这些标签的卡片缓存
+	return s.clearCardsCache(ids)
 }
 
 // 根据名称搜索标签
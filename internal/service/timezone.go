@@ -0,0 +1,28 @@
+package service
+
+import (
+	"ReMindful/internal/repository"
+	"time"
+)
+
+// 加载用户设置的时区，用户不存在或时区无效时使用服务器时区
+func userLocation(userRepo *repository.UserRepository, userID uint) *time.Location {
+	if userRepo == nil {
+		return time.Local
+	}
+	user, err := userRepo.FindByID(userID)
+	if err != nil || user.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// 用户时区当天零点
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
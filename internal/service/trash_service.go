@@ -0,0 +1,244 @@
+package service
+
+import (
+	"ReMindful/internal/config"
+	"ReMindful/internal/model"
+	"ReMindful/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 每批彻底删除的条目数
+const purgeBatchSize = 500
+
+// TrashService 回收站：卡片和标签删除后先进入回收站，可恢复，超过保留天数后由清理任务彻底删除
+type TrashService struct {
+	repo      *repository.TrashRepository
+	decksRepo *repository.DecksRepository
+	tagsRepo  *repository.TagsRepository
+	redis     *redis.Client
+	cfg       config.TrashConfig
+}
+
+func NewTrashService(repo *repository.TrashRepository, decksRepo *repository.DecksRepository, tagsRepo *repository.TagsRepository, redis *redis.Client, cfg config.TrashConfig) *TrashService {
+	return &TrashService{
+		repo:      repo,
+		decksRepo: decksRepo,
+		tagsRepo:  tagsRepo,
+		redis:     redis,
+		cfg:       cfg,
+	}
+}
+
+// 条目的彻底删除时间，未开启自动清理时返回 nil
+func (s *TrashService) purgeAt(deletedAt time.Time) *time.Time {
+	if s.cfg.RetentionDays <= 0 {
+		return nil
+	}
+	t := deletedAt.AddDate(0, 0, s.cfg.RetentionDays)
+	return &t
+}
+
+// 获取回收站中的卡片
+func (s *TrashService) GetTrashedCards(userID uint, page, pageSize int) ([]*model.TrashedCard, int64, error) {
+	cards, total, err := s.repo.FindCards(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*model.TrashedCard, len(cards))
+	for i, card := range cards {
+		items[i] = &model.TrashedCard{LearningCard: card, PurgeAt: s.purgeAt(card.DeletedAt.Time)}
+	}
+	return items, total, nil
+}
+
+// 获取回收站中的标签
+func (s *TrashService) GetTrashedTags(userID uint, page, pageSize int) ([]*model.TrashedTag, int64, error) {
+	tags, total, err := s.repo.FindTags(userID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]uint, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	counts, err := s.repo.CountTagCards(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*model.TrashedTag, len(tags))
+	for i, tag := range tags {
+		items[i] = &model.TrashedTag{Tag: tag, CardCount: counts[tag.ID], PurgeAt: s.purgeAt(tag.DeletedAt.Time)}
+	}
+	return items, total, nil
+}
+
+// 获取回收站中的卡片
+func (s *TrashService) GetTrashedCard(id uint) (*model.LearningCard, error) {
+	card, err := s.repo.FindCardByID(id)
+	if err != nil {
+		return nil, errors.New("回收站中没有此卡片")
+	}
+	return card, nil
+}
+
+// 获取回收站中的标签
+func (s *TrashService) GetTrashedTag(id uint) (*model.Tag, error) {
+	tag, err := s.repo.FindTagByID(id)
+	if err != nil {
+		return nil, errors.New("回收站中没有此标签")
+	}
+	return tag, nil
+}
+
+// 恢复卡片：放回原卡组（删除时位于筛选卡组中的放回其原卡组），原卡组已删除时放入默认卡组
+func (s *TrashService) RestoreCard(card *model.LearningCard) error {
+	deckID := card.DeckID
+	if card.OriginalDeckID != 0 {
+		deckID = card.OriginalDeckID
+	}
+	deck, err := s.decksRepo.FindByID(deckID)
+	if err != nil || deck.UserID != card.UserID || deck.IsFiltered {
+		if deck, err = s.decksRepo.FindOrCreateDefault(card.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RestoreCard(card.ID, deck.ID); err != nil {
+		return err
+	}
+	s.clearCardsCache([]uint{card.ID})
+	return nil
+}
+
+// 恢复标签以及和它一起删除的下级标签，同一用户下已有同名标签时不能恢复
+func (s *TrashService) RestoreTag(tag *model.Tag) error {
+	subtree, err := s.tagsRepo.FindSubtree(tag.UserID, tag.Name)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	var restore []*model.Tag
+	for _, t := range subtree {
+		switch {
+		case !t.DeletedAt.Valid:
+			existing[t.Name] = true
+		case t.ID == tag.ID || t.DeletedAt.Time.Equal(tag.DeletedAt.Time):
+			restore = append(restore, t)
+		}
+	}
+	ids := make([]uint, 0, len(restore))
+	for _, t := range restore {
+		if existing[t.Name] {
+			return errors.New("标签名称已存在")
+		}
+		ids = append(ids, t.ID)
+	}
+
+	if err := s.repo.RestoreTags(ids); err != nil {
+		return err
+	}
+	cardIDs, err := s.tagsRepo.FindCardIDsByTags(ids)
+	if err != nil {
+		return err
+	}
+	s.clearCardsCache(cardIDs)
+	return nil
+}
+
+// 彻底删除回收站中的卡片
+func (s *TrashService) DeleteCard(id uint) error {
+	_, err := s.repo.PurgeCards([]uint{id})
+	return err
+}
+
+// 彻底删除回收站中的标签
+func (s *TrashService) DeleteTag(id uint) error {
+	_, err := s.repo.PurgeTags([]uint{id})
+	return err
+}
+
+// 清空用户的回收站
+func (s *TrashService) EmptyTrash(userID uint) (*model.TrashPurgeResult, error) {
+	return s.purge(userID, time.Now())
+}
+
+// 彻底删除所有用户回收站中超过保留天数的条目
+func (s *TrashService) PurgeExpired() (*model.TrashPurgeResult, error) {
+	return s.purge(0, time.Now().AddDate(0, 0, -s.cfg.RetentionDays))
+}
+
+// 分批彻底删除删除时间早于 before 的条目，userID 为 0 时不限用户
+func (s *TrashService) purge(userID uint, before time.Time) (*model.TrashPurgeResult, error) {
+	result := &model.TrashPurgeResult{}
+	for {
+		ids, err := s.repo.FindCardIDs(userID, before, purgeBatchSize)
+		if err != nil {
+			return result, err
+		}
+		purged, err := s.repo.PurgeCards(ids)
+		result.Cards += purged
+		if err != nil {
+			return result, err
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	for {
+		ids, err := s.repo.FindTagIDs(userID, before, purgeBatchSize)
+		if err != nil {
+			return result, err
+		}
+		purged, err := s.repo.PurgeTags(ids)
+		result.Tags += purged
+		if err != nil {
+			return result, err
+		}
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// 启动后台清理任务，按配置的间隔彻底删除过期条目，ctx 取消时退出
+func (s *TrashService) StartPurgeJob(ctx context.Context) {
+	if s.cfg.RetentionDays <= 0 || s.cfg.PurgeInterval <= 0 {
+		log.Println("Trash purge job disabled")
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.PurgeInterval)
+		defer ticker.Stop()
+		for {
+			result, err := s.PurgeExpired()
+			if err != nil {
+				log.Printf("Warning: Failed to purge trash: %v", err)
+			} else if result.Cards > 0 || result.Tags > 0 {
+				log.Printf("Purged %d cards and %d tags from trash", result.Cards, result.Tags)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// 清除卡片缓存
+func (s *TrashService) clearCardsCache(ids []uint) {
+	if s.redis == nil {
+		return
+	}
+	for _, id := range ids {
+		s.redis.Del(context.Background(), fmt.Sprintf("%s%d", cardCacheKeyPrefix, id))
+	}
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"log"
 	"math/rand"
 	"time"
 
@@ -17,10 +19,17 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	passwordResetExpiration    = 15 * time.Minute // 重置密码验证码的有效期
+	passwordResetEmailCooldown = time.Minute      // 同一邮箱两次发送重置验证码的最短间隔
+	passwordResetIPCooldown    = 20 * time.Second // 同一IP两次请求重置验证码的最短间隔
+)
+
 type UserService struct {
 	repo        *repository.UserRepository
 	redis       *redis.Client
 	emailSender *email.EmailSender
+	authService *AuthService
 }
 
 func NewUserService(repo *repository.UserRepository, redis *redis.Client, emailSender *email.EmailSender) *UserService {
@@ -31,6 +40,11 @@ func NewUserService(repo *repository.UserRepository, redis *redis.Client, emailS
 	}
 }
 
+// SetAuthService 设置登录会话服务，登录时由它签发令牌
+func (s *UserService) SetAuthService(authService *AuthService) {
+	s.authService = authService
+}
+
 // SendVerificationCode 发送验证码
 func (s *UserService) SendVerificationCode(email string) error {
 	// 生成6位随机验证码
@@ -109,7 +123,11 @@ func (s *UserService) Register(req *model.RegisterRequest) error {
 }
 
 // Login 用户登录
-func (s *UserService) Login(req *model.LoginRequest, jwtSecret string) (*model.LoginResponse, error) {
+func (s *UserService) Login(req *model.LoginRequest, client model.ClientInfo) (*model.LoginResponse, error) {
+	if s.authService == nil {
+		return nil, errors.New("不支持登录会话")
+	}
+
 	user, err := s.repo.FindByEmail(req.Email)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -130,16 +148,8 @@ func (s *UserService) Login(req *model.LoginRequest, jwtSecret string) (*model.L
 		return nil, err
 	}
 
-	// 生成JWT token
-	token, err := jwt.GenerateToken(user.ID, jwtSecret, 24) // token有效期24小时
-	if err != nil {
-		return nil, err
-	}
-
-	return &model.LoginResponse{
-		Token:    token,
-		ExpireAt: time.Now().Add(24 * time.Hour),
-	}, nil
+	// 创建登录会话，签发访问令牌和刷新令牌
+	return s.authService.CreateSession(user.ID, client)
 }
 
 // GetUserInfo 获取用户信息
@@ -194,3 +204,147 @@ func (s *UserService) UpdateUser(userID uint, req *model.UserInfoRequest) error
 
 	return s.repo.UpdateFields(userID, updates)
 }
+
+func passwordResetKey(email string) string {
+	return fmt.Sprintf("password_reset:%s", email)
+}
+
+// 设置冷却标记，冷却期内已经设置过时返回 false
+func (s *UserService) acquireCooldown(key string, cooldown time.Duration) (bool, error) {
+	return s.redis.SetNX(context.Background(), key, 1, cooldown).Result()
+}
+
+// SendPasswordReset 向已注册的邮箱发送一次性的重置密码验证码。
+// 同一邮箱和同一IP都有冷却时间，防止被用来向他人邮箱轰炸；
+// 查询用户和发送邮件在后台进行，邮箱是否注册不会体现在返回结果和响应时间上
+func (s *UserService) SendPasswordReset(email, ip string) error {
+	ok, err := s.acquireCooldown(fmt.Sprintf("password_reset_cooldown:ip:%s", ip), passwordResetIPCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("请求过于频繁，请稍后再试")
+	}
+	ok, err = s.acquireCooldown(fmt.Sprintf("password_reset_cooldown:email:%s", email), passwordResetEmailCooldown)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("请求过于频繁，请稍后再试")
+	}
+
+	go func() {
+		if err := s.deliverPasswordReset(email); err != nil {
+			log.Printf("发送重置密码邮件失败: %v", err)
+		}
+	}()
+	return nil
+}
+
+// 为已注册的邮箱发送重置验证码；已有未过期的验证码时重新发送同一个，
+// 不会让用户之前收到的验证码失效
+func (s *UserService) deliverPasswordReset(email string) error {
+	if _, err := s.repo.FindByEmail(email); err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+
+	ctx := context.Background()
+	key := passwordResetKey(email)
+	code, err := randomToken(8)
+	if err != nil {
+		return err
+	}
+	created, err := s.redis.SetNX(ctx, key, code, passwordResetExpiration).Result()
+	if err != nil {
+		return err
+	}
+	expiration := passwordResetExpiration
+	if !created {
+		if code, err = s.redis.Get(ctx, key).Result(); err != nil {
+			return err
+		}
+		if expiration, err = s.redis.TTL(ctx, key).Result(); err != nil {
+			return err
+		}
+	}
+
+	return s.emailSender.SendPasswordResetCode(email, code, expiration)
+}
+
+// ResetPassword 用邮件中的验证码重置密码，验证码使用后失效，用户的全部登录会话随之注销
+func (s *UserService) ResetPassword(req *model.ResetPasswordRequest) error {
+	key := passwordResetKey(req.Email)
+	storedCode, err := s.redis.Get(context.Background(), key).Result()
+	if err == redis.Nil {
+		return errors.New("验证码已过期")
+	}
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(storedCode), []byte(req.Code)) != 1 {
+		return errors.New("验证码错误")
+	}
+	// 删除成功的一方才能使用验证码，防止并发请求重复使用
+	deleted, err := s.redis.Del(context.Background(), key).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("验证码已过期")
+	}
+
+	user, err := s.repo.FindByEmail(req.Email)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+
+	return s.setPassword(user.ID, req.NewPassword)
+}
+
+// ChangePassword 验证当前密码后修改密码，用户的全部登录会话随之注销
+func (s *UserService) ChangePassword(userID uint, req *model.ChangePasswordRequest) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+
+	if !jwt.VerifyPassword(req.OldPassword, user.PasswordHash) {
+		return errors.New("当前密码错误")
+	}
+	if req.OldPassword == req.NewPassword {
+		return errors.New("新密码不能与当前密码相同")
+	}
+
+	return s.setPassword(userID, req.NewPassword)
+}
+
+// 保存新密码并注销用户在所有设备上的登录会话。
+// 同时更新 tokens_valid_after，认证中间件会拒绝此前签发的所有访问令牌
+func (s *UserService) setPassword(userID uint, password string) error {
+	hashedPassword := jwt.HashPassword(password)
+	if hashedPassword == "" {
+		return errors.New("密码加密失败")
+	}
+	if err := s.repo.UpdateFields(userID, map[string]interface{}{
+		"password_hash":      hashedPassword,
+		"tokens_valid_after": tokensValidAfter(time.Now()),
+	}); err != nil {
+		return err
+	}
+
+	if s.authService != nil {
+		if _, err := s.authService.LogoutAll(userID, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
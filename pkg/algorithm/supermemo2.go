@@ -76,3 +76,23 @@ func GetReviewQuality(duration time.Duration, isCorrect bool, isHard bool) int {
 	}
 	return Correct // 普通答对
 }
+
+// 到达下次复习时间时的目标记忆保持率
+const TargetRetention = 0.9
+
+// PredictRetention 估算卡片当前的记忆保持率：假设到下次复习时间时保持率为 TargetRetention，
+// 按指数遗忘曲线 R = TargetRetention^(已过时间/计划间隔) 推算
+func PredictRetention(lastReview, nextReview, now time.Time) float64 {
+	return Retrievability(now.Sub(lastReview), nextReview.Sub(lastReview))
+}
+
+// Retrievability 按 PredictRetention 的遗忘曲线估算计划间隔为 scheduled 的卡片经过 elapsed 后的回忆概率
+func Retrievability(elapsed, scheduled time.Duration) float64 {
+	if scheduled <= 0 {
+		scheduled = 24 * time.Hour
+	}
+	if elapsed <= 0 {
+		return 1
+	}
+	return math.Pow(TargetRetention, float64(elapsed)/float64(scheduled))
+}
@@ -0,0 +1,390 @@
+// Package cardquery 解析卡片搜索语法，生成语法树
+//
+// 语法示例：tag:git -tag:done type:cloze due<3d ease<2.0 lapses>3 "exact phrase" created:7d
+//
+// 空格分隔的条件之间为"且"，OR 表示"或"，- 表示取反，括号用于分组，
+// 不带字段的词或双引号中的短语匹配卡片标题和内容。
+package cardquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Node 语法树节点
+type Node interface {
+	node()
+}
+
+// And 全部子条件都满足
+type And struct {
+	Nodes []Node
+}
+
+// Or 任一子条件满足
+type Or struct {
+	Nodes []Node
+}
+
+// Not 子条件不满足
+type Not struct {
+	Node Node
+}
+
+// Term 单个搜索条件
+type Term struct {
+	Field string // 字段名，为空表示匹配标题和内容的文本
+	Op    string // 比较符：":" "=" "<" "<=" ">" ">="
+	Value string // 去掉引号后的值
+}
+
+func (And) node()  {}
+func (Or) node()   {}
+func (Not) node()  {}
+func (Term) node() {}
+
+// 字段的取值类型
+type fieldKind int
+
+const (
+	kindText     fieldKind = iota // 文本，仅支持 :
+	kindNumber                    // 数值，支持全部比较符
+	kindDuration                  // 相对时间（如 3d、12h、2w），支持全部比较符
+	kindWindow                    // 最近一段时间内（如 7d），仅支持 :
+	kindFlag                      // 状态，仅支持 :
+)
+
+// 支持的搜索字段
+var fields = map[string]fieldKind{
+	"tag":        kindText,     // 标签名，支持 * 通配
+	"deck":       kindText,     // 卡组名，支持 * 通配
+	"type":       kindText,     // 卡片类型
+	"is":         kindFlag,     // new / due / filtered
+	"ease":       kindNumber,   // 简易因子
+	"difficulty": kindNumber,   // 难度系数
+	"reviews":    kindNumber,   // 复习次数
+	"lapses":     kindNumber,   // 答错次数
+	"due":        kindDuration, // 距到期的时间
+	"created":    kindDuration, // 创建至今的时间
+	"reviewed":   kindWindow,   // 最近一段时间内复习过
+	"failed":     kindWindow,   // 最近一段时间内答错过
+}
+
+// is: 支持的状态
+var flags = map[string]bool{"new": true, "due": true, "filtered": true, "suspended": true}
+
+// 单个查询允许的最多条件数
+const maxTerms = 50
+
+// Error 搜索语法错误
+type Error struct {
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return "搜索语法错误: " + e.Msg
+}
+
+// Errorf 构造搜索语法错误
+func Errorf(format string, args ...interface{}) error {
+	return &Error{Msg: fmt.Sprintf(format, args...)}
+}
+
+// Parse 解析搜索语句，空语句返回 nil
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, Errorf("多余的右括号")
+	}
+	return node, nil
+}
+
+// Duration 解析相对时间的值，支持 m（分钟）、h（小时）、d（天）、w（周），无单位时按天计算
+func (t Term) Duration() (time.Duration, error) {
+	return parseDuration(t.Value)
+}
+
+// Number 解析数值字段的值
+func (t Term) Number() (float64, error) {
+	return strconv.ParseFloat(t.Value, 64)
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokLParen
+	tokRParen
+	tokNot
+	tokOr
+	tokAnd
+)
+
+type token struct {
+	kind   tokenKind
+	text   string // 去掉引号后的文本
+	quoted bool   // 整个词是否为引号中的短语
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokNot})
+			i++
+		default:
+			var sb strings.Builder
+			quoted := r == '"'
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if runes[i] != '"' {
+					sb.WriteRune(runes[i])
+					i++
+					continue
+				}
+				// 引号中的内容原样保留，支持 \" 转义
+				i++
+				closed := false
+				for i < len(runes) {
+					if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == '"' {
+						sb.WriteRune('"')
+						i += 2
+						continue
+					}
+					if runes[i] == '"' {
+						closed = true
+						i++
+						break
+					}
+					sb.WriteRune(runes[i])
+					i++
+				}
+				if !closed {
+					return nil, Errorf("引号未闭合")
+				}
+				if quoted && (i >= len(runes) || unicode.IsSpace(runes[i]) || runes[i] == '(' || runes[i] == ')') {
+					break
+				}
+				quoted = false
+			}
+			text := sb.String()
+			tok := token{kind: tokWord, text: text, quoted: quoted}
+			if !quoted {
+				switch strings.ToUpper(text) {
+				case "OR":
+					tok.kind = tokOr
+				case "AND":
+					tok.kind = tokAnd
+				}
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	terms  int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+// or := and (OR and)*
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []Node{first}
+	for tok := p.peek(); tok != nil && tok.kind == tokOr; tok = p.peek() {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, next)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return Or{Nodes: nodes}, nil
+}
+
+// and := unary+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for tok := p.peek(); tok != nil && tok.kind != tokOr && tok.kind != tokRParen; tok = p.peek() {
+		if tok.kind == tokAnd {
+			p.pos++
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, Errorf("缺少搜索条件")
+	case 1:
+		return nodes[0], nil
+	}
+	return And{Nodes: nodes}, nil
+}
+
+// unary := - unary | ( or ) | term
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok == nil {
+		return nil, Errorf("缺少搜索条件")
+	}
+	switch tok.kind {
+	case tokNot:
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Node: node}, nil
+	case tokLParen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != tokRParen {
+			return nil, Errorf("括号未闭合")
+		}
+		p.pos++
+		return node, nil
+	case tokWord:
+		p.pos++
+		p.terms++
+		if p.terms > maxTerms {
+			return nil, Errorf("搜索条件不能超过%d个", maxTerms)
+		}
+		return parseTerm(*tok)
+	}
+	return nil, Errorf("缺少搜索条件")
+}
+
+// 比较符，按长度从长到短匹配
+var operators = []string{"<=", ">=", ":", "=", "<", ">"}
+
+func parseTerm(tok token) (Node, error) {
+	if tok.quoted {
+		return Term{Op: ":", Value: tok.text}, nil
+	}
+
+	// 查找第一个比较符，其前面的部分为字段名
+	idx := strings.IndexAny(tok.text, ":=<>")
+	if idx <= 0 {
+		return Term{Op: ":", Value: tok.text}, nil
+	}
+	name := strings.ToLower(tok.text[:idx])
+	kind, known := fields[name]
+	if !known {
+		if isWord(name) {
+			return nil, Errorf("未知的搜索字段 %s", name)
+		}
+		return Term{Op: ":", Value: tok.text}, nil
+	}
+
+	rest := tok.text[idx:]
+	var op string
+	for _, candidate := range operators {
+		if strings.HasPrefix(rest, candidate) {
+			op = candidate
+			break
+		}
+	}
+	value := rest[len(op):]
+	if value == "" {
+		return nil, Errorf("%s 缺少值", name)
+	}
+
+	term := Term{Field: name, Op: op, Value: value}
+	switch kind {
+	case kindText, kindWindow, kindFlag:
+		if op != ":" && op != "=" {
+			return nil, Errorf("%s 不支持比较符 %s", name, op)
+		}
+	}
+	switch kind {
+	case kindNumber:
+		if _, err := term.Number(); err != nil {
+			return nil, Errorf("%s 的值必须是数字", name)
+		}
+	case kindDuration, kindWindow:
+		if _, err := term.Duration(); err != nil {
+			return nil, Errorf("%s 的值必须是时间，如 3d、12h、2w", name)
+		}
+	case kindFlag:
+		term.Value = strings.ToLower(value)
+		if !flags[term.Value] {
+			return nil, Errorf("未知的状态 is:%s", value)
+		}
+	}
+	return term, nil
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	unit := 24 * time.Hour
+	number := value
+	switch value[len(value)-1] {
+	case 'm':
+		unit, number = time.Minute, value[:len(value)-1]
+	case 'h':
+		unit, number = time.Hour, value[:len(value)-1]
+	case 'd':
+		number = value[:len(value)-1]
+	case 'w':
+		unit, number = 7*24*time.Hour, value[:len(value)-1]
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
@@ -0,0 +1,160 @@
+package cardquery
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func text(value string) Term {
+	return Term{Op: ":", Value: value}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Node
+	}{
+		{"空语句", "   ", nil},
+		{"单个词", "git", text("git")},
+		{"字段", "tag:git", Term{Field: "tag", Op: ":", Value: "git"}},
+		{"字段名不区分大小写", "TAG:git", Term{Field: "tag", Op: ":", Value: "git"}},
+		{"比较符", "ease<=2.5", Term{Field: "ease", Op: "<=", Value: "2.5"}},
+		{"状态值转为小写", "is:DUE", Term{Field: "is", Op: ":", Value: "due"}},
+		{"空格为且", "tag:git type:cloze", And{Nodes: []Node{
+			Term{Field: "tag", Op: ":", Value: "git"},
+			Term{Field: "type", Op: ":", Value: "cloze"},
+		}}},
+		{"AND 可以省略", "a AND b", And{Nodes: []Node{text("a"), text("b")}}},
+		{"且优先于或", "a b OR c", Or{Nodes: []Node{
+			And{Nodes: []Node{text("a"), text("b")}},
+			text("c"),
+		}}},
+		{"或不区分大小写", "a or b", Or{Nodes: []Node{text("a"), text("b")}}},
+		{"取反只作用于紧随的条件", "-a b", And{Nodes: []Node{Not{Node: text("a")}, text("b")}}},
+		{"取反优先于或", "-a OR b", Or{Nodes: []Node{Not{Node: text("a")}, text("b")}}},
+		{"括号改变优先级", "a (b OR c)", And{Nodes: []Node{
+			text("a"),
+			Or{Nodes: []Node{text("b"), text("c")}},
+		}}},
+		{"取反括号", "-(a OR b)", Not{Node: Or{Nodes: []Node{text("a"), text("b")}}}},
+		{"嵌套括号", "((a))", text("a")},
+		{"连字符在词中不是取反", "e-mail", text("e-mail")},
+		{"单独的连字符是普通词", "a - b", And{Nodes: []Node{text("a"), text("-"), text("b")}}},
+		{"引号中的短语", `"exact phrase"`, text("exact phrase")},
+		{"引号中的 OR 是普通词", `"OR"`, text("OR")},
+		{"引号中的括号和冒号原样保留", `"tag:(x)"`, text("tag:(x)")},
+		{"引号转义", `"say \"hi\""`, text(`say "hi"`)},
+		{"字段值带引号", `tag:"my tag"`, Term{Field: "tag", Op: ":", Value: "my tag"}},
+		{"引号后紧跟括号", `("a b")`, text("a b")},
+		{"非英文的字段名按文本匹配", "中文:内容", text("中文:内容")},
+		{"开头是比较符按文本匹配", ":foo", text(":foo")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) 返回错误: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   string
+	}{
+		{"引号未闭合", `"abc`, "引号未闭合"},
+		{"字段值的引号未闭合", `tag:"abc`, "引号未闭合"},
+		{"括号未闭合", "(a OR b", "括号未闭合"},
+		{"多余的右括号", "a)", "多余的右括号"},
+		{"空括号", "()", "缺少搜索条件"},
+		{"取反空括号", "-()", "缺少搜索条件"},
+		{"取反后缺少条件", "a -(", "缺少搜索条件"},
+		{"或之前缺少条件", "OR a", "缺少搜索条件"},
+		{"或之后缺少条件", "a OR", "缺少搜索条件"},
+		{"未知字段", "foo:bar", "未知的搜索字段 foo"},
+		{"冒号前的英文单词视为字段名", "http://x", "未知的搜索字段 http"},
+		{"缺少值", "tag:", "tag 缺少值"},
+		{"文本字段不支持比较", "tag>a", "tag 不支持比较符 >"},
+		{"时间窗口不支持比较", "reviewed<7d", "reviewed 不支持比较符 <"},
+		{"数值字段的值不是数字", "ease<abc", "ease 的值必须是数字"},
+		{"时间字段的值无效", "due<3x", "due 的值必须是时间"},
+		{"负的时间", "created:-1d", "created 的值必须是时间"},
+		{"未知状态", "is:old", "未知的状态 is:old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %#v, want error", tt.input, node)
+			}
+			var syntaxErr *Error
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) 返回的错误类型为 %T", tt.input, err)
+			}
+			if !strings.Contains(syntaxErr.Msg, tt.msg) {
+				t.Errorf("Parse(%q) 错误 %q，应包含 %q", tt.input, syntaxErr.Msg, tt.msg)
+			}
+		})
+	}
+}
+
+func TestParseTermLimit(t *testing.T) {
+	terms := make([]string, maxTerms+1)
+	for i := range terms {
+		terms[i] = "w"
+	}
+
+	if _, err := Parse(strings.Join(terms[:maxTerms], " ")); err != nil {
+		t.Fatalf("%d 个条件应当允许: %v", maxTerms, err)
+	}
+	// 括号和运算符不计入条件数
+	grouped := "(" + strings.Join(terms[:maxTerms/2], " OR ") + ") -(" + strings.Join(terms[:maxTerms/2], " ") + ")"
+	if _, err := Parse(grouped); err != nil {
+		t.Fatalf("%d 个条件应当允许: %v", maxTerms, err)
+	}
+	if _, err := Parse(strings.Join(terms, " ")); err == nil {
+		t.Fatalf("%d 个条件应当返回错误", maxTerms+1)
+	}
+	if _, err := Parse(strings.Join(terms, " OR ")); err == nil {
+		t.Fatalf("用 OR 连接的 %d 个条件应当返回错误", maxTerms+1)
+	}
+}
+
+func TestTermDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"30m", 30 * time.Minute, true},
+		{"12h", 12 * time.Hour, true},
+		{"3d", 3 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"1.5d", 36 * time.Hour, true},
+		{"5", 5 * 24 * time.Hour, true},
+		{"0", 0, true},
+		{"d", 0, false},
+		{"3y", 0, false},
+		{"-1d", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := Term{Field: "due", Op: "<", Value: tt.value}.Duration()
+		if (err == nil) != tt.ok {
+			t.Errorf("Duration(%q) error = %v, want ok %v", tt.value, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("Duration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
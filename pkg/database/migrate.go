@@ -3,18 +3,111 @@ package database
 import (
 	"ReMindful/internal/model"
 	"fmt"
+	"log"
 
 	"gorm.io/gorm"
 )
 
 // AutoMigrate 自动迁移数据库表结构
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	// 卡片与标签的多对多关系使用自定义连接表模型
+	if err := db.SetupJoinTable(&model.LearningCard{}, "Tags", &model.CardTag{}); err != nil {
+		return err
+	}
+
+	// 标签名改为按用户唯一，移除旧的全局唯一索引
+	if db.Migrator().HasTable(&model.Tag{}) && db.Migrator().HasIndex(&model.Tag{}, "idx_tags_name") {
+		if err := db.Migrator().DropIndex(&model.Tag{}, "idx_tags_name"); err != nil {
+			return err
+		}
+	}
+
+	if err := db.AutoMigrate(
 		&model.User{},
 		&model.Tag{},
 		&model.LearningCard{},
 		&model.ReviewLog{},
-	)
+		&model.Deck{},
+		&model.DeckPreset{},
+		&model.CardRevision{},
+		&model.CardTag{},
+		&model.StudySession{},
+		&model.StudyGoal{},
+		&model.UserAchievement{},
+		&model.DailyStat{},
+		&model.AuthSession{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.CardBand{},
+	); err != nil {
+		return err
+	}
+
+	return migrateDefaultDecks(db)
+}
+
+// 每个用户只保留一个默认卡组，并把尚未归属卡组的卡片放入默认卡组。
+// default_owner 是只在默认卡组上有值的生成列，其唯一索引保证并发请求不会创建多个默认卡组
+func migrateDefaultDecks(db *gorm.DB) error {
+	// 合并历史上重复创建的默认卡组：保留最早的一个，其余改为普通卡组
+	type duplicate struct {
+		UserID uint
+		KeepID uint
+	}
+	var duplicates []duplicate
+	if err := db.Model(&model.Deck{}).
+		Select("user_id, MIN(id) AS keep_id").
+		Where("is_default = ?", true).
+		Group("user_id").
+		Having("COUNT(*) > 1").
+		Scan(&duplicates).Error; err != nil {
+		return err
+	}
+	for _, d := range duplicates {
+		if err := db.Unscoped().Model(&model.Deck{}).
+			Where("user_id = ? AND is_default = ? AND id <> ?", d.UserID, true, d.KeepID).
+			Update("is_default", false).Error; err != nil {
+			return err
+		}
+	}
+
+	var count int64
+	err := db.Raw("SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? AND column_name = ?", "decks", "default_owner").Scan(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := db.Exec("ALTER TABLE decks ADD COLUMN default_owner BIGINT UNSIGNED " +
+			"AS (IF(is_default AND deleted_at IS NULL, user_id, NULL)) STORED").Error; err != nil {
+			return err
+		}
+		if err := db.Exec("CREATE UNIQUE INDEX idx_decks_default_owner ON decks(default_owner)").Error; err != nil {
+			return err
+		}
+	}
+
+	// 回填没有卡组的卡片（包括回收站中的卡片）
+	var userIDs []uint
+	if err := db.Unscoped().Model(&model.LearningCard{}).
+		Where("deck_id = 0").
+		Distinct().Pluck("user_id", &userIDs).Error; err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		var deck model.Deck
+		err := db.Where(model.Deck{UserID: userID, IsDefault: true}).
+			Attrs(model.Deck{Name: model.DefaultDeckName}).
+			FirstOrCreate(&deck).Error
+		if err != nil {
+			return err
+		}
+		if err := db.Unscoped().Model(&model.LearningCard{}).
+			Where("user_id = ? AND deck_id = 0", userID).
+			Update("deck_id", deck.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // CreateIndexes 创建必要的索引
@@ -56,5 +149,18 @@ func CreateIndexes(db *gorm.DB) error {
 		return err
 	}
 
+	// 为卡片标题和内容创建全文索引，ngram 分词器支持中文
+	var count int64
+	err := db.Raw("SELECT COUNT(*) FROM information_schema.statistics WHERE table_schema = DATABASE() AND table_name = ? AND index_name = ?", "learning_cards", "idx_learning_cards_fulltext").Scan(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		if err := db.Exec("CREATE FULLTEXT INDEX idx_learning_cards_fulltext ON learning_cards(title, content) WITH PARSER ngram").Error; err != nil {
+			// 不支持 ngram 的数据库退回到内存索引搜索，不影响启动
+			log.Printf("创建全文索引失败，将使用内存索引搜索: %v", err)
+		}
+	}
+
 	return nil
 }
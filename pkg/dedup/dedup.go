@@ -0,0 +1,211 @@
+// Package dedup 检测重复和近似重复的文本
+//
+// 完全重复：规范化（转小写、去掉空白和标点）后的内容哈希相同。
+// 近似重复：对规范化文本按3字切片（shingle）计算 MinHash 签名，
+// 用 LSH 分桶找出候选对，再以签名估算的 Jaccard 相似度确认。
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 签名参数：64个哈希分为16段，每段4行，相似度约0.5以上的文本会成为候选
+const (
+	NumHashes    = 64
+	Bands        = 16
+	rowsPerBand  = NumHashes / Bands
+	shingleRunes = 3
+)
+
+// Normalize 规范化文本：转小写，去掉空白、标点和符号
+func Normalize(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return sb.String()
+}
+
+// Hash 规范化后的标题和内容的哈希，用于判断完全重复
+func Hash(title, content string) string {
+	sum := sha256.Sum256([]byte(Normalize(title) + "\x00" + Normalize(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Signature 计算文本的 MinHash 签名
+func Signature(text string) []uint64 {
+	runes := []rune(Normalize(text))
+	sig := make([]uint64, NumHashes)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	if len(runes) == 0 {
+		return sig
+	}
+
+	n := shingleRunes
+	if len(runes) < n {
+		n = len(runes)
+	}
+	for i := 0; i+n <= len(runes); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+n])))
+		x := h.Sum64()
+		for j := range sig {
+			if v := mix(x ^ seeds[j]); v < sig[j] {
+				sig[j] = v
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity 由两个签名估算 Jaccard 相似度
+func Similarity(a, b []uint64) float64 {
+	if len(a) != NumHashes || len(b) != NumHashes {
+		return 0
+	}
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / NumHashes
+}
+
+// Encode 将签名编码为字节，便于存储
+func Encode(sig []uint64) []byte {
+	buf := make([]byte, 8*len(sig))
+	for i, v := range sig {
+		binary.LittleEndian.PutUint64(buf[8*i:], v)
+	}
+	return buf
+}
+
+// Decode 解码签名，长度不符时返回 nil
+func Decode(buf []byte) []uint64 {
+	if len(buf) != 8*NumHashes {
+		return nil
+	}
+	sig := make([]uint64, NumHashes)
+	for i := range sig {
+		sig[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	return sig
+}
+
+// BandHashes 签名每一段的哈希，共 Bands 个；任一段哈希相同的两个签名即为候选对。
+// 签名长度不符时返回 nil
+func BandHashes(sig []uint64) []uint64 {
+	if len(sig) != NumHashes {
+		return nil
+	}
+	hashes := make([]uint64, Bands)
+	for band := range hashes {
+		h := fnv.New64a()
+		h.Write(Encode(sig[band*rowsPerBand : (band+1)*rowsPerBand]))
+		hashes[band] = h.Sum64()
+	}
+	return hashes
+}
+
+// Item 参与聚类的文本签名
+type Item struct {
+	ID        uint
+	Signature []uint64
+}
+
+// Member 聚类中的成员及其与聚类中第一个成员的相似度
+type Member struct {
+	ID         uint
+	Similarity float64
+}
+
+// Cluster 将相似度不低于 threshold 的签名聚为一类，只返回包含两个及以上成员的聚类
+func Cluster(items []Item, threshold float64) [][]Member {
+	parent := make([]int, len(items))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// LSH 分桶：任一段哈希相同的签名成为候选对
+	bandHashes := make([][]uint64, len(items))
+	for i, item := range items {
+		bandHashes[i] = BandHashes(item.Signature)
+	}
+	for band := 0; band < Bands; band++ {
+		buckets := make(map[uint64][]int)
+		for i := range items {
+			if bandHashes[i] == nil {
+				continue
+			}
+			buckets[bandHashes[i][band]] = append(buckets[bandHashes[i][band]], i)
+		}
+		for _, bucket := range buckets {
+			for _, j := range bucket[1:] {
+				a, b := find(bucket[0]), find(j)
+				if a != b && Similarity(items[bucket[0]].Signature, items[j].Signature) >= threshold {
+					parent[b] = a
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	for i := range items {
+		root := find(i)
+		groups[root] = append(groups[root], i)
+	}
+	var clusters [][]Member
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		first := items[group[0]]
+		members := make([]Member, len(group))
+		for k, i := range group {
+			members[k] = Member{ID: items[i].ID, Similarity: Similarity(first.Signature, items[i].Signature)}
+		}
+		clusters = append(clusters, members)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i][0].ID < clusters[j][0].ID
+	})
+	return clusters
+}
+
+// 每个哈希函数的种子
+var seeds = func() [NumHashes]uint64 {
+	var s [NumHashes]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range s {
+		x = mix(x + uint64(i))
+		s[i] = x
+	}
+	return s
+}()
+
+// splitmix64 混合函数
+func mix(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	return x ^ (x >> 31)
+}
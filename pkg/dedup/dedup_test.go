@@ -0,0 +1,139 @@
+package dedup
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"Hello, World!", "helloworld"},
+		{"  Go  语言\t入门\n", "go语言入门"},
+		{"什么是 MinHash？", "什么是minhash"},
+		{"a-b_c/d", "abcd"},
+		{"ＡＢＣ１２３", "ａｂｃ１２３"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.text); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		name           string
+		title, content string
+		other          [2]string
+		same           bool
+	}{
+		{"大小写和标点不同", "Git 基础", "Git 是分布式版本控制系统。", [2]string{"git基础", "GIT是分布式版本控制系统"}, true},
+		{"内容不同", "Git 基础", "分布式", [2]string{"Git 基础", "集中式"}, false},
+		{"标题和内容的分界不同", "ab", "c", [2]string{"a", "bc"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := Hash(tt.title, tt.content), Hash(tt.other[0], tt.other[1])
+			if (a == b) != tt.same {
+				t.Errorf("Hash 相同 = %v, want %v", a == b, tt.same)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	const base = "间隔重复是一种利用遗忘曲线安排复习时间的学习方法，可以显著提高长期记忆的效果"
+	tests := []struct {
+		name     string
+		a, b     string
+		min, max float64
+	}{
+		{"完全相同", base, base, 1, 1},
+		{"只有标点和大小写不同", "Spaced Repetition!", "spaced repetition", 1, 1},
+		{"末尾多了标点", base, base + "。", 1, 1},
+		{"改动几个字", base, "间隔重复是一种利用遗忘曲线安排复习时间的记忆方法，可以明显提高长期记忆的效果", 0.5, 0.99},
+		{"完全不同", base, "HTTP 状态码 404 表示请求的资源在服务器上不存在", 0, 0.2},
+		{"短文本", "ab", "ab", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(Signature(tt.a), Signature(tt.b))
+			if got < tt.min || got > tt.max {
+				t.Errorf("Similarity = %.3f, want [%.2f, %.2f]", got, tt.min, tt.max)
+			}
+		})
+	}
+
+	if got := Similarity(Signature(base), nil); got != 0 {
+		t.Errorf("长度不符的签名相似度 = %v, want 0", got)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	sig := Signature("间隔重复")
+	if got := Decode(Encode(sig)); !reflect.DeepEqual(got, sig) {
+		t.Errorf("Decode(Encode(sig)) 与原签名不同")
+	}
+	for _, buf := range [][]byte{nil, make([]byte, 8*NumHashes-1), make([]byte, 8*NumHashes+8)} {
+		if got := Decode(buf); got != nil {
+			t.Errorf("Decode(%d 字节) = %v, want nil", len(buf), got)
+		}
+	}
+}
+
+func TestBandHashes(t *testing.T) {
+	a := BandHashes(Signature("Git 是分布式版本控制系统"))
+	if len(a) != Bands {
+		t.Fatalf("len(BandHashes) = %d, want %d", len(a), Bands)
+	}
+	if b := BandHashes(Signature("git是分布式版本控制系统")); !reflect.DeepEqual(a, b) {
+		t.Error("规范化后相同的文本分段哈希应当相同")
+	}
+	if got := BandHashes(make([]uint64, NumHashes-1)); got != nil {
+		t.Errorf("长度不符的签名 BandHashes = %v, want nil", got)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	texts := map[uint]string{
+		1: "间隔重复是一种利用遗忘曲线安排复习时间的学习方法",
+		2: "HTTP 状态码 404 表示请求的资源在服务器上不存在",
+		3: "间隔重复是一种利用遗忘曲线安排复习时间的学习方法。",
+		4: "Go 语言的 goroutine 由运行时调度，开销远小于线程",
+		5: "http 状态码 404：表示请求的资源在服务器上不存在了",
+		6: "Go 语言的 goroutine 由运行时调度，开销远小于线程！",
+	}
+	var items []Item
+	for id := uint(1); id <= 6; id++ {
+		items = append(items, Item{ID: id, Signature: Signature(texts[id])})
+	}
+	// 签名无效的条目不参与聚类
+	items = append(items, Item{ID: 7, Signature: nil})
+
+	clusters := Cluster(items, 0.8)
+	var got [][]uint
+	for _, cluster := range clusters {
+		var ids []uint
+		for _, member := range cluster {
+			ids = append(ids, member.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]uint{{1, 3}, {2, 5}, {4, 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Cluster = %v, want %v", got, want)
+	}
+	for _, cluster := range clusters {
+		if cluster[0].Similarity != 1 {
+			t.Errorf("聚类第一个成员与自身的相似度 = %v, want 1", cluster[0].Similarity)
+		}
+	}
+
+	if got := Cluster(items, 1.01); len(got) != 0 {
+		t.Errorf("阈值高于 1 时 Cluster = %v, want 空", got)
+	}
+}
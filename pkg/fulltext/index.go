@@ -0,0 +1,246 @@
+// Package fulltext 纯Go实现的内存倒排索引，用于不支持 MySQL FULLTEXT 的数据库
+//
+// 分词方式与 MySQL ngram 分词器一致：中日韩文字按相邻两字切分，
+// 其他文字按非字母数字字符切分为小写单词。
+package fulltext
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 标题中的词权重高于内容
+const titleWeight = 2.0
+
+// Document 待索引的文档
+type Document struct {
+	ID      uint
+	Title   string
+	Content string
+}
+
+// Hit 搜索命中结果
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+// Index 倒排索引，非并发安全，由调用方加锁
+type Index struct {
+	postings map[string]map[uint]float64 // 词 -> 文档ID -> 加权词频
+	lengths  map[uint]float64            // 文档ID -> 加权词数
+	total    float64                     // 全部文档的加权词数
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[uint]float64),
+		lengths:  make(map[uint]float64),
+	}
+}
+
+// Len 已索引的文档数
+func (idx *Index) Len() int {
+	return len(idx.lengths)
+}
+
+// Add 添加或替换文档
+func (idx *Index) Add(doc Document) {
+	idx.Remove(doc.ID)
+	var length float64
+	add := func(text string, weight float64) {
+		for _, token := range Tokenize(text) {
+			docs, ok := idx.postings[token]
+			if !ok {
+				docs = make(map[uint]float64)
+				idx.postings[token] = docs
+			}
+			docs[doc.ID] += weight
+			length += weight
+		}
+	}
+	add(doc.Title, titleWeight)
+	add(doc.Content, 1)
+	idx.lengths[doc.ID] = length
+	idx.total += length
+}
+
+// Remove 删除文档
+func (idx *Index) Remove(id uint) {
+	length, ok := idx.lengths[id]
+	if !ok {
+		return
+	}
+	for token, docs := range idx.postings {
+		if _, ok := docs[id]; ok {
+			delete(docs, id)
+			if len(docs) == 0 {
+				delete(idx.postings, token)
+			}
+		}
+	}
+	delete(idx.lengths, id)
+	idx.total -= length
+}
+
+// Search 按 BM25 相关度返回同时包含全部查询词的文档
+func (idx *Index) Search(query string) []Hit {
+	tokens := uniqueTokens(Tokenize(query))
+	if len(tokens) == 0 || len(idx.lengths) == 0 {
+		return nil
+	}
+
+	const k1, b = 1.2, 0.75
+	n := float64(len(idx.lengths))
+	avg := idx.total / n
+	scores := make(map[uint]float64)
+	matched := make(map[uint]int)
+	for _, token := range tokens {
+		docs := idx.postings[token]
+		if r := []rune(token); len(r) == 1 && IsCJK(r[0]) {
+			docs = idx.charPostings(token)
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + (n-float64(len(docs))+0.5)/(float64(len(docs))+0.5))
+		for id, tf := range docs {
+			norm := tf + k1*(1-b+b*idx.lengths[id]/avg)
+			scores[id] += idf * tf * (k1 + 1) / norm
+			matched[id]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if matched[id] == len(tokens) {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+// 单个中日韩文字只在独立出现时单独成词，其余时候包含在两字词中：
+// 以它开头的词计入词频，只出现在连续文字末尾的文档改用以它结尾的词
+func (idx *Index) charPostings(char string) map[uint]float64 {
+	docs := make(map[uint]float64)
+	ending := make(map[uint]float64)
+	for token, postings := range idx.postings {
+		target := docs
+		switch {
+		case strings.HasPrefix(token, char):
+		case strings.HasSuffix(token, char):
+			target = ending
+		default:
+			continue
+		}
+		for id, tf := range postings {
+			target[id] += tf
+		}
+	}
+	for id, tf := range ending {
+		if _, ok := docs[id]; !ok {
+			docs[id] = tf
+		}
+	}
+	return docs
+}
+
+// Tokenize 切分文本：中日韩文字切为相邻两字，单个字单独成词，其他文字切为小写单词
+func Tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			tokens = append(tokens, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				tokens = append(tokens, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for _, r := range text {
+		switch {
+		case IsCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
+
+// Terms 将查询切分为用于高亮的词：连续的中日韩文字和单词各自作为一个整体
+func Terms(query string) []string {
+	var terms []string
+	var current []rune
+	cjk := false
+	flush := func() {
+		if len(current) > 0 {
+			terms = append(terms, string(current))
+			current = current[:0]
+		}
+	}
+	for _, r := range query {
+		switch {
+		case IsCJK(r):
+			if !cjk {
+				flush()
+			}
+			cjk = true
+			current = append(current, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if cjk {
+				flush()
+			}
+			cjk = false
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return uniqueTokens(terms)
+}
+
+// IsCJK 是否为中日韩文字
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]bool, len(tokens))
+	unique := tokens[:0]
+	for _, token := range tokens {
+		if !seen[token] {
+			seen[token] = true
+			unique = append(unique, token)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,119 @@
+package fulltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"go1.22 发布", []string{"go1", "22", "发布"}},
+		{"间隔重复", []string{"间隔", "隔重", "重复"}},
+		{"学", []string{"学"}},
+		{"用Go写", []string{"用", "go", "写"}},
+		{"カタカナ", []string{"カタ", "タカ", "カナ"}},
+		{"a-b_c", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", nil},
+		{"Go 并发", []string{"Go", "并发"}},
+		{"学习Go语言", []string{"学习", "Go", "语言"}},
+		{"go go GO", []string{"go", "GO"}},
+		{"a+b", []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func hitIDs(hits []Hit) []uint {
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{ID: 1, Title: "Go 并发", Content: "goroutine 和 channel 是 Go 并发的基础"})
+	idx.Add(Document{ID: 2, Title: "Rust 所有权", Content: "所有权规则保证内存安全"})
+	idx.Add(Document{ID: 3, Title: "笔记", Content: "Go 的垃圾回收"})
+	idx.Add(Document{ID: 4, Title: "你好", Content: "问候语"})
+	idx.Add(Document{ID: 5, Title: "好", Content: "单独一个字"})
+
+	tests := []struct {
+		name  string
+		query string
+		want  []uint
+	}{
+		{"标题命中的排在前面", "go", []uint{1, 3}},
+		{"不区分大小写", "GOROUTINE", []uint{1}},
+		{"要求包含全部查询词", "go 垃圾", []uint{3}},
+		{"中文词按两字切分", "内存安全", []uint{2}},
+		{"没有命中", "python", []uint{}},
+		{"任一查询词没有命中", "go python", []uint{}},
+		{"空查询", "  ", []uint{}},
+		{"单个汉字匹配两字词的开头", "所", []uint{2}},
+		{"单个汉字匹配连续文字的末尾", "语", []uint{4}},
+		{"单个汉字匹配独立的字和两字词", "好", []uint{4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitIDs(idx.Search(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{ID: 1, Title: "旧标题", Content: "alpha"})
+	idx.Add(Document{ID: 2, Title: "其他", Content: "beta"})
+	if idx.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", idx.Len())
+	}
+
+	// 再次添加同一文档会替换原来的内容
+	idx.Add(Document{ID: 1, Title: "新标题", Content: "gamma"})
+	if idx.Len() != 2 {
+		t.Errorf("替换后 Len() = %d, want 2", idx.Len())
+	}
+	if hits := idx.Search("alpha"); len(hits) != 0 {
+		t.Errorf("替换后仍能搜到旧内容: %v", hits)
+	}
+	if got := hitIDs(idx.Search("gamma")); !reflect.DeepEqual(got, []uint{1}) {
+		t.Errorf("Search(gamma) = %v, want [1]", got)
+	}
+
+	idx.Remove(1)
+	idx.Remove(1)
+	if idx.Len() != 1 {
+		t.Errorf("删除后 Len() = %d, want 1", idx.Len())
+	}
+	if hits := idx.Search("gamma"); len(hits) != 0 {
+		t.Errorf("删除后仍能搜到: %v", hits)
+	}
+	if got := hitIDs(idx.Search("beta")); !reflect.DeepEqual(got, []uint{2}) {
+		t.Errorf("Search(beta) = %v, want [2]", got)
+	}
+}
@@ -0,0 +1,30 @@
+package fulltext
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxRunes int
+		want     string
+	}{
+		{"高亮命中词", "learn Go today", []string{"go"}, 0, "learn <mark>Go</mark> today"},
+		{"多个命中词", "间隔重复和主动回忆", []string{"重复", "回忆"}, 0, "间隔<mark>重复</mark>和主动<mark>回忆</mark>"},
+		{"相邻的命中合并为一段", "abcd", []string{"ab", "cd"}, 0, "<mark>abcd</mark>"},
+		{"转义HTML", "<b>go</b> & more", []string{"go"}, 0, "&lt;b&gt;<mark>go</mark>&lt;/b&gt; &amp; more"},
+		{"没有命中返回开头", "abcdefghij", []string{"xyz"}, 4, "abcd…"},
+		{"忽略空查询词", "abc", []string{""}, 0, "abc"},
+		{"以命中词为中心截取", "0123456789abcdefghijklmnop", []string{"f"}, 9, "…cde<mark>f</mark>ghijk…"},
+		{"命中在末尾时截取最后一段", "0123456789", []string{"9"}, 4, "…678<mark>9</mark>"},
+		{"片段在命中词中间截断", "abcdefgh", []string{"bcdef"}, 3, "a<mark>bc</mark>…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.text, tt.terms, tt.maxRunes); got != tt.want {
+				t.Errorf("Snippet(%q, %q, %d) = %q, want %q", tt.text, tt.terms, tt.maxRunes, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,7 +7,8 @@ import (
 )
 
 type Claims struct {
-    UserID uint
+    UserID    uint
+    SessionID uint `json:",omitempty"` // 登录会话ID，旧版token没有
     jwt.RegisteredClaims
 }
 
@@ -24,6 +25,22 @@ func GenerateToken(userID uint, secret string, expireHours int) (string, error)
     return token.SignedString([]byte(secret))
 }
 
+// 生成访问令牌，jti 用于注销时加入黑名单
+func GenerateAccessToken(userID, sessionID uint, jti string, secret string, expireAt time.Time) (string, error) {
+    claims := Claims{
+        UserID:    userID,
+        SessionID: sessionID,
+        RegisteredClaims: jwt.RegisteredClaims{
+            ID:        jti,
+            ExpiresAt: jwt.NewNumericDate(expireAt),
+            IssuedAt:  jwt.NewNumericDate(time.Now()),
+        },
+    }
+
+    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+    return token.SignedString([]byte(secret))
+}
+
 func ParseToken(tokenString string, secret string) (*Claims, error) {
     token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
         return []byte(secret), nil
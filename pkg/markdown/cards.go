@@ -0,0 +1,343 @@
+// Package markdown 从Markdown笔记中提取学习卡片
+package markdown
+
+import (
+	"ReMindful/pkg/dedup"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 卡片类型，与 model.CardType 的取值一致
+const (
+	KindBasic    = "basic"    // Question :: Answer 单行卡片
+	KindQuestion = "question" // 以单独一行 ? 分隔的问答块
+	KindCloze    = "cloze"    // 含 ==高亮== 的填空卡片
+)
+
+// Card 从笔记中提取出的卡片
+type Card struct {
+	Kind   string   // 卡片类型
+	Front  string   // 正面（问题，填空卡片为挖空后的文本）
+	Back   string   // 背面（答案，填空卡片为带 {{cN::}} 标记的文本）
+	Tags   []string // 卡片所在行中的 #标签
+	Anchor string   // 稳定的来源锚点，重复导入时用于定位已有卡片
+	Line   int      // 卡片在文件中的起始行号（从1开始）
+
+	blockID string // Obsidian 块ID（^id）
+}
+
+// Note 一个Markdown文件的解析结果
+type Note struct {
+	Path  string   // 文件在笔记库中的路径
+	Tags  []string // 文件级标签（frontmatter 和非卡片段落中的 #标签）
+	Cards []Card
+}
+
+var (
+	tagPattern       = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_\-/]+)`)
+	highlightPattern = regexp.MustCompile(`==([^=\n]+?)==`)
+	blockIDPattern   = regexp.MustCompile(`\s\^([A-Za-z0-9-]+)\s*$`)
+	// Dataview 行内字段（key:: value），可以在列表项中
+	fieldPattern = regexp.MustCompile(`^\s*(?:[-*+]\s+)?[\p{L}\p{N}_][\p{L}\p{N}_-]*::`)
+)
+
+// 单行问答的分隔符，两侧必须有空格，避免匹配 std::vector 之类的文本
+const basicSeparator = " :: "
+
+// 最长的锚点路径，超出时使用路径的哈希
+const maxAnchorPathLength = 180
+
+// Parse 解析一个Markdown文件
+func Parse(path string, src []byte) *Note {
+	note := &Note{Path: path}
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "---" {
+				note.Tags = append(note.Tags, parseFrontmatterTags(lines[1:i])...)
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	anchors := make(map[string]int)
+	for _, p := range splitParagraphs(lines, start) {
+		cards := parseParagraph(p)
+		if len(cards) == 0 {
+			note.Tags = append(note.Tags, extractTags(strings.Join(p.lines, "\n"))...)
+			continue
+		}
+		for _, card := range cards {
+			card.Anchor = makeAnchor(path, card, anchors)
+			note.Cards = append(note.Cards, card)
+		}
+	}
+	note.Tags = uniqueStrings(note.Tags)
+	return note
+}
+
+// 段落：以空行分隔的连续行，代码块整体跳过
+type paragraph struct {
+	line  int
+	lines []string
+}
+
+func splitParagraphs(lines []string, start int) []paragraph {
+	var paragraphs []paragraph
+	var current *paragraph
+	inFence := false
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			current = nil
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if trimmed == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			paragraphs = append(paragraphs, paragraph{line: i + 1})
+			current = &paragraphs[len(paragraphs)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	return paragraphs
+}
+
+// 解析段落中的卡片
+func parseParagraph(p paragraph) []Card {
+	// 多行问答：问题 / ? / 答案
+	for i, line := range p.lines {
+		if strings.TrimSpace(line) != "?" {
+			continue
+		}
+		front := strings.Join(p.lines[:i], "\n")
+		back := strings.Join(p.lines[i+1:], "\n")
+		tags := extractTags(front + "\n" + back)
+		front, back = cleanText(front), cleanText(back)
+		if front == "" || back == "" {
+			return nil
+		}
+		return []Card{{Kind: KindQuestion, Front: front, Back: back, Tags: tags, Line: p.line, blockID: blockID(back)}}
+	}
+
+	var cards []Card
+	var rest []string
+	for i, line := range p.lines {
+		// 单行问答：Question :: Answer
+		if idx := separatorIndex(line); idx >= 0 {
+			tags := extractTags(line)
+			front, back := cleanText(line[:idx]), cleanText(line[idx+len(basicSeparator):])
+			if front != "" && back != "" {
+				cards = append(cards, Card{Kind: KindBasic, Front: front, Back: back, Tags: tags, Line: p.line + i, blockID: blockID(line)})
+				continue
+			}
+		}
+		rest = append(rest, line)
+	}
+
+	// 高亮填空：段落中其余文本含 ==高亮==
+	text := strings.Join(rest, "\n")
+	if highlightPattern.MatchString(text) {
+		tags := extractTags(text)
+		id := blockID(text)
+		text = cleanText(text)
+		n := 0
+		back := highlightPattern.ReplaceAllStringFunc(text, func(m string) string {
+			n++
+			return fmt.Sprintf("{{c%d::%s}}", n, highlightPattern.FindStringSubmatch(m)[1])
+		})
+		front := highlightPattern.ReplaceAllString(text, "[...]")
+		cards = append(cards, Card{Kind: KindCloze, Front: front, Back: back, Tags: tags, Line: p.line, blockID: id})
+	}
+	return cards
+}
+
+// 单行问答分隔符的位置，不是问答行时返回 -1。
+// 跳过 Dataview 行内字段、{{c1::}} 形式的填空标记和行内代码中的分隔符
+func separatorIndex(line string) int {
+	if fieldPattern.MatchString(line) || strings.Contains(line, "{{") {
+		return -1
+	}
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '`' {
+			inCode = !inCode
+			continue
+		}
+		if !inCode && strings.HasPrefix(line[i:], basicSeparator) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 解析 frontmatter 中的 tags 字段，支持 tags: [a, b]、tags: a b 和列表形式
+func parseFrontmatterTags(lines []string) []string {
+	var tags []string
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "tags:") {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, "tags:"))
+		if value != "" {
+			value = strings.Trim(value, "[]")
+			for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+				tags = append(tags, normalizeTag(tag))
+			}
+			continue
+		}
+		for i+1 < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i+1]), "-") {
+			i++
+			tags = append(tags, normalizeTag(strings.TrimPrefix(strings.TrimSpace(lines[i]), "-")))
+		}
+	}
+	return tags
+}
+
+func normalizeTag(tag string) string {
+	return strings.TrimPrefix(strings.Trim(strings.TrimSpace(tag), `"'`), "#")
+}
+
+// 提取文本中的 #标签（纯数字的不算标签）
+func extractTags(text string) []string {
+	var tags []string
+	for _, m := range tagPattern.FindAllStringSubmatch(text, -1) {
+		tag := strings.Trim(m[2], "/")
+		if tag == "" || strings.IndexFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return uniqueStrings(tags)
+}
+
+// 提取文本末尾的块ID
+func blockID(text string) string {
+	if m := blockIDPattern.FindStringSubmatch(text); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// 去掉文本中的 #标签 和块ID
+func cleanText(text string) string {
+	text = blockIDPattern.ReplaceAllString(text, "")
+	text = tagPattern.ReplaceAllStringFunc(text, func(m string) string {
+		sub := tagPattern.FindStringSubmatch(m)
+		if strings.IndexFunc(sub[2], func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			return m
+		}
+		return sub[1]
+	})
+	return strings.TrimSpace(text)
+}
+
+// AnchorPrefix 文件中所有卡片锚点的公共前缀
+func AnchorPrefix(path string) string {
+	if len(path) > maxAnchorPathLength {
+		sum := sha1.Sum([]byte(path))
+		path = hex.EncodeToString(sum[:])
+	}
+	return path + "#"
+}
+
+// 生成锚点：优先使用 Obsidian 块ID（^id），否则使用问题文本的哈希。
+// 问题修改后哈希锚点会变化，导入时用 MatchEdited 在同一文件的已导入卡片中找回原来的卡片
+func makeAnchor(path string, card Card, seen map[string]int) string {
+	var anchor string
+	if card.blockID != "" {
+		anchor = AnchorPrefix(path) + "^" + card.blockID
+	} else {
+		sum := sha1.Sum([]byte(strings.Join(strings.Fields(card.Front), " ")))
+		anchor = AnchorPrefix(path) + hex.EncodeToString(sum[:8])
+	}
+
+	// 同一文件中重复的问题按出现次序区分
+	seen[anchor]++
+	if n := seen[anchor]; n > 1 {
+		anchor = fmt.Sprintf("%s-%d", anchor, n)
+	}
+	return anchor
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	result := values[:0]
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+// Imported 同一文件中已导入、但锚点没有被本次解析出的卡片使用的卡片
+type Imported struct {
+	Front string
+	Back  string
+}
+
+// MatchEdited 为锚点没有找到已导入卡片的卡片（通常是问题被修改过）在 imported 中寻找内容最相近的卡片，
+// 问题和答案的平均相似度不低于 threshold 才算匹配，每张已导入的卡片最多匹配一次。
+// 相似度相同时优先匹配在各自列表中位置接近的卡片，返回 cards 下标到 imported 下标的映射
+func MatchEdited(cards []Card, imported []Imported, threshold float64) map[int]int {
+	type pair struct {
+		card, imported int
+		score          float64
+	}
+	var pairs []pair
+	importedSigs := make([][2][]uint64, len(imported))
+	for j, item := range imported {
+		importedSigs[j] = [2][]uint64{dedup.Signature(item.Front), dedup.Signature(item.Back)}
+	}
+	for i, card := range cards {
+		front, back := dedup.Signature(card.Front), dedup.Signature(card.Back)
+		for j, sigs := range importedSigs {
+			score := (dedup.Similarity(front, sigs[0]) + dedup.Similarity(back, sigs[1])) / 2
+			if score >= threshold {
+				pairs = append(pairs, pair{card: i, imported: j, score: score})
+			}
+		}
+	}
+	distance := func(p pair) int {
+		if p.card > p.imported {
+			return p.card - p.imported
+		}
+		return p.imported - p.card
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].score != pairs[b].score {
+			return pairs[a].score > pairs[b].score
+		}
+		return distance(pairs[a]) < distance(pairs[b])
+	})
+
+	matches := make(map[int]int)
+	used := make(map[int]bool)
+	for _, p := range pairs {
+		if _, ok := matches[p.card]; ok || used[p.imported] {
+			continue
+		}
+		matches[p.card] = p.imported
+		used[p.imported] = true
+	}
+	return matches
+}
@@ -0,0 +1,236 @@
+package markdown
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 卡片的类型、正面和背面
+type cardText struct {
+	Kind, Front, Back string
+}
+
+func cardTexts(cards []Card) []cardText {
+	texts := make([]cardText, 0, len(cards))
+	for _, card := range cards {
+		texts = append(texts, cardText{card.Kind, card.Front, card.Back})
+	}
+	return texts
+}
+
+func TestParseCards(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []cardText
+	}{
+		{
+			name: "单行问答",
+			src:  "Go 的作者 :: Rob Pike",
+			want: []cardText{{KindBasic, "Go 的作者", "Rob Pike"}},
+		},
+		{
+			name: "同一段落中的多张单行问答",
+			src:  "a :: 1\nb :: 2",
+			want: []cardText{{KindBasic, "a", "1"}, {KindBasic, "b", "2"}},
+		},
+		{
+			name: "分隔符两侧没有空格",
+			src:  "std::vector 是动态数组\nfoo ::bar",
+			want: []cardText{},
+		},
+		{
+			name: "Dataview 行内字段",
+			src:  "author:: Rob Pike\n- status:: done :: x",
+			want: []cardText{},
+		},
+		{
+			name: "行内代码中的分隔符",
+			src:  "`a :: b` 是 Haskell 的类型签名",
+			want: []cardText{},
+		},
+		{
+			name: "行内代码之后的分隔符",
+			src:  "`x :: Int` 的含义 :: x 的类型是 Int",
+			want: []cardText{{KindBasic, "`x :: Int` 的含义", "x 的类型是 Int"}},
+		},
+		{
+			name: "已有的填空标记",
+			src:  "{{c1::Go}} :: 语言",
+			want: []cardText{},
+		},
+		{
+			name: "一侧为空",
+			src:  "问题 :: #tag",
+			want: []cardText{},
+		},
+		{
+			name: "多行问答",
+			src:  "什么是 goroutine？\n?\n轻量级线程\n由运行时调度",
+			want: []cardText{{KindQuestion, "什么是 goroutine？", "轻量级线程\n由运行时调度"}},
+		},
+		{
+			name: "高亮填空",
+			src:  "Go 由 ==Google== 在 ==2009== 年发布",
+			want: []cardText{{KindCloze, "Go 由 [...] 在 [...] 年发布", "Go 由 {{c1::Google}} 在 {{c2::2009}} 年发布"}},
+		},
+		{
+			name: "单行问答之外的高亮",
+			src:  "a :: b\n==c== 是填空",
+			want: []cardText{{KindBasic, "a", "b"}, {KindCloze, "[...] 是填空", "{{c1::c}} 是填空"}},
+		},
+		{
+			name: "代码块中的内容",
+			src:  "```\na :: b\n```\nc :: d",
+			want: []cardText{{KindBasic, "c", "d"}},
+		},
+		{
+			name: "frontmatter 不是卡片",
+			src:  "---\ntitle: a :: b\n---\nc :: d",
+			want: []cardText{{KindBasic, "c", "d"}},
+		},
+		{
+			name: "去掉标签和块ID",
+			src:  "问题 #go :: 答案 ^abc",
+			want: []cardText{{KindBasic, "问题", "答案"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := Parse("notes/go.md", []byte(tt.src))
+			if got := cardTexts(note.Cards); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	src := "---\ntags: [go, \"#lang\"]\n---\n#backend 笔记\n\n问题 #concurrency #2024 :: 答案\n"
+	note := Parse("go.md", []byte(src))
+
+	if want := []string{"go", "lang", "backend"}; !reflect.DeepEqual(note.Tags, want) {
+		t.Errorf("note.Tags = %v, want %v", note.Tags, want)
+	}
+	if len(note.Cards) != 1 {
+		t.Fatalf("len(note.Cards) = %d, want 1", len(note.Cards))
+	}
+	card := note.Cards[0]
+	if want := []string{"concurrency"}; !reflect.DeepEqual(card.Tags, want) {
+		t.Errorf("card.Tags = %v, want %v", card.Tags, want)
+	}
+	if card.Line != 6 {
+		t.Errorf("card.Line = %d, want 6", card.Line)
+	}
+}
+
+func TestParseAnchors(t *testing.T) {
+	anchors := func(src string) []string {
+		var result []string
+		for _, card := range Parse("deck/go.md", []byte(src)).Cards {
+			result = append(result, card.Anchor)
+		}
+		return result
+	}
+
+	first := anchors("a :: 1\n\nb :: 2")
+	for _, anchor := range first {
+		if !strings.HasPrefix(anchor, AnchorPrefix("deck/go.md")) {
+			t.Errorf("锚点 %q 应以文件的锚点前缀开头", anchor)
+		}
+	}
+
+	// 锚点只取决于问题：答案修改、卡片移动位置后不变
+	moved := anchors("b :: 2 修改\n\n前面插入的段落\n\na :: 1")
+	if moved[0] != first[1] || moved[1] != first[0] {
+		t.Errorf("锚点随位置或答案变化: %v -> %v", first, moved)
+	}
+
+	// 问题中多余的空白不影响锚点
+	if spaced := anchors("a   :: 1"); spaced[0] != first[0] {
+		t.Errorf("问题空白不同的锚点 %q != %q", spaced[0], first[0])
+	}
+
+	// 块ID优先于问题的哈希
+	withID := anchors("a :: 1 ^card-1")
+	if want := AnchorPrefix("deck/go.md") + "^card-1"; withID[0] != want {
+		t.Errorf("块ID锚点 = %q, want %q", withID[0], want)
+	}
+
+	// 重复的问题按出现次序区分
+	dup := anchors("a :: 1\na :: 2")
+	if dup[0] == dup[1] || dup[1] != dup[0]+"-2" {
+		t.Errorf("重复问题的锚点 = %v", dup)
+	}
+}
+
+func TestAnchorPrefix(t *testing.T) {
+	if got := AnchorPrefix("a/b.md"); got != "a/b.md#" {
+		t.Errorf("AnchorPrefix = %q", got)
+	}
+	long := strings.Repeat("x", maxAnchorPathLength+1)
+	got := AnchorPrefix(long)
+	if len(got) != 41 || !strings.HasSuffix(got, "#") {
+		t.Errorf("过长路径的锚点前缀 = %q，应为 SHA-1 哈希", got)
+	}
+	if got != AnchorPrefix(long) {
+		t.Error("同一路径的锚点前缀应当稳定")
+	}
+}
+
+func TestMatchEdited(t *testing.T) {
+	const question = "Go 语言中 channel 的零值是什么，向它发送数据会发生什么"
+	const answer = "channel 的零值是 nil，向 nil channel 发送数据会永久阻塞"
+
+	tests := []struct {
+		name     string
+		cards    []Card
+		imported []Imported
+		want     map[int]int
+	}{
+		{
+			name:     "问题小幅修改",
+			cards:    []Card{{Front: question + "？", Back: answer}},
+			imported: []Imported{{Front: question, Back: answer}},
+			want:     map[int]int{0: 0},
+		},
+		{
+			name:     "内容完全不同",
+			cards:    []Card{{Front: "HTTP 状态码 404 的含义", Back: "请求的资源不存在"}},
+			imported: []Imported{{Front: question, Back: answer}},
+			want:     map[int]int{},
+		},
+		{
+			name:     "每张已导入的卡片最多匹配一次",
+			cards:    []Card{{Front: question + "？", Back: answer}, {Front: question + "！", Back: answer}},
+			imported: []Imported{{Front: question, Back: answer}},
+			want:     map[int]int{0: 0},
+		},
+		{
+			name: "相似度相同时匹配位置接近的卡片",
+			cards: []Card{
+				{Front: "无关的问题 A", Back: "无关的答案 A"},
+				{Front: question, Back: answer},
+			},
+			imported: []Imported{
+				{Front: question, Back: answer},
+				{Front: question, Back: answer},
+			},
+			want: map[int]int{1: 1},
+		},
+		{
+			name:     "没有已导入的卡片",
+			cards:    []Card{{Front: question, Back: answer}},
+			imported: nil,
+			want:     map[int]int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchEdited(tt.cards, tt.imported, 0.5); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MatchEdited = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
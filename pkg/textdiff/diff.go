@@ -0,0 +1,124 @@
+// Package textdiff 基于最长公共子序列的文本差异比较
+package textdiff
+
+import "strings"
+
+// 差异操作类型
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// 参与 LCS 计算的最大单元数（行数或字数的乘积），超出时整体视为替换
+const maxCells = 4_000_000
+
+// Op 一段差异
+type Op struct {
+	Op   string `json:"op" example:"insert" enums:"equal,insert,delete"`
+	Text string `json:"text"`
+}
+
+// Lines 按行比较，适合多行内容
+func Lines(a, b string) []Op {
+	return diff(splitLines(a), splitLines(b))
+}
+
+// Runes 按字比较，适合标题等单行文本
+func Runes(a, b string) []Op {
+	return diff(splitRunes(a), splitRunes(b))
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	// 以换行结尾时 SplitAfter 会多出一个空行
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func splitRunes(s string) []string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, string(r))
+	}
+	return parts
+}
+
+func diff(a, b []string) []Op {
+	// 去掉相同的前缀和后缀，减少计算量
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []Op
+	ops = appendOp(ops, OpEqual, a[:prefix])
+	ops = append(ops, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	ops = appendOp(ops, OpEqual, a[len(a)-suffix:])
+	return ops
+}
+
+func middle(a, b []string) []Op {
+	var ops []Op
+	if len(a)*len(b) > maxCells {
+		ops = appendOp(ops, OpDelete, a)
+		return appendOp(ops, OpInsert, b)
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = appendOp(ops, OpEqual, a[i:i+1])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = appendOp(ops, OpDelete, a[i:i+1])
+			i++
+		default:
+			ops = appendOp(ops, OpInsert, b[j:j+1])
+			j++
+		}
+	}
+	ops = appendOp(ops, OpDelete, a[i:])
+	return appendOp(ops, OpInsert, b[j:])
+}
+
+// 追加差异，与上一段类型相同时合并
+func appendOp(ops []Op, op string, parts []string) []Op {
+	if len(parts) == 0 {
+		return ops
+	}
+	text := strings.Join(parts, "")
+	if n := len(ops); n > 0 && ops[n-1].Op == op {
+		ops[n-1].Text += text
+		return ops
+	}
+	return append(ops, Op{Op: op, Text: text})
+}
@@ -0,0 +1,102 @@
+package textdiff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{"相同", "abc", "abc", []Op{{OpEqual, "abc"}}},
+		{"都为空", "", "", nil},
+		{"新增", "", "abc", []Op{{OpInsert, "abc"}}},
+		{"删除", "abc", "", []Op{{OpDelete, "abc"}}},
+		{"中间插入", "ac", "abc", []Op{{OpEqual, "a"}, {OpInsert, "b"}, {OpEqual, "c"}}},
+		{"中间删除", "abc", "ac", []Op{{OpEqual, "a"}, {OpDelete, "b"}, {OpEqual, "c"}}},
+		{"替换先删除后插入", "axc", "ayc", []Op{{OpEqual, "a"}, {OpDelete, "x"}, {OpInsert, "y"}, {OpEqual, "c"}}},
+		{"按字而不是按字节", "学习卡片", "复习卡片", []Op{{OpDelete, "学"}, {OpInsert, "复"}, {OpEqual, "习卡片"}}},
+		{"连续的同类差异合并", "abcd", "xyzd", []Op{{OpDelete, "abc"}, {OpInsert, "xyz"}, {OpEqual, "d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Runes(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Runes(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Op
+	}{
+		{"相同", "a\nb\n", "a\nb\n", []Op{{OpEqual, "a\nb\n"}}},
+		{"修改一行", "a\nb\nc\n", "a\nB\nc\n", []Op{{OpEqual, "a\n"}, {OpDelete, "b\n"}, {OpInsert, "B\n"}, {OpEqual, "c\n"}}},
+		{"末尾追加", "a\n", "a\nb\n", []Op{{OpEqual, "a\n"}, {OpInsert, "b\n"}}},
+		{"末行没有换行符", "a\nb", "a\nb\n", []Op{{OpEqual, "a\n"}, {OpDelete, "b"}, {OpInsert, "b\n"}}},
+		{"从空内容开始", "", "a\nb", []Op{{OpInsert, "a\nb"}}},
+		{"移动一行", "a\nb\nc\n", "b\nc\na\n", []Op{{OpDelete, "a\n"}, {OpEqual, "b\nc\n"}, {OpInsert, "a\n"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// 相等和删除的部分拼出原文，相等和插入的部分拼出新文本，相邻的差异类型不同
+func TestDiffReconstructs(t *testing.T) {
+	pairs := [][2]string{
+		{"the quick brown fox", "the slow brown dog"},
+		{"kitten", "sitting"},
+		{"间隔重复是一种学习方法", "间隔重复是高效的记忆方法"},
+		{"line1\nline2\nline3\n", "line0\nline2\nline3\nline4\n"},
+	}
+	for _, pair := range pairs {
+		for name, fn := range map[string]func(a, b string) []Op{"Runes": Runes, "Lines": Lines} {
+			ops := fn(pair[0], pair[1])
+			var a, b strings.Builder
+			for i, op := range ops {
+				if i > 0 && ops[i-1].Op == op.Op {
+					t.Errorf("%s(%q, %q) 相邻的差异类型相同: %v", name, pair[0], pair[1], ops)
+				}
+				switch op.Op {
+				case OpEqual:
+					a.WriteString(op.Text)
+					b.WriteString(op.Text)
+				case OpDelete:
+					a.WriteString(op.Text)
+				case OpInsert:
+					b.WriteString(op.Text)
+				}
+			}
+			if a.String() != pair[0] || b.String() != pair[1] {
+				t.Errorf("%s(%q, %q) 无法还原: %q, %q", name, pair[0], pair[1], a.String(), b.String())
+			}
+		}
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 2001; i++ {
+		fmt.Fprintf(&a, "a%d\n", i)
+		fmt.Fprintf(&b, "b%d\n", i)
+	}
+	// 相同的首尾不参与计算，中间部分超出上限时整体视为替换
+	ops := Lines("head\n"+a.String()+"tail\n", "head\n"+b.String()+"tail\n")
+	want := []Op{{OpEqual, "head\n"}, {OpDelete, a.String()}, {OpInsert, b.String()}, {OpEqual, "tail\n"}}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("超出上限的差异 = %d 段, want 整体替换", len(ops))
+	}
+}
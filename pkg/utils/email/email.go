@@ -4,7 +4,9 @@ import (
 	"ReMindful/internal/config"
 	"crypto/tls"
 	"fmt"
+	"math"
 	"net/smtp"
+	"time"
 )
 
 type EmailSender struct {
@@ -18,7 +20,18 @@ func NewEmailSender(config config.EmailConfig) *EmailSender {
 func (s *EmailSender) SendVerificationCode(to, code string) error {
 	subject := "ReMindful 验证码"
 	body := fmt.Sprintf("您的验证码是: %s\n验证码有效期为5分钟。", code)
+	return s.send(to, subject, body)
+}
+
+// SendPasswordResetCode 发送重置密码的验证码
+func (s *EmailSender) SendPasswordResetCode(to, code string, expiration time.Duration) error {
+	subject := "ReMindful 重置密码"
+	body := fmt.Sprintf("您正在重置 ReMindful 账户的密码，验证码是: %s\n验证码有效期为%d分钟，只能使用一次。\n如果不是您本人操作，请忽略此邮件，您的密码不会改变。", code, int(math.Ceil(expiration.Minutes())))
+	return s.send(to, subject, body)
+}
 
+// 发送纯文本邮件
+func (s *EmailSender) send(to, subject, body string) error {
 	msg := fmt.Sprintf("From: %s\r\n"+
 		"To: %s\r\n"+
 		"Subject: %s\r\n"+